@@ -1,9 +1,11 @@
 package main
 
 import (
+	"crypto/rand"
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 	"gorm.io/driver/postgres"
@@ -11,7 +13,10 @@ import (
 
 	"azh/internal/config"
 	"azh/internal/handler"
+	"azh/internal/mail"
 	"azh/internal/model"
+	"azh/internal/notify"
+	"azh/internal/pubsub"
 	"azh/internal/repository"
 	"azh/internal/service"
 )
@@ -20,6 +25,12 @@ func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
 
+	// Course times are local times of the club, independent of the time zone of the server
+	timezone, err := time.LoadLocation(cfg.ClubTimezone)
+	if err != nil {
+		log.Fatalf("Failed to load time zone %q: %v", cfg.ClubTimezone, err)
+	}
+
 	// Initialize database connection
 	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
 		cfg.DBHost, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBPort)
@@ -29,7 +40,17 @@ func main() {
 	}
 
 	// Auto-migrate models
-	err = db.AutoMigrate(&model.Course{}, &model.Member{}, &model.MemberCourse{}, &model.Participation{})
+	err = db.AutoMigrate(
+		&model.Course{}, &model.Member{}, &model.MemberCourse{}, &model.Participation{},
+		&model.Trainer{}, &model.CourseTrainer{}, &model.SessionTrainer{}, &model.TrainerUnavailability{},
+		&model.Qualification{}, &model.QualificationRequirement{}, &model.WaitlistEntry{},
+		&model.Registration{}, &model.EventDate{}, &model.EventRegistration{},
+		&model.PunchCard{}, &model.PunchCardUsage{}, &model.AttendanceChange{}, &model.AuditEntry{},
+		&model.SessionLock{}, &model.UnlockRequest{}, &model.Session{}, &model.Incident{},
+		&model.Location{}, &model.Room{}, &model.OpeningHours{}, &model.LocationAlias{}, &model.CalendarFeed{},
+		&model.Notification{}, &model.NotificationSetting{},
+		&model.Guardian{}, &model.GuardianChild{}, &model.Absence{}, &model.Webhook{}, &model.WebhookDelivery{},
+	)
 	if err != nil {
 		log.Fatalf("Failed to auto-migrate database: %v", err)
 	}
@@ -39,16 +60,150 @@ func main() {
 	memberCourseRepo := repository.NewMemberCourseRepository(db)
 	participationRepo := repository.NewParticipationRepository(db)
 	memberRepo := repository.NewMemberRepository(db)
+	statsRepo := repository.NewStatsRepository(db)
+	sessionTrainerRepo := repository.NewSessionTrainerRepository(db)
+	trainerRepo := repository.NewTrainerRepository(db)
+	qualificationRepo := repository.NewQualificationRepository(db)
+	waitlistRepo := repository.NewWaitlistRepository(db)
+	registrationRepo := repository.NewRegistrationRepository(db)
+	eventRegistrationRepo := repository.NewEventRegistrationRepository(db)
+	punchCardRepo := repository.NewPunchCardRepository(db)
+	attendanceChangeRepo := repository.NewAttendanceChangeRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	sessionLockRepo := repository.NewSessionLockRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	incidentRepo := repository.NewIncidentRepository(db)
+	locationRepo := repository.NewLocationRepository(db)
+	calendarFeedRepo := repository.NewCalendarFeedRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	guardianRepo := repository.NewGuardianRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+
+	// Initialize mailer
+	mailer := mail.NewMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUser, cfg.SMTPPassword, cfg.SMTPFrom)
+
+	// Initialize notification channels; the file channel writes messages to disk for testing
+	var channels []notify.Channel
+	if mailer.Enabled() {
+		channels = append(channels, notify.NewEmailChannel(mailer))
+	}
+	if cfg.NotifyWebhookURL != "" {
+		channels = append(channels, notify.NewWebhookChannel(cfg.NotifyWebhookURL))
+	}
+	if cfg.NotifyOutboxDir != "" {
+		fileChannel, err := notify.NewFileChannel(cfg.NotifyOutboxDir)
+		if err != nil {
+			log.Fatalf("Failed to create notification outbox directory: %v", err)
+		}
+		channels = append(channels, fileChannel)
+	}
+	templates, err := notify.LoadTemplates()
+	if err != nil {
+		log.Fatalf("Failed to load notification templates: %v", err)
+	}
 
 	// Initialize services
 	courseService := service.NewCourseService(courseRepo)
-	participationService := service.NewParticipationService(courseRepo, memberCourseRepo, participationRepo, memberRepo)
-	importService := service.NewImportService(db, courseRepo, memberRepo, memberCourseRepo, participationRepo)
+	auditService := service.NewAuditService(auditRepo)
+	// Subscribes to the audit log, so it must exist before the first change is made
+	webhookService := service.NewWebhookService(webhookRepo, auditService, service.WebhookSettings{
+		MaxAttempts: cfg.WebhookMaxAttempts,
+		RetryDelay:  time.Duration(cfg.WebhookRetrySeconds) * time.Second,
+		Interval:    time.Duration(cfg.WebhookDispatchSeconds) * time.Second,
+		Timeout:     time.Duration(cfg.WebhookTimeoutSeconds) * time.Second,
+	})
+	sessionLockService := service.NewSessionLockService(courseRepo, sessionLockRepo, auditService, service.LockSettings{
+		AutoLockDays: cfg.SessionAutoLockDays,
+		UnlockPeriod: time.Duration(cfg.SessionUnlockHours) * time.Hour,
+	}, timezone)
+	club := service.ClubInfo{Name: cfg.ClubName, Address: cfg.ClubAddress}
+	notificationService := service.NewNotificationService(notificationRepo, memberRepo, templates, channels, club, auditService,
+		service.OutboxSettings{
+			MaxAttempts: cfg.NotifyMaxAttempts,
+			RetryDelay:  time.Duration(cfg.NotifyRetryMinutes) * time.Minute,
+			Interval:    time.Duration(cfg.NotifyOutboxSeconds) * time.Second,
+		})
+	sessionService := service.NewSessionService(courseRepo, sessionRepo, memberRepo, memberCourseRepo, sessionLockService,
+		notificationService, auditService)
+	// Attendance changes are published in-process, so all devices must talk to the same instance
+	broker := pubsub.NewMemoryBroker()
+	participationService := service.NewParticipationService(courseRepo, memberCourseRepo, participationRepo, memberRepo, punchCardRepo,
+		attendanceChangeRepo, guardianRepo, sessionLockService, sessionService, auditService, broker)
+	syncService := service.NewSyncService(participationService, participationRepo, attendanceChangeRepo)
+	statsService := service.NewStatsService(statsRepo)
+	qualificationService := service.NewQualificationService(qualificationRepo, trainerRepo)
+	trainerService := service.NewTrainerService(courseRepo, participationRepo, sessionTrainerRepo, trainerRepo, qualificationService,
+		auditService, service.PayrollSettings{
+			HourlyRate:   cfg.TrainerHourlyRate,
+			AllowanceCap: cfg.TrainerAllowanceCap,
+		})
+	locationService := service.NewLocationService(courseRepo, locationRepo, auditService)
+	waitlistService := service.NewWaitlistService(courseRepo, memberRepo, memberCourseRepo, waitlistRepo, auditService)
+	importService := service.NewImportService(db, courseRepo, memberRepo, memberCourseRepo, participationRepo, trainerService, locationService,
+		waitlistService, auditService)
+	printService := service.NewPrintService(participationService, club)
+	calendarService := service.NewCalendarService(courseRepo, sessionRepo, locationRepo, calendarFeedRepo, trainerService, club,
+		cfg.PublicURL, timezone)
+	incidentService := service.NewIncidentService(courseRepo, memberRepo, incidentRepo, printService, auditService)
+	guardianService := service.NewGuardianService(courseRepo, memberRepo, memberCourseRepo, participationRepo, sessionRepo, guardianRepo,
+		participationService, auditService, cfg.PublicURL, timezone)
+	registrationService := service.NewRegistrationService(courseRepo, memberRepo, memberCourseRepo, registrationRepo, waitlistService,
+		guardianService, auditService, mailer, cfg.OfficeEmail, cfg.PublicURL)
+	eventService := service.NewEventService(courseRepo, memberRepo, memberCourseRepo, eventRegistrationRepo, trainerService,
+		locationService, auditService)
+	punchCardService := service.NewPunchCardService(courseRepo, memberRepo, punchCardRepo, auditService, service.PunchCardDefaults{
+		Credits: cfg.PunchCardCredits,
+		Price:   cfg.PunchCardPrice,
+	})
+	// Without a configured secret the QR codes stay valid only until the next restart
+	checkInSecret := []byte(cfg.CheckInSecret)
+	if len(checkInSecret) == 0 {
+		log.Printf("No CHECKIN_SECRET configured, using a random secret")
+		checkInSecret = []byte(rand.Text())
+	}
+	checkInService := service.NewCheckInService(courseRepo, memberRepo, memberCourseRepo, participationService, checkInSecret, service.CheckInWindow{
+		OpensBefore: time.Duration(cfg.CheckInOpenMinutes) * time.Minute,
+	}, timezone)
+	churnService := service.NewChurnService(courseRepo, memberRepo, statsRepo, trainerRepo, mailer, cfg.OfficeEmail, service.ChurnCriteria{
+		MissedSessions: cfg.ChurnMissedSessions,
+		MinRate:        cfg.ChurnMinRate,
+		MaxDrop:        cfg.ChurnMaxDrop,
+		Weeks:          cfg.ChurnWeeks,
+	})
+
+	// Normalize trainers of courses imported before trainers became entities
+	if err := trainerService.NormalizeUnlinkedCourseTrainers(); err != nil {
+		log.Fatalf("Failed to normalize course trainers: %v", err)
+	}
+	// Link courses to the locations their free-text location maps to
+	if err := locationService.NormalizeCourseLocations(); err != nil {
+		log.Fatalf("Failed to normalize course locations: %v", err)
+	}
 
 	// Initialize handlers
 	courseHandler := handler.NewCourseHandler(courseService)
 	participationHandler := handler.NewParticipationHandler(participationService)
 	importHandler := handler.NewImportHandler(importService)
+	statsHandler := handler.NewStatsHandler(statsService)
+	churnHandler := handler.NewChurnHandler(churnService)
+	printHandler := handler.NewPrintHandler(printService)
+	trainerHandler := handler.NewTrainerHandler(trainerService)
+	qualificationHandler := handler.NewQualificationHandler(qualificationService)
+	waitlistHandler := handler.NewWaitlistHandler(waitlistService)
+	registrationHandler := handler.NewRegistrationHandler(registrationService)
+	guardianHandler := handler.NewGuardianHandler(guardianService)
+	eventHandler := handler.NewEventHandler(eventService)
+	punchCardHandler := handler.NewPunchCardHandler(punchCardService)
+	checkInHandler := handler.NewCheckInHandler(checkInService)
+	syncHandler := handler.NewSyncHandler(syncService)
+	auditHandler := handler.NewAuditHandler(auditService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+	sessionLockHandler := handler.NewSessionLockHandler(sessionLockService)
+	sessionHandler := handler.NewSessionHandler(sessionService)
+	incidentHandler := handler.NewIncidentHandler(incidentService)
+	locationHandler := handler.NewLocationHandler(locationService)
+	calendarHandler := handler.NewCalendarHandler(calendarService)
+	notificationHandler := handler.NewNotificationHandler(notificationService)
 
 	// Set up router
 	router := httprouter.New()
@@ -56,10 +211,116 @@ func main() {
 	// Course endpoints
 	router.GET("/api/courses", courseHandler.GetCourses)
 	router.GET("/api/courses/:id/occurrences", courseHandler.GetOccurrences)
+	router.GET("/api/courses/:id/at-risk", churnHandler.GetAtRiskMembers)
+	router.GET("/api/courses/:id/attendance-sheet", printHandler.GetAttendanceSheet)
+
+	// Enrollment and waitlist endpoints
+	router.PUT("/api/courses/:id/capacity", waitlistHandler.SetCapacity)
+	router.GET("/api/courses/:id/waitlist", waitlistHandler.GetWaitlist)
+	router.POST("/api/courses/:id/enrollments", waitlistHandler.Enroll)
+	router.DELETE("/api/courses/:id/enrollments/:memberId", waitlistHandler.Unenroll)
+
+	// Event endpoints; attendance is recorded through the participation endpoints
+	router.GET("/api/events", eventHandler.GetEvents)
+	router.POST("/api/events", eventHandler.CreateEvent)
+	router.PUT("/api/events/:id", eventHandler.UpdateEvent)
+	router.GET("/api/events/:id/registrations", eventHandler.GetRegistrations)
+	router.POST("/api/events/:id/registrations", eventHandler.Register)
+	router.DELETE("/api/events/:id/registrations/:memberId", eventHandler.Unregister)
+	router.PUT("/api/events/:id/registrations/:memberId/payment", eventHandler.SetPaid)
 
 	// Participation endpoints
 	router.GET("/api/courses/:id/dates/:date/participants", participationHandler.GetParticipants)
 	router.POST("/api/courses/:id/dates/:date/participants/:participantId/attendance", participationHandler.SetAttendance)
+	router.GET("/api/courses/:id/dates/:date/stream", participationHandler.StreamAttendance)
+	router.PUT("/api/courses/:id/dates/:date/attendance", participationHandler.SetAttendanceBulk)
+	router.DELETE("/api/courses/:id/dates/:date/attendance", participationHandler.ClearAttendance)
+	router.POST("/api/courses/:id/dates/:date/attendance/mark-all-present", participationHandler.MarkAllPresent)
+	router.POST("/api/courses/:id/dates/:date/attendance/copy-previous", participationHandler.CopyPreviousAttendance)
+
+	// Session notes and training content endpoints
+	router.GET("/api/courses/:id/sessions", sessionHandler.GetSessions)
+	router.GET("/api/courses/:id/dates/:date/session", sessionHandler.GetSession)
+	router.PUT("/api/courses/:id/dates/:date/session", sessionHandler.UpdateSession)
+	router.POST("/api/courses/:id/dates/:date/cancel", sessionHandler.CancelSession)
+	router.GET("/api/courses/:id/dates/:date/notifications", sessionHandler.GetNotifications)
+
+	// Incident reports; reading them requires the admin token as they contain health data
+	router.POST("/api/courses/:id/dates/:date/incidents", incidentHandler.Report)
+
+	// Session lock endpoints; unlock requests are decided through the admin endpoints
+	router.GET("/api/courses/:id/dates/:date/lock", sessionLockHandler.GetLock)
+	router.POST("/api/courses/:id/dates/:date/lock", sessionLockHandler.Lock)
+	router.POST("/api/courses/:id/dates/:date/unlock-requests", sessionLockHandler.RequestUnlock)
+
+	// Sync endpoint for attendance changes recorded offline
+	router.POST("/api/sync", syncHandler.Sync)
+
+	// QR code self check-in endpoint; the QR codes are handed out through the admin API
+	router.POST("/api/courses/:id/dates/:date/check-in", checkInHandler.CheckIn)
+
+	// Notification settings of members
+	router.GET("/api/members/:id/notification-settings", notificationHandler.GetSetting)
+	router.PUT("/api/members/:id/notification-settings", notificationHandler.SetSetting)
+
+	// Statistics endpoints
+	router.GET("/api/stats/members", statsHandler.GetMemberStats)
+	router.GET("/api/stats/courses", statsHandler.GetCourseStats)
+	router.GET("/api/stats/courses/:id/sessions", statsHandler.GetSessionHeadCounts)
+	router.GET("/api/stats/streaks", statsHandler.GetStreaks)
+
+	// Trainer endpoints
+	router.GET("/api/trainers", trainerHandler.GetTrainers)
+	router.POST("/api/trainers/:id/unavailability", trainerHandler.AddUnavailability)
+	router.DELETE("/api/trainers/:id/unavailability/:unavailabilityId", trainerHandler.RemoveUnavailability)
+	router.GET("/api/sessions/uncovered", trainerHandler.GetUncoveredSessions)
+	router.GET("/api/courses/:id/dates/:date/trainers", trainerHandler.GetSessionTrainers)
+	router.PUT("/api/courses/:id/dates/:date/trainers", trainerHandler.SetSessionTrainers)
+	router.GET("/api/reports/trainer-hours", trainerHandler.GetHoursReport)
+
+	// Location endpoints; imported courses are linked to locations through aliases
+	router.GET("/api/locations", locationHandler.GetLocations)
+	router.POST("/api/locations", locationHandler.CreateLocation)
+	router.PUT("/api/locations/:id", locationHandler.UpdateLocation)
+	router.POST("/api/locations/:id/rooms", locationHandler.AddRoom)
+	router.POST("/api/locations/:id/aliases", locationHandler.AddAlias)
+	router.DELETE("/api/locations/:id/aliases/:aliasId", locationHandler.RemoveAlias)
+	router.PUT("/api/courses/:id/room", locationHandler.SetCourseRoom)
+	router.GET("/api/reports/unmapped-locations", locationHandler.GetUnmappedLocations)
+	router.GET("/api/reports/room-conflicts", locationHandler.GetRoomConflicts)
+
+	// Calendar feeds; the feed URLs contain a secret token and need no further authentication.
+	// Feeds are rotated through the admin endpoints.
+	router.GET("/calendar/:file", calendarHandler.GetFeed)
+	router.POST("/api/courses/:id/calendar-feed", calendarHandler.GetCourseFeed)
+	router.POST("/api/trainers/:id/calendar-feed", calendarHandler.GetTrainerFeed)
+
+	// Qualification endpoints
+	router.GET("/api/trainers/:id/qualifications", qualificationHandler.GetQualifications)
+	router.POST("/api/trainers/:id/qualifications", qualificationHandler.AddQualification)
+	router.DELETE("/api/trainers/:id/qualifications/:qualificationId", qualificationHandler.RemoveQualification)
+	router.GET("/api/qualification-requirements", qualificationHandler.GetRequirements)
+	router.PUT("/api/qualification-requirements/:trainingType", qualificationHandler.SetRequirements)
+	router.GET("/api/reports/qualification-expiries", qualificationHandler.GetExpiries)
+
+	// Punch card endpoints
+	router.PUT("/api/courses/:id/drop-in", punchCardHandler.SetDropIn)
+	router.POST("/api/punch-cards", punchCardHandler.SellCard)
+	router.GET("/api/members/:id/punch-cards", punchCardHandler.GetCards)
+	router.GET("/api/reports/punch-cards", punchCardHandler.GetReport)
+
+	// Registration endpoints
+	router.GET("/api/public/courses", registrationHandler.GetOpenCourses)
+	router.POST("/api/public/registrations", registrationHandler.Register)
+	router.POST("/api/public/registrations/confirm", registrationHandler.ConfirmRegistration)
+	router.GET("/api/registrations", registrationHandler.GetRegistrations)
+	router.POST("/api/registrations/:id/approve", registrationHandler.ApproveRegistration)
+	router.POST("/api/registrations/:id/reject", registrationHandler.RejectRegistration)
+
+	// Absences reported by guardians through their personal link
+	router.GET("/api/public/absences/:token", guardianHandler.GetOverview)
+	router.POST("/api/public/absences/:token", guardianHandler.ReportAbsence)
+	router.POST("/api/public/absences/:token/withdraw", guardianHandler.WithdrawAbsence)
 
 	// Export endpoint
 	router.GET("/api/export", participationHandler.ExportData)
@@ -67,10 +328,82 @@ func main() {
 	// Import endpoint
 	router.POST("/api/import", importHandler.ImportCSV)
 
+	// Admin endpoints, disabled unless ADMIN_TOKEN is configured
+	router.GET("/api/admin/audit", handler.RequireAdmin(cfg.AdminToken, auditHandler.GetEntries))
+	router.GET("/api/admin/unlock-requests", handler.RequireAdmin(cfg.AdminToken, sessionLockHandler.GetUnlockRequests))
+	router.POST("/api/admin/unlock-requests/:id/approve", handler.RequireAdmin(cfg.AdminToken, sessionLockHandler.ApproveUnlock))
+	router.POST("/api/admin/unlock-requests/:id/reject", handler.RequireAdmin(cfg.AdminToken, sessionLockHandler.RejectUnlock))
+	router.GET("/api/admin/incidents", handler.RequireAdmin(cfg.AdminToken, incidentHandler.GetIncidents))
+	router.GET("/api/admin/incidents/:id", handler.RequireAdmin(cfg.AdminToken, incidentHandler.GetIncident))
+	router.PUT("/api/admin/incidents/:id/status", handler.RequireAdmin(cfg.AdminToken, incidentHandler.SetStatus))
+	router.GET("/api/admin/incidents/:id/report", handler.RequireAdmin(cfg.AdminToken, incidentHandler.GetReport))
+	router.POST("/api/admin/calendar-feed", handler.RequireAdmin(cfg.AdminToken, calendarHandler.GetClubFeed))
+	router.POST("/api/admin/trainers", handler.RequireAdmin(cfg.AdminToken, trainerHandler.CreateTrainer))
+	router.PUT("/api/admin/trainers/:id", handler.RequireAdmin(cfg.AdminToken, trainerHandler.UpdateTrainer))
+	router.POST("/api/admin/courses/:id/calendar-feed", handler.RequireAdmin(cfg.AdminToken, calendarHandler.GetCourseFeedAdmin))
+	router.POST("/api/admin/trainers/:id/calendar-feed", handler.RequireAdmin(cfg.AdminToken, calendarHandler.GetTrainerFeedAdmin))
+	router.GET("/api/admin/members/:id/qr-code", handler.RequireAdmin(cfg.AdminToken, checkInHandler.GetQRCode))
+	router.GET("/api/admin/notifications", handler.RequireAdmin(cfg.AdminToken, notificationHandler.GetNotifications))
+	router.POST("/api/admin/notification-test", handler.RequireAdmin(cfg.AdminToken, notificationHandler.SendTest))
+	router.POST("/api/admin/notifications/:id/retry", handler.RequireAdmin(cfg.AdminToken, notificationHandler.Retry))
+	router.GET("/api/admin/guardians", handler.RequireAdmin(cfg.AdminToken, guardianHandler.GetGuardians))
+	router.POST("/api/admin/guardians", handler.RequireAdmin(cfg.AdminToken, guardianHandler.CreateGuardian))
+	router.PUT("/api/admin/guardians/:id", handler.RequireAdmin(cfg.AdminToken, guardianHandler.UpdateGuardian))
+	router.POST("/api/admin/guardians/:id/link", handler.RequireAdmin(cfg.AdminToken, guardianHandler.GetLink))
+	router.GET("/api/admin/webhooks", handler.RequireAdmin(cfg.AdminToken, webhookHandler.GetWebhooks))
+	router.POST("/api/admin/webhooks", handler.RequireAdmin(cfg.AdminToken, webhookHandler.CreateWebhook))
+	router.PUT("/api/admin/webhooks/:id", handler.RequireAdmin(cfg.AdminToken, webhookHandler.UpdateWebhook))
+	router.DELETE("/api/admin/webhooks/:id", handler.RequireAdmin(cfg.AdminToken, webhookHandler.DeleteWebhook))
+	router.POST("/api/admin/webhooks/:id/test", handler.RequireAdmin(cfg.AdminToken, webhookHandler.SendTest))
+	router.GET("/api/admin/webhook-deliveries", handler.RequireAdmin(cfg.AdminToken, webhookHandler.GetDeliveries))
+	router.POST("/api/admin/webhook-deliveries/:id/retry", handler.RequireAdmin(cfg.AdminToken, webhookHandler.RetryDelivery))
+
 	router.GET("/", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 		w.Header().Set("Content-Type", "text/html")
 		http.ServeFile(w, r, "index.html")
 	})
+	router.GET("/register", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "text/html")
+		http.ServeFile(w, r, "register.html")
+	})
+	router.GET("/absence", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "text/html")
+		http.ServeFile(w, r, "absence.html")
+	})
+	router.GET("/kiosk", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "text/html")
+		http.ServeFile(w, r, "kiosk.html")
+	})
+	router.GET("/sw.js", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "text/javascript")
+		w.Header().Set("Cache-Control", "no-cache")
+		http.ServeFile(w, r, "sw.js")
+	})
+	router.GET("/manifest.webmanifest", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/manifest+json")
+		http.ServeFile(w, r, "manifest.webmanifest")
+	})
+
+	// Deliver queued notifications in the background
+	if len(channels) == 0 {
+		log.Printf("No notification channel configured, notifications stay in the outbox")
+	}
+	notificationService.StartOutbox()
+
+	// Post queued webhook events in the background
+	webhookService.StartDispatcher()
+
+	// Offer spots freed by cancellations to waiting members every night
+	waitlistService.StartDailyPromotion(3)
+
+	// Send the weekly churn digest on Monday mornings
+	if cfg.ChurnDigestEnabled {
+		if mailer.Enabled() {
+			churnService.StartWeeklyDigest(time.Monday, 7)
+		} else {
+			log.Printf("Churn digest enabled, but no SMTP server configured")
+		}
+	}
 
 	// Start server
 	port := cfg.Port
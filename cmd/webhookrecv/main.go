@@ -0,0 +1,58 @@
+// Command webhookrecv is a local receiver for testing outgoing webhooks. It verifies the signature
+// of every request with the webhook secret and logs the events it receives.
+//
+//	go run ./cmd/webhookrecv -secret <secret from creating the webhook>
+//
+// Point a webhook at http://localhost:9090/ and call its test endpoint. With -status, the receiver
+// answers with another status code, e.g. 500 to watch the retries in the delivery log.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"azh/internal/webhook"
+)
+
+func main() {
+	addr := flag.String("addr", ":9090", "address to listen on")
+	secret := flag.String("secret", "", "secret of the webhook, required")
+	status := flag.Int("status", http.StatusOK, "status code to answer verified requests with")
+	tolerance := flag.Duration("tolerance", 5*time.Minute, "maximum age of a request")
+	flag.Parse()
+	if *secret == "" {
+		log.Fatal("The -secret flag is required")
+	}
+
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		payload, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "Failed to read body", http.StatusBadRequest)
+			return
+		}
+		if err := webhook.Verify(*secret, r.Header, payload, time.Now(), *tolerance); err != nil {
+			log.Printf("Rejected delivery %s: %v", r.Header.Get(webhook.HeaderDelivery), err)
+			http.Error(w, "Invalid signature", http.StatusUnauthorized)
+			return
+		}
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, payload, "", "  "); err != nil {
+			indented.Write(payload)
+		}
+		log.Printf("Delivery %s: %s (answering %d)\n%s", r.Header.Get(webhook.HeaderDelivery),
+			r.Header.Get(webhook.HeaderEvent), *status, indented.String())
+		w.WriteHeader(*status)
+	})
+
+	log.Printf("Webhook receiver listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
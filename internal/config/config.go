@@ -1,6 +1,9 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+)
 
 // Config holds application configuration
 type Config struct {
@@ -10,6 +13,49 @@ type Config struct {
 	DBName     string
 	DBPort     string
 	Port       string
+
+	ClubName     string
+	ClubAddress  string
+	ClubTimezone string // time zone of the course times, e.g. Europe/Berlin
+	PublicURL    string
+
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUser     string
+	SMTPPassword string
+	SMTPFrom     string
+	OfficeEmail  string
+
+	ChurnMissedSessions int
+	ChurnMinRate        float64
+	ChurnMaxDrop        float64
+	ChurnWeeks          int
+	ChurnDigestEnabled  bool
+
+	TrainerHourlyRate   float64
+	TrainerAllowanceCap float64
+
+	PunchCardCredits int
+	PunchCardPrice   float64
+
+	CheckInSecret      string
+	CheckInOpenMinutes int
+
+	AdminToken string
+
+	SessionAutoLockDays int
+	SessionUnlockHours  int
+
+	NotifyWebhookURL    string
+	NotifyOutboxDir     string
+	NotifyMaxAttempts   int
+	NotifyRetryMinutes  int
+	NotifyOutboxSeconds int
+
+	WebhookMaxAttempts     int
+	WebhookRetrySeconds    int
+	WebhookDispatchSeconds int
+	WebhookTimeoutSeconds  int
 }
 
 // LoadConfig loads configuration from environment variables
@@ -21,6 +67,49 @@ func LoadConfig() Config {
 		DBName:     getEnv("DB_NAME", "azh"),
 		DBPort:     getEnv("DB_PORT", "5432"),
 		Port:       getEnv("PORT", "8080"),
+
+		ClubName:     getEnv("CLUB_NAME", "AZH"),
+		ClubAddress:  getEnv("CLUB_ADDRESS", ""),
+		ClubTimezone: getEnv("CLUB_TIMEZONE", "Europe/Berlin"),
+		PublicURL:    getEnv("PUBLIC_URL", "http://localhost:8080"),
+
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnv("SMTP_PORT", "587"),
+		SMTPUser:     getEnv("SMTP_USER", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:     getEnv("SMTP_FROM", "noreply@azh.de"),
+		OfficeEmail:  getEnv("OFFICE_EMAIL", ""),
+
+		ChurnMissedSessions: getEnvInt("CHURN_MISSED_SESSIONS", 3),
+		ChurnMinRate:        getEnvFloat("CHURN_MIN_RATE", 0.5),
+		ChurnMaxDrop:        getEnvFloat("CHURN_MAX_DROP", 0.3),
+		ChurnWeeks:          getEnvInt("CHURN_WEEKS", 8),
+		ChurnDigestEnabled:  getEnvBool("CHURN_DIGEST_ENABLED", false),
+
+		TrainerHourlyRate:   getEnvFloat("TRAINER_HOURLY_RATE", 15),
+		TrainerAllowanceCap: getEnvFloat("TRAINER_ALLOWANCE_CAP", 3300),
+
+		PunchCardCredits: getEnvInt("PUNCH_CARD_CREDITS", 10),
+		PunchCardPrice:   getEnvFloat("PUNCH_CARD_PRICE", 100),
+
+		CheckInSecret:      getEnv("CHECKIN_SECRET", ""),
+		CheckInOpenMinutes: getEnvInt("CHECKIN_OPEN_MINUTES", 30),
+
+		AdminToken: getEnv("ADMIN_TOKEN", ""),
+
+		SessionAutoLockDays: getEnvInt("SESSION_AUTO_LOCK_DAYS", 0),
+		SessionUnlockHours:  getEnvInt("SESSION_UNLOCK_HOURS", 24),
+
+		NotifyWebhookURL:    getEnv("NOTIFY_WEBHOOK_URL", ""),
+		NotifyOutboxDir:     getEnv("NOTIFY_OUTBOX_DIR", ""),
+		NotifyMaxAttempts:   getEnvInt("NOTIFY_MAX_ATTEMPTS", 5),
+		NotifyRetryMinutes:  getEnvInt("NOTIFY_RETRY_MINUTES", 5),
+		NotifyOutboxSeconds: getEnvInt("NOTIFY_OUTBOX_SECONDS", 60),
+
+		WebhookMaxAttempts:     getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		WebhookRetrySeconds:    getEnvInt("WEBHOOK_RETRY_SECONDS", 30),
+		WebhookDispatchSeconds: getEnvInt("WEBHOOK_DISPATCH_SECONDS", 30),
+		WebhookTimeoutSeconds:  getEnvInt("WEBHOOK_TIMEOUT_SECONDS", 10),
 	}
 }
 
@@ -31,3 +120,27 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvInt retrieves an integer environment variable or returns default value
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(getEnv(key, "")); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvFloat retrieves a floating point environment variable or returns default value
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value, err := strconv.ParseFloat(getEnv(key, ""), 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvBool retrieves a boolean environment variable or returns default value
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, err := strconv.ParseBool(getEnv(key, "")); err == nil {
+		return value
+	}
+	return defaultValue
+}
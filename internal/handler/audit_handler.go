@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"azh/internal/repository"
+	"azh/internal/service"
+	"github.com/julienschmidt/httprouter"
+)
+
+// maxActorLength is the maximum length of an actor name stored in the audit log
+const maxActorLength = 100
+
+// AuditHandler handles HTTP requests for the audit log
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+// NewAuditHandler creates a new AuditHandler
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// GetEntries handles GET /api/admin/audit?entity=...&entity_id=...&actor=...&from=YYYY-MM-DD&to=YYYY-MM-DD&limit=N
+func (h *AuditHandler) GetEntries(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	query := r.URL.Query()
+	filter := repository.AuditFilter{
+		Entity:   query.Get("entity"),
+		EntityID: query.Get("entity_id"),
+		Actor:    query.Get("actor"),
+		Limit:    500,
+	}
+	if from := query.Get("from"); from != "" {
+		date, err := time.ParseInLocation("2006-01-02", from, time.Local)
+		if err != nil {
+			http.Error(w, "Invalid from date format", http.StatusBadRequest)
+			return
+		}
+		filter.From = date
+	}
+	if to := query.Get("to"); to != "" {
+		date, err := time.ParseInLocation("2006-01-02", to, time.Local)
+		if err != nil {
+			http.Error(w, "Invalid to date format", http.StatusBadRequest)
+			return
+		}
+		// The end date is inclusive
+		filter.To = date.AddDate(0, 0, 1)
+	}
+	if limit := query.Get("limit"); limit != "" {
+		var err error
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit < 1 || filter.Limit > 5000 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	entries, err := h.auditService.GetEntries(filter)
+	if err != nil {
+		http.Error(w, "Failed to retrieve audit log", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// RequireAdmin restricts a route to requests carrying the admin token as bearer token. Without a
+// configured token the route is disabled.
+func RequireAdmin(token string, next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		if token == "" {
+			http.Error(w, "Admin API disabled", http.StatusForbidden)
+			return
+		}
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r, ps)
+	}
+}
+
+// actor identifies who made a request for the audit log. The UI sends the name entered by the
+// user in the X-Actor header; requests without it are attributed to their address.
+func actor(r *http.Request) string {
+	if name := strings.TrimSpace(r.Header.Get("X-Actor")); name != "" {
+		if runes := []rune(name); len(runes) > maxActorLength {
+			name = string(runes[:maxActorLength])
+		}
+		return name
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "unknown (" + host + ")"
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"azh/internal/model"
+	"azh/internal/service"
+	"github.com/julienschmidt/httprouter"
+)
+
+// CalendarHandler handles HTTP requests for iCalendar feeds
+type CalendarHandler struct {
+	calendarService *service.CalendarService
+}
+
+// NewCalendarHandler creates a new CalendarHandler
+func NewCalendarHandler(calendarService *service.CalendarService) *CalendarHandler {
+	return &CalendarHandler{calendarService: calendarService}
+}
+
+// GetFeed handles GET /calendar/:file, where the file name is the feed token with the .ics extension
+func (h *CalendarHandler) GetFeed(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	token, ok := strings.CutSuffix(ps.ByName("file"), ".ics")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	data, err := h.calendarService.Render(token, time.Now())
+	if errors.Is(err, service.ErrCalendarFeedNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to render calendar", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(data)
+}
+
+// GetCourseFeed handles POST /api/courses/:id/calendar-feed; rotating requires the admin API
+func (h *CalendarHandler) GetCourseFeed(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	h.getFeed(w, r, ps, model.CalendarFeedCourse, "Invalid course ID", false)
+}
+
+// GetTrainerFeed handles POST /api/trainers/:id/calendar-feed; rotating requires the admin API
+func (h *CalendarHandler) GetTrainerFeed(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	h.getFeed(w, r, ps, model.CalendarFeedTrainer, "Invalid trainer ID", false)
+}
+
+// GetCourseFeedAdmin handles POST /api/admin/courses/:id/calendar-feed[?rotate=true]
+func (h *CalendarHandler) GetCourseFeedAdmin(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	h.getFeed(w, r, ps, model.CalendarFeedCourse, "Invalid course ID", true)
+}
+
+// GetTrainerFeedAdmin handles POST /api/admin/trainers/:id/calendar-feed[?rotate=true]
+func (h *CalendarHandler) GetTrainerFeedAdmin(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	h.getFeed(w, r, ps, model.CalendarFeedTrainer, "Invalid trainer ID", true)
+}
+
+// GetClubFeed handles POST /api/admin/calendar-feed[?rotate=true]
+func (h *CalendarHandler) GetClubFeed(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	h.getFeed(w, r, ps, model.CalendarFeedClub, "", true)
+}
+
+// getFeed returns the feed URL of a course, a trainer or the club, creating its token on first use.
+// Rotating breaks every existing subscription, so it is only allowed through the admin API.
+func (h *CalendarHandler) getFeed(w http.ResponseWriter, r *http.Request, ps httprouter.Params, kind, invalidID string, mayRotate bool) {
+	var subjectID uint64
+	if kind != model.CalendarFeedClub {
+		var err error
+		if subjectID, err = strconv.ParseUint(ps.ByName("id"), 10, 32); err != nil {
+			http.Error(w, invalidID, http.StatusBadRequest)
+			return
+		}
+	}
+	rotate := r.URL.Query().Get("rotate") == "true"
+	if rotate && !mayRotate {
+		http.Error(w, "Rotating calendar feeds requires the admin API", http.StatusForbidden)
+		return
+	}
+	feed, err := h.calendarService.GetFeed(kind, uint(subjectID), rotate)
+	switch {
+	case errors.Is(err, service.ErrCourseNotFound):
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrTrainerNotFound):
+		http.Error(w, "Trainer not found", http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, "Failed to create calendar feed", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(feed)
+}
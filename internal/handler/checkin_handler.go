@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"azh/internal/service"
+	"github.com/julienschmidt/httprouter"
+)
+
+// CheckInHandler handles HTTP requests for the QR code self check-in
+type CheckInHandler struct {
+	checkInService *service.CheckInService
+}
+
+// NewCheckInHandler creates a new CheckInHandler
+func NewCheckInHandler(checkInService *service.CheckInService) *CheckInHandler {
+	return &CheckInHandler{checkInService: checkInService}
+}
+
+// GetQRCode handles GET /api/admin/members/:id/qr-code?size=N. Anyone holding the code can check
+// the member in, so it is only handed out to administrators printing the member cards.
+func (h *CheckInHandler) GetQRCode(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	memberID, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid member ID", http.StatusBadRequest)
+		return
+	}
+	size := 256
+	if sizeStr := r.URL.Query().Get("size"); sizeStr != "" {
+		if size, err = strconv.Atoi(sizeStr); err != nil || size < 64 || size > 1024 {
+			http.Error(w, "Invalid size", http.StatusBadRequest)
+			return
+		}
+	}
+	png, err := h.checkInService.QRCode(uint(memberID), size)
+	if errors.Is(err, service.ErrMemberNotFound) {
+		http.Error(w, "Member not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to generate QR code", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Content-Disposition", "inline; filename=\"checkin-"+strconv.FormatUint(memberID, 10)+".png\"")
+	w.Write(png)
+}
+
+// CheckIn handles POST /api/courses/:id/dates/:date/check-in
+func (h *CheckInHandler) CheckIn(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	courseID, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+	date, err := time.Parse("2006-01-02", ps.ByName("date"))
+	if err != nil {
+		http.Error(w, "Invalid date format", http.StatusBadRequest)
+		return
+	}
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.checkInService.CheckIn(uint(courseID), date, req.Token, time.Now())
+	switch {
+	case errors.Is(err, service.ErrInvalidToken):
+		http.Error(w, "Invalid QR code", http.StatusBadRequest)
+		return
+	case errors.Is(err, service.ErrCourseNotFound):
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrNotEnrolled):
+		http.Error(w, "Member not enrolled in this course", http.StatusForbidden)
+		return
+	case errors.Is(err, service.ErrOutsideTimeframe):
+		http.Error(w, "Check-in is not open for this session", http.StatusUnprocessableEntity)
+		return
+	case errors.Is(err, service.ErrNoCredits):
+		http.Error(w, "No punch card credits left", http.StatusConflict)
+		return
+	case errors.Is(err, service.ErrSessionLocked):
+		http.Error(w, "Session locked", http.StatusConflict)
+		return
+	case errors.Is(err, service.ErrSessionCancelled):
+		http.Error(w, "Session cancelled", http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "Failed to check in", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"azh/internal/service"
+	"github.com/julienschmidt/httprouter"
+)
+
+// ChurnHandler handles HTTP requests for churn risk detection
+type ChurnHandler struct {
+	churnService *service.ChurnService
+}
+
+// NewChurnHandler creates a new ChurnHandler
+func NewChurnHandler(churnService *service.ChurnService) *ChurnHandler {
+	return &ChurnHandler{churnService: churnService}
+}
+
+// GetAtRiskMembers handles GET /api/courses/:id/at-risk[?missed=N][&minRate=0.5][&maxDrop=0.3][&weeks=8]
+func (h *ChurnHandler) GetAtRiskMembers(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	courseID, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	criteria := h.churnService.DefaultCriteria()
+	query := r.URL.Query()
+	if missed := query.Get("missed"); missed != "" {
+		if criteria.MissedSessions, err = strconv.Atoi(missed); err != nil || criteria.MissedSessions < 1 {
+			http.Error(w, "Invalid number of missed sessions", http.StatusBadRequest)
+			return
+		}
+	}
+	if minRate := query.Get("minRate"); minRate != "" {
+		if criteria.MinRate, err = strconv.ParseFloat(minRate, 64); err != nil || criteria.MinRate < 0 || criteria.MinRate > 1 {
+			http.Error(w, "Invalid minimum rate", http.StatusBadRequest)
+			return
+		}
+	}
+	if maxDrop := query.Get("maxDrop"); maxDrop != "" {
+		if criteria.MaxDrop, err = strconv.ParseFloat(maxDrop, 64); err != nil || criteria.MaxDrop < 0 || criteria.MaxDrop > 1 {
+			http.Error(w, "Invalid maximum drop", http.StatusBadRequest)
+			return
+		}
+	}
+	if weeks := query.Get("weeks"); weeks != "" {
+		if criteria.Weeks, err = strconv.Atoi(weeks); err != nil || criteria.Weeks < 1 {
+			http.Error(w, "Invalid number of weeks", http.StatusBadRequest)
+			return
+		}
+	}
+
+	atRisk, err := h.churnService.GetAtRiskMembers(uint(courseID), criteria, time.Now())
+	if err != nil {
+		http.Error(w, "Failed to detect at-risk members", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(atRisk)
+}
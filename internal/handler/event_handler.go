@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"azh/internal/service"
+	"github.com/julienschmidt/httprouter"
+)
+
+// EventHandler handles HTTP requests for one-off events
+type EventHandler struct {
+	eventService *service.EventService
+}
+
+// NewEventHandler creates a new EventHandler
+func NewEventHandler(eventService *service.EventService) *EventHandler {
+	return &EventHandler{eventService: eventService}
+}
+
+// GetEvents handles GET /api/events
+func (h *EventHandler) GetEvents(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	events, err := h.eventService.GetEvents()
+	if err != nil {
+		http.Error(w, "Failed to retrieve events", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(events)
+}
+
+// CreateEvent handles POST /api/events
+func (h *EventHandler) CreateEvent(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var req service.EventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	event, err := h.eventService.CreateEvent(actor(r), req)
+	if errors.Is(err, service.ErrInvalidEvent) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to create event", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(event)
+}
+
+// UpdateEvent handles PUT /api/events/:id
+func (h *EventHandler) UpdateEvent(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	eventID, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		return
+	}
+	var req service.EventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	event, err := h.eventService.UpdateEvent(actor(r), uint(eventID), req)
+	if errors.Is(err, service.ErrEventNotFound) {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, service.ErrInvalidEvent) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to update event", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(event)
+}
+
+// GetRegistrations handles GET /api/events/:id/registrations
+func (h *EventHandler) GetRegistrations(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	eventID, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		return
+	}
+	registrations, err := h.eventService.GetRegistrations(uint(eventID))
+	if errors.Is(err, service.ErrEventNotFound) {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to retrieve registrations", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(registrations)
+}
+
+// Register handles POST /api/events/:id/registrations
+func (h *EventHandler) Register(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	eventID, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		return
+	}
+	var req service.EventRegistrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	registration, err := h.eventService.Register(actor(r), uint(eventID), req, time.Now())
+	switch {
+	case errors.Is(err, service.ErrEventNotFound):
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrMemberNotFound):
+		http.Error(w, "Member not found", http.StatusBadRequest)
+		return
+	case errors.Is(err, service.ErrInvalidEvent):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, service.ErrAlreadyEnrolled), errors.Is(err, service.ErrEventFull):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "Failed to register for event", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(registration)
+}
+
+// Unregister handles DELETE /api/events/:id/registrations/:memberId
+func (h *EventHandler) Unregister(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	eventID, memberID, ok := parseEventAndMember(w, ps)
+	if !ok {
+		return
+	}
+	err := h.eventService.Unregister(actor(r), eventID, memberID)
+	if errors.Is(err, service.ErrEventNotFound) {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, service.ErrMemberNotFound) {
+		http.Error(w, "Registration not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to remove registration", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetPaid handles PUT /api/events/:id/registrations/:memberId/payment
+func (h *EventHandler) SetPaid(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	eventID, memberID, ok := parseEventAndMember(w, ps)
+	if !ok {
+		return
+	}
+	var req struct {
+		Paid bool `json:"paid"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	err := h.eventService.SetPaid(eventID, memberID, req.Paid, time.Now())
+	if errors.Is(err, service.ErrEventNotFound) {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, service.ErrMemberNotFound) {
+		http.Error(w, "Registration not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to update payment", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseEventAndMember reads the event and member ID from the route parameters
+func parseEventAndMember(w http.ResponseWriter, ps httprouter.Params) (uint, uint, bool) {
+	eventID, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	memberID, err := strconv.ParseUint(ps.ByName("memberId"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid member ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return uint(eventID), uint(memberID), true
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"azh/internal/service"
+	"github.com/julienschmidt/httprouter"
+)
+
+// GuardianHandler handles HTTP requests for guardians and the absences they report
+type GuardianHandler struct {
+	guardianService *service.GuardianService
+}
+
+// NewGuardianHandler creates a new GuardianHandler
+func NewGuardianHandler(guardianService *service.GuardianService) *GuardianHandler {
+	return &GuardianHandler{guardianService: guardianService}
+}
+
+// GetGuardians handles GET /api/admin/guardians
+func (h *GuardianHandler) GetGuardians(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	guardians, err := h.guardianService.GetGuardians()
+	if err != nil {
+		http.Error(w, "Failed to retrieve guardians", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(guardians)
+}
+
+// CreateGuardian handles POST /api/admin/guardians
+func (h *GuardianHandler) CreateGuardian(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var req service.GuardianRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	guardian, err := h.guardianService.CreateGuardian(actor(r), req)
+	if !h.writeGuardianError(w, err) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(guardian)
+}
+
+// UpdateGuardian handles PUT /api/admin/guardians/:id
+func (h *GuardianHandler) UpdateGuardian(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid guardian ID", http.StatusBadRequest)
+		return
+	}
+	var req service.GuardianRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	guardian, err := h.guardianService.UpdateGuardian(actor(r), uint(id), req)
+	if !h.writeGuardianError(w, err) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(guardian)
+}
+
+// GetLink handles POST /api/admin/guardians/:id/link[?rotate=true]
+func (h *GuardianHandler) GetLink(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid guardian ID", http.StatusBadRequest)
+		return
+	}
+	link, err := h.guardianService.GetLink(uint(id), r.URL.Query().Get("rotate") == "true")
+	if !h.writeGuardianError(w, err) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(link)
+}
+
+// GetOverview handles GET /api/public/absences/:token
+func (h *GuardianHandler) GetOverview(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	overview, err := h.guardianService.GetOverview(ps.ByName("token"), time.Now())
+	if !h.writeGuardianError(w, err) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(overview)
+}
+
+// ReportAbsence handles POST /api/public/absences/:token
+func (h *GuardianHandler) ReportAbsence(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	var req service.AbsenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	absence, err := h.guardianService.ReportAbsence(ps.ByName("token"), req, time.Now())
+	if !h.writeGuardianError(w, err) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(absence)
+}
+
+// WithdrawAbsence handles POST /api/public/absences/:token/withdraw
+func (h *GuardianHandler) WithdrawAbsence(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	var req service.AbsenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	err := h.guardianService.WithdrawAbsence(ps.ByName("token"), req, time.Now())
+	if !h.writeGuardianError(w, err) {
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeGuardianError reports errors of guardian and absence requests; it returns true if there was none
+func (h *GuardianHandler) writeGuardianError(w http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, service.ErrInvalidGuardian), errors.Is(err, service.ErrInvalidAbsence):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrGuardianNotFound):
+		http.Error(w, "Guardian not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrMemberNotFound):
+		http.Error(w, "Member not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrCourseNotFound):
+		http.Error(w, "Course not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrAbsenceNotFound):
+		http.Error(w, "Absence not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrAbsenceNotAllowed):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, service.ErrSessionCancelled):
+		http.Error(w, "Session cancelled", http.StatusConflict)
+	case errors.Is(err, service.ErrSessionLocked):
+		http.Error(w, "Session locked", http.StatusConflict)
+	default:
+		http.Error(w, "Failed to process request", http.StatusInternalServerError)
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -39,23 +40,36 @@ func (h *ImportHandler) ImportCSV(w http.ResponseWriter, r *http.Request, _ http
 	}
 
 	var processedFiles []string
+	total := service.ImportResult{Warnings: []string{}}
 	for _, fileHeader := range files {
-		if h.extractSingleCSVFile(w, fileHeader) {
+		result, failed := h.extractSingleCSVFile(w, actor(r), fileHeader)
+		if failed {
 			return
 		}
 		processedFiles = append(processedFiles, fileHeader.Filename)
+		total.Added += result.Added
+		total.Removed += result.Removed
+		total.Promoted += result.Promoted
+		total.Warnings = append(total.Warnings, result.Warnings...)
 	}
 
-	w.WriteHeader(http.StatusOK)
 	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(fmt.Sprintf(`{"message": "Successfully processed files: %v"}`, processedFiles)))
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(struct {
+		Message string `json:"message"`
+		service.ImportResult
+	}{
+		Message:      fmt.Sprintf("Successfully processed files: %v", processedFiles),
+		ImportResult: total,
+	})
 }
 
-func (h *ImportHandler) extractSingleCSVFile(w http.ResponseWriter, fileHeader *multipart.FileHeader) bool {
+func (h *ImportHandler) extractSingleCSVFile(w http.ResponseWriter, actorName string, fileHeader *multipart.FileHeader) (service.ImportResult, bool) {
+	var result service.ImportResult
 	file, err := fileHeader.Open()
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Unable to open file %s", fileHeader.Filename), http.StatusInternalServerError)
-		return true
+		return result, true
 	}
 	defer file.Close()
 
@@ -63,7 +77,7 @@ func (h *ImportHandler) extractSingleCSVFile(w http.ResponseWriter, fileHeader *
 	tmpFile, err := os.CreateTemp("", fmt.Sprintf("csv-upload-%s-*", fileHeader.Filename))
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Unable to create temporary file for %s", fileHeader.Filename), http.StatusInternalServerError)
-		return true
+		return result, true
 	}
 	tmpFilePath := tmpFile.Name()
 	defer tmpFile.Close()
@@ -72,14 +86,14 @@ func (h *ImportHandler) extractSingleCSVFile(w http.ResponseWriter, fileHeader *
 	// Write uploaded file content to temporary file
 	if _, err := io.Copy(tmpFile, file); err != nil {
 		http.Error(w, fmt.Sprintf("Unable to write to temporary file for %s", fileHeader.Filename), http.StatusInternalServerError)
-		return true
+		return result, true
 	}
 
 	// Process the CSV file
-	err = h.importService.ProcessCSV(tmpFilePath, fileHeader.Filename)
+	result, err = h.importService.ProcessCSV(actorName, tmpFilePath, fileHeader.Filename)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error processing file %s: %v", fileHeader.Filename, err), http.StatusInternalServerError)
-		return true
+		return result, true
 	}
-	return false
+	return result, false
 }
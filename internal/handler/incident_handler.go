@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"azh/internal/model"
+	"azh/internal/service"
+	"github.com/julienschmidt/httprouter"
+)
+
+// IncidentHandler handles HTTP requests for incident reports
+type IncidentHandler struct {
+	incidentService *service.IncidentService
+}
+
+// NewIncidentHandler creates a new IncidentHandler
+func NewIncidentHandler(incidentService *service.IncidentService) *IncidentHandler {
+	return &IncidentHandler{incidentService: incidentService}
+}
+
+// Report handles POST /api/courses/:id/dates/:date/incidents
+func (h *IncidentHandler) Report(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	courseID, date, ok := parseSession(w, ps)
+	if !ok {
+		return
+	}
+	var req service.IncidentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	incident, err := h.incidentService.Report(actor(r), courseID, date, req)
+	switch {
+	case errors.Is(err, service.ErrCourseNotFound):
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrMemberNotFound):
+		http.Error(w, "Member not found", http.StatusBadRequest)
+		return
+	case errors.Is(err, service.ErrInvalidIncident):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, "Failed to report incident", http.StatusInternalServerError)
+		return
+	}
+	// The report is only readable through the admin API afterwards
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":     incident.ID,
+		"status": incident.Status,
+	})
+}
+
+// GetIncidents handles GET /api/admin/incidents?status=reported
+func (h *IncidentHandler) GetIncidents(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	incidents, err := h.incidentService.GetIncidents(r.URL.Query().Get("status"))
+	if err != nil {
+		http.Error(w, "Failed to retrieve incidents", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(incidents)
+}
+
+// GetIncident handles GET /api/admin/incidents/:id
+func (h *IncidentHandler) GetIncident(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := parseIncidentID(w, ps)
+	if !ok {
+		return
+	}
+	incident, err := h.incidentService.GetIncident(id)
+	if errors.Is(err, service.ErrIncidentNotFound) {
+		http.Error(w, "Incident not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to retrieve incident", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(incident)
+}
+
+// SetStatus handles PUT /api/admin/incidents/:id/status
+func (h *IncidentHandler) SetStatus(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := parseIncidentID(w, ps)
+	if !ok {
+		return
+	}
+	var req service.IncidentStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Status != model.IncidentStatusForwarded && req.Status != model.IncidentStatusClosed {
+		http.Error(w, "Invalid incident status", http.StatusBadRequest)
+		return
+	}
+	incident, err := h.incidentService.SetStatus(actor(r), id, req, time.Now())
+	switch {
+	case errors.Is(err, service.ErrIncidentNotFound):
+		http.Error(w, "Incident not found", http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrIncidentState):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "Failed to update incident", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(incident)
+}
+
+// GetReport handles GET /api/admin/incidents/:id/report
+func (h *IncidentHandler) GetReport(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := parseIncidentID(w, ps)
+	if !ok {
+		return
+	}
+	pdf, err := h.incidentService.ReportPDF(id)
+	if errors.Is(err, service.ErrIncidentNotFound) {
+		http.Error(w, "Incident not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to render incident report", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=unfallmeldung-%d.pdf", id))
+	w.Write(pdf)
+}
+
+// parseIncidentID reads the incident ID from the route parameters
+func parseIncidentID(w http.ResponseWriter, ps httprouter.Params) (uint, bool) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid incident ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return uint(id), true
+}
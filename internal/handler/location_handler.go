@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"azh/internal/service"
+	"github.com/julienschmidt/httprouter"
+)
+
+// LocationHandler handles HTTP requests for locations, rooms and room assignments
+type LocationHandler struct {
+	locationService *service.LocationService
+}
+
+// NewLocationHandler creates a new LocationHandler
+func NewLocationHandler(locationService *service.LocationService) *LocationHandler {
+	return &LocationHandler{locationService: locationService}
+}
+
+// GetLocations handles GET /api/locations
+func (h *LocationHandler) GetLocations(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	locations, err := h.locationService.GetLocations()
+	if err != nil {
+		http.Error(w, "Failed to retrieve locations", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(locations)
+}
+
+// CreateLocation handles POST /api/locations
+func (h *LocationHandler) CreateLocation(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var req service.LocationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	location, err := h.locationService.CreateLocation(actor(r), req)
+	if !h.writeLocationError(w, err) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(location)
+}
+
+// UpdateLocation handles PUT /api/locations/:id
+func (h *LocationHandler) UpdateLocation(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := parseLocationID(w, ps)
+	if !ok {
+		return
+	}
+	var req service.LocationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	location, err := h.locationService.UpdateLocation(actor(r), id, req)
+	if !h.writeLocationError(w, err) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(location)
+}
+
+// AddRoom handles POST /api/locations/:id/rooms
+func (h *LocationHandler) AddRoom(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := parseLocationID(w, ps)
+	if !ok {
+		return
+	}
+	var req service.RoomRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	room, err := h.locationService.AddRoom(actor(r), id, req)
+	if !h.writeLocationError(w, err) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(room)
+}
+
+// AddAlias handles POST /api/locations/:id/aliases
+func (h *LocationHandler) AddAlias(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := parseLocationID(w, ps)
+	if !ok {
+		return
+	}
+	var req service.AliasRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	alias, err := h.locationService.AddAlias(actor(r), id, req)
+	if !h.writeLocationError(w, err) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(alias)
+}
+
+// RemoveAlias handles DELETE /api/locations/:id/aliases/:aliasId
+func (h *LocationHandler) RemoveAlias(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := parseLocationID(w, ps)
+	if !ok {
+		return
+	}
+	aliasID, err := strconv.ParseUint(ps.ByName("aliasId"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid alias ID", http.StatusBadRequest)
+		return
+	}
+	if !h.writeLocationError(w, h.locationService.RemoveAlias(actor(r), id, uint(aliasID))) {
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetCourseRoom handles PUT /api/courses/:id/room
+func (h *LocationHandler) SetCourseRoom(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	courseID, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+	var req struct {
+		RoomID *uint `json:"room_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	result, err := h.locationService.SetCourseRoom(actor(r), uint(courseID), req.RoomID, time.Now())
+	if errors.Is(err, service.ErrCourseNotFound) {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+	if !h.writeLocationError(w, err) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetUnmappedLocations handles GET /api/reports/unmapped-locations
+func (h *LocationHandler) GetUnmappedLocations(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	unmapped, err := h.locationService.GetUnmappedLocations()
+	if err != nil {
+		http.Error(w, "Failed to retrieve unmapped locations", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(unmapped)
+}
+
+// GetRoomConflicts handles GET /api/reports/room-conflicts?minDate=YYYY-MM-DD&maxDate=YYYY-MM-DD
+func (h *LocationHandler) GetRoomConflicts(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	query := r.URL.Query()
+	minDate := query.Get("minDate")
+	maxDate := query.Get("maxDate")
+	if minDate == "" || maxDate == "" {
+		http.Error(w, "minDate and maxDate are required", http.StatusBadRequest)
+		return
+	}
+	from, err := time.Parse("2006-01-02", minDate)
+	if err != nil {
+		http.Error(w, "Invalid date format", http.StatusBadRequest)
+		return
+	}
+	to, err := time.Parse("2006-01-02", maxDate)
+	if err != nil || to.Before(from) {
+		http.Error(w, "Invalid date range", http.StatusBadRequest)
+		return
+	}
+	conflicts, err := h.locationService.GetRoomConflicts(from, to)
+	if err != nil {
+		http.Error(w, "Failed to check room conflicts", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(conflicts)
+}
+
+// writeLocationError reports errors of maintaining locations; it returns true if there was none
+func (h *LocationHandler) writeLocationError(w http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, service.ErrLocationNotFound):
+		http.Error(w, "Location not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrInvalidLocation):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrLocationConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, "Failed to update location", http.StatusInternalServerError)
+	}
+	return false
+}
+
+// parseLocationID reads the location ID from the route parameters
+func parseLocationID(w http.ResponseWriter, ps httprouter.Params) (uint, bool) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid location ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return uint(id), true
+}
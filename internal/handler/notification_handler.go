@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"azh/internal/service"
+	"github.com/julienschmidt/httprouter"
+)
+
+// NotificationHandler handles HTTP requests for notifications and notification settings
+type NotificationHandler struct {
+	notificationService *service.NotificationService
+}
+
+// NewNotificationHandler creates a new NotificationHandler
+func NewNotificationHandler(notificationService *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+// GetSetting handles GET /api/members/:id/notification-settings
+func (h *NotificationHandler) GetSetting(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	memberID, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid member ID", http.StatusBadRequest)
+		return
+	}
+	setting, err := h.notificationService.GetSetting(uint(memberID))
+	if errors.Is(err, service.ErrMemberNotFound) {
+		http.Error(w, "Member not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to retrieve notification settings", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(setting)
+}
+
+// SetSetting handles PUT /api/members/:id/notification-settings
+func (h *NotificationHandler) SetSetting(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	memberID, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid member ID", http.StatusBadRequest)
+		return
+	}
+	var req service.NotificationSettingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	setting, err := h.notificationService.SetSetting(actor(r), uint(memberID), req)
+	switch {
+	case errors.Is(err, service.ErrMemberNotFound):
+		http.Error(w, "Member not found", http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrInvalidNotification):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, "Failed to update notification settings", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(setting)
+}
+
+// GetNotifications handles GET /api/admin/notifications?reference=...&status=failed
+func (h *NotificationHandler) GetNotifications(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	query := r.URL.Query()
+	notifications, err := h.notificationService.GetNotifications(query.Get("reference"), query.Get("status"))
+	if err != nil {
+		http.Error(w, "Failed to retrieve notifications", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(notifications)
+}
+
+// Retry handles POST /api/admin/notifications/:id/retry
+func (h *NotificationHandler) Retry(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid notification ID", http.StatusBadRequest)
+		return
+	}
+	notification, err := h.notificationService.Retry(uint(id), time.Now())
+	switch {
+	case errors.Is(err, service.ErrNotificationNotFound):
+		http.Error(w, "Notification not found", http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrNotificationState):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "Failed to retry notification", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(notification)
+}
+
+// SendTest handles POST /api/admin/notification-test
+func (h *NotificationHandler) SendTest(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var req service.TestNotificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	notification, err := h.notificationService.SendTest(req, time.Now())
+	if errors.Is(err, service.ErrInvalidNotification) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to queue test notification", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(notification)
+}
@@ -2,10 +2,13 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
+	"azh/internal/model"
 	"azh/internal/service"
 	"github.com/julienschmidt/httprouter"
 )
@@ -55,23 +58,199 @@ func (h *ParticipationHandler) SetAttendance(w http.ResponseWriter, r *http.Requ
 	}
 
 	var req struct {
-		Present bool `json:"present"`
+		Present bool   `json:"present"`
+		Status  string `json:"status"` // present, excused or absent; takes precedence over present
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
+	status := req.Status
+	if status == "" {
+		status = model.ParticipationStatusAbsent
+		if req.Present {
+			status = model.ParticipationStatusPresent
+		}
+	}
+	if status != model.ParticipationStatusPresent && status != model.ParticipationStatusExcused && status != model.ParticipationStatusAbsent {
+		http.Error(w, "Invalid attendance status", http.StatusBadRequest)
+		return
+	}
 
-	err = h.participationService.SetAttendance(uint(courseID), date, uint(participantID), req.Present)
+	err = h.participationService.SetAttendance(actor(r), uint(courseID), date, uint(participantID), status)
+	if errors.Is(err, service.ErrCourseNotFound) {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, service.ErrNoCredits) {
+		http.Error(w, "No punch card credits left", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, service.ErrSessionLocked) {
+		http.Error(w, "Session locked", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, service.ErrSessionCancelled) {
+		http.Error(w, "Session cancelled", http.StatusConflict)
+		return
+	}
 	if err != nil {
 		http.Error(w, "Failed to update attendance", http.StatusInternalServerError)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]bool{"present": req.Present})
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"present": status == model.ParticipationStatusPresent,
+		"status":  status,
+	})
+}
+
+// SetAttendanceBulk handles PUT /api/courses/:id/dates/:date/attendance
+func (h *ParticipationHandler) SetAttendanceBulk(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	courseID, date, ok := parseSession(w, ps)
+	if !ok {
+		return
+	}
+	var req struct {
+		Updates []service.AttendanceUpdate `json:"updates"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	for _, update := range req.Updates {
+		if update.Status != model.ParticipationStatusPresent && update.Status != model.ParticipationStatusExcused && update.Status != model.ParticipationStatusAbsent {
+			http.Error(w, "Invalid attendance status", http.StatusBadRequest)
+			return
+		}
+	}
+	err := h.participationService.SetAttendanceBulk(actor(r), courseID, date, req.Updates)
+	h.writeBulkResult(w, courseID, date, err)
+}
+
+// MarkAllPresent handles POST /api/courses/:id/dates/:date/attendance/mark-all-present
+func (h *ParticipationHandler) MarkAllPresent(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	courseID, date, ok := parseSession(w, ps)
+	if !ok {
+		return
+	}
+	err := h.participationService.MarkAllPresent(actor(r), courseID, date)
+	h.writeBulkResult(w, courseID, date, err)
+}
+
+// CopyPreviousAttendance handles POST /api/courses/:id/dates/:date/attendance/copy-previous
+func (h *ParticipationHandler) CopyPreviousAttendance(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	courseID, date, ok := parseSession(w, ps)
+	if !ok {
+		return
+	}
+	_, err := h.participationService.CopyPreviousAttendance(actor(r), courseID, date)
+	if errors.Is(err, service.ErrNoPreviousSession) {
+		http.Error(w, "No previous session with attendance", http.StatusNotFound)
+		return
+	}
+	h.writeBulkResult(w, courseID, date, err)
+}
+
+// ClearAttendance handles DELETE /api/courses/:id/dates/:date/attendance
+func (h *ParticipationHandler) ClearAttendance(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	courseID, date, ok := parseSession(w, ps)
+	if !ok {
+		return
+	}
+	err := h.participationService.ClearAttendance(actor(r), courseID, date)
+	h.writeBulkResult(w, courseID, date, err)
+}
+
+// writeBulkResult responds to a bulk attendance change with the updated participant list
+func (h *ParticipationHandler) writeBulkResult(w http.ResponseWriter, courseID uint, date time.Time, err error) {
+	if errors.Is(err, service.ErrCourseNotFound) {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, service.ErrNoCredits) {
+		http.Error(w, "No punch card credits left", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, service.ErrSessionLocked) {
+		http.Error(w, "Session locked", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, service.ErrSessionCancelled) {
+		http.Error(w, "Session cancelled", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to update attendance", http.StatusInternalServerError)
+		return
+	}
+	participants, err := h.participationService.GetParticipants(strconv.FormatUint(uint64(courseID), 10), date.Format("2006-01-02"))
+	if err != nil {
+		http.Error(w, "Failed to retrieve participants", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(participants)
+}
+
+// parseSession reads the course ID and date from the route parameters
+func parseSession(w http.ResponseWriter, ps httprouter.Params) (uint, time.Time, bool) {
+	courseID, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return 0, time.Time{}, false
+	}
+	date, err := time.Parse("2006-01-02", ps.ByName("date"))
+	if err != nil {
+		http.Error(w, "Invalid date format", http.StatusBadRequest)
+		return 0, time.Time{}, false
+	}
+	return uint(courseID), date, true
+}
+
+// streamHeartbeat is the interval of the comments keeping idle event streams open through proxies
+const streamHeartbeat = 25 * time.Second
+
+// StreamAttendance handles GET /api/courses/:id/dates/:date/stream. It sends the attendance changes
+// of the session as server-sent events until the client disconnects.
+func (h *ParticipationHandler) StreamAttendance(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	courseID, date, ok := parseSession(w, ps)
+	if !ok {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	messages, unsubscribe := h.participationService.SubscribeAttendance(courseID, date)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case message := <-messages:
+			fmt.Fprintf(w, "event: attendance\ndata: %s\n\n", message)
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		}
+	}
 }
 
-// ExportData handles GET /api/export?minDate=YYYY-MM-DD&maxDate=YYYY-MM-DD
+// ExportData handles GET /api/export?minDate=YYYY-MM-DD&maxDate=YYYY-MM-DD[&format=csv|xlsx][&layout=rows|matrix|sessions]
 func (h *ParticipationHandler) ExportData(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	query := r.URL.Query()
 	minDate := query.Get("minDate")
@@ -80,13 +259,51 @@ func (h *ParticipationHandler) ExportData(w http.ResponseWriter, r *http.Request
 		http.Error(w, "minDate and maxDate are required", http.StatusBadRequest)
 		return
 	}
+	if _, err := time.Parse("2006-01-02", minDate); err != nil {
+		http.Error(w, "Invalid date format", http.StatusBadRequest)
+		return
+	}
+	if _, err := time.Parse("2006-01-02", maxDate); err != nil {
+		http.Error(w, "Invalid date format", http.StatusBadRequest)
+		return
+	}
+
+	format := query.Get("format")
+	if format == "" {
+		format = service.ExportFormatCSV
+	}
+	layout := query.Get("layout")
+	if layout == "" {
+		layout = service.ExportLayoutRows
+	}
+	var contentType string
+	switch format {
+	case service.ExportFormatCSV:
+		contentType = "text/csv"
+	case service.ExportFormatXLSX:
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		http.Error(w, "Invalid export format", http.StatusBadRequest)
+		return
+	}
+	filename := "participations"
+	switch layout {
+	case service.ExportLayoutRows:
+	case service.ExportLayoutMatrix:
+		filename = "anwesenheitsliste"
+	case service.ExportLayoutSessions:
+		filename = "termine"
+	default:
+		http.Error(w, "Invalid export layout", http.StatusBadRequest)
+		return
+	}
 
-	csvData, err := h.participationService.ExportData(minDate, maxDate)
+	data, err := h.participationService.Export(minDate, maxDate, format, layout)
 	if err != nil {
 		http.Error(w, "Failed to export data", http.StatusInternalServerError)
 		return
 	}
-	w.Header().Set("Content-Type", "text/csv")
-	w.Header().Set("Content-Disposition", "attachment; filename=participations.csv")
-	w.Write([]byte(csvData))
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.%s", filename, format))
+	w.Write(data)
 }
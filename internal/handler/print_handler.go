@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"azh/internal/service"
+	"github.com/julienschmidt/httprouter"
+)
+
+// PrintHandler handles HTTP requests for printable documents
+type PrintHandler struct {
+	printService *service.PrintService
+}
+
+// NewPrintHandler creates a new PrintHandler
+func NewPrintHandler(printService *service.PrintService) *PrintHandler {
+	return &PrintHandler{printService: printService}
+}
+
+// GetAttendanceSheet handles GET /api/courses/:id/attendance-sheet?minDate=YYYY-MM-DD&maxDate=YYYY-MM-DD
+func (h *PrintHandler) GetAttendanceSheet(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	courseID, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+	query := r.URL.Query()
+	minDate := query.Get("minDate")
+	maxDate := query.Get("maxDate")
+	if minDate == "" || maxDate == "" {
+		http.Error(w, "minDate and maxDate are required", http.StatusBadRequest)
+		return
+	}
+	from, err := time.Parse("2006-01-02", minDate)
+	if err != nil {
+		http.Error(w, "Invalid date format", http.StatusBadRequest)
+		return
+	}
+	to, err := time.Parse("2006-01-02", maxDate)
+	if err != nil || to.Before(from) {
+		http.Error(w, "Invalid date range", http.StatusBadRequest)
+		return
+	}
+
+	pdf, err := h.printService.AttendanceSheetPDF(uint(courseID), minDate, maxDate)
+	if errors.Is(err, service.ErrCourseNotFound) {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to render attendance sheet", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=anwesenheitsliste-%d-%s-%s.pdf", courseID, minDate, maxDate))
+	w.Write(pdf)
+}
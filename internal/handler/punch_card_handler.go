@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"azh/internal/service"
+	"github.com/julienschmidt/httprouter"
+)
+
+// PunchCardHandler handles HTTP requests for drop-in punch cards
+type PunchCardHandler struct {
+	punchCardService *service.PunchCardService
+}
+
+// NewPunchCardHandler creates a new PunchCardHandler
+func NewPunchCardHandler(punchCardService *service.PunchCardService) *PunchCardHandler {
+	return &PunchCardHandler{punchCardService: punchCardService}
+}
+
+// SetDropIn handles PUT /api/courses/:id/drop-in
+func (h *PunchCardHandler) SetDropIn(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	courseID, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+	var req struct {
+		DropIn bool `json:"drop_in"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	err = h.punchCardService.SetDropIn(actor(r), uint(courseID), req.DropIn)
+	if errors.Is(err, service.ErrCourseNotFound) {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to update course", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SellCard handles POST /api/punch-cards
+func (h *PunchCardHandler) SellCard(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var req service.PunchCardSaleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	card, err := h.punchCardService.SellCard(actor(r), req, time.Now())
+	if errors.Is(err, service.ErrInvalidPunchCard) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, service.ErrMemberNotFound) {
+		http.Error(w, "Member not found", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to save punch card", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(card)
+}
+
+// GetCards handles GET /api/members/:id/punch-cards
+func (h *PunchCardHandler) GetCards(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	memberID, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid member ID", http.StatusBadRequest)
+		return
+	}
+	cards, err := h.punchCardService.GetCards(uint(memberID), time.Now())
+	if err != nil {
+		http.Error(w, "Failed to retrieve punch cards", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cards)
+}
+
+// GetReport handles GET /api/reports/punch-cards?minDate=YYYY-MM-DD&maxDate=YYYY-MM-DD
+func (h *PunchCardHandler) GetReport(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	query := r.URL.Query()
+	minDate := query.Get("minDate")
+	maxDate := query.Get("maxDate")
+	if minDate == "" || maxDate == "" {
+		http.Error(w, "minDate and maxDate are required", http.StatusBadRequest)
+		return
+	}
+	from, err := time.Parse("2006-01-02", minDate)
+	if err != nil {
+		http.Error(w, "Invalid date format", http.StatusBadRequest)
+		return
+	}
+	to, err := time.Parse("2006-01-02", maxDate)
+	if err != nil || to.Before(from) {
+		http.Error(w, "Invalid date range", http.StatusBadRequest)
+		return
+	}
+	report, err := h.punchCardService.GetReport(minDate, maxDate)
+	if err != nil {
+		http.Error(w, "Failed to compute punch card report", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"azh/internal/model"
+	"azh/internal/service"
+	"github.com/julienschmidt/httprouter"
+)
+
+// QualificationHandler handles HTTP requests for trainer qualifications
+type QualificationHandler struct {
+	qualificationService *service.QualificationService
+}
+
+// NewQualificationHandler creates a new QualificationHandler
+func NewQualificationHandler(qualificationService *service.QualificationService) *QualificationHandler {
+	return &QualificationHandler{qualificationService: qualificationService}
+}
+
+// GetQualifications handles GET /api/trainers/:id/qualifications
+func (h *QualificationHandler) GetQualifications(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	trainerID, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid trainer ID", http.StatusBadRequest)
+		return
+	}
+	qualifications, err := h.qualificationService.GetQualifications(uint(trainerID))
+	if err != nil {
+		http.Error(w, "Failed to retrieve qualifications", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(qualifications)
+}
+
+// AddQualification handles POST /api/trainers/:id/qualifications
+func (h *QualificationHandler) AddQualification(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	trainerID, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid trainer ID", http.StatusBadRequest)
+		return
+	}
+	var req struct {
+		Type      string `json:"type"`
+		IssuedAt  string `json:"issued_at"`
+		ExpiresAt string `json:"expires_at"`
+		Notes     string `json:"notes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	qualification := model.Qualification{TrainerID: uint(trainerID), Type: req.Type, Notes: req.Notes}
+	if qualification.IssuedAt, err = time.Parse("2006-01-02", req.IssuedAt); err != nil {
+		http.Error(w, "Invalid issue date format", http.StatusBadRequest)
+		return
+	}
+	if req.ExpiresAt != "" {
+		if qualification.ExpiresAt, err = time.Parse("2006-01-02", req.ExpiresAt); err != nil {
+			http.Error(w, "Invalid expiry date format", http.StatusBadRequest)
+			return
+		}
+	}
+
+	qualification, err = h.qualificationService.AddQualification(qualification)
+	if errors.Is(err, service.ErrTrainerNotFound) {
+		http.Error(w, "Trainer not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, service.ErrInvalidQualification) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to save qualification", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(qualification)
+}
+
+// RemoveQualification handles DELETE /api/trainers/:id/qualifications/:qualificationId
+func (h *QualificationHandler) RemoveQualification(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	trainerID, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid trainer ID", http.StatusBadRequest)
+		return
+	}
+	qualificationID, err := strconv.ParseUint(ps.ByName("qualificationId"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid qualification ID", http.StatusBadRequest)
+		return
+	}
+	if err := h.qualificationService.RemoveQualification(uint(trainerID), uint(qualificationID)); err != nil {
+		http.Error(w, "Failed to remove qualification", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetRequirements handles GET /api/qualification-requirements
+func (h *QualificationHandler) GetRequirements(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	requirements, err := h.qualificationService.GetRequirements()
+	if err != nil {
+		http.Error(w, "Failed to retrieve qualification requirements", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(requirements)
+}
+
+// SetRequirements handles PUT /api/qualification-requirements/:trainingType
+func (h *QualificationHandler) SetRequirements(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	var req struct {
+		Types []string `json:"types"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	err := h.qualificationService.SetRequirements(ps.ByName("trainingType"), req.Types)
+	if errors.Is(err, service.ErrInvalidQualification) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to update qualification requirements", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetExpiries handles GET /api/reports/qualification-expiries?days=N
+func (h *QualificationHandler) GetExpiries(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	days := 90
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		var err error
+		if days, err = strconv.Atoi(daysStr); err != nil || days < 0 {
+			http.Error(w, "Invalid number of days", http.StatusBadRequest)
+			return
+		}
+	}
+	expiries, err := h.qualificationService.GetExpiries(days, time.Now())
+	if err != nil {
+		http.Error(w, "Failed to retrieve qualification expiries", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(expiries)
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"azh/internal/model"
+	"azh/internal/service"
+	"github.com/julienschmidt/httprouter"
+)
+
+// RegistrationHandler handles HTTP requests for online registrations
+type RegistrationHandler struct {
+	registrationService *service.RegistrationService
+}
+
+// NewRegistrationHandler creates a new RegistrationHandler
+func NewRegistrationHandler(registrationService *service.RegistrationService) *RegistrationHandler {
+	return &RegistrationHandler{registrationService: registrationService}
+}
+
+// GetOpenCourses handles GET /api/public/courses
+func (h *RegistrationHandler) GetOpenCourses(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	courses, err := h.registrationService.GetOpenCourses(time.Now())
+	if err != nil {
+		http.Error(w, "Failed to retrieve courses", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(courses)
+}
+
+// Register handles POST /api/public/registrations
+func (h *RegistrationHandler) Register(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var req service.RegistrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	_, err := h.registrationService.Register(req, time.Now())
+	if errors.Is(err, service.ErrInvalidRegistration) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, service.ErrCourseUnavailable) {
+		http.Error(w, "Course not open for registration", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to save registration", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"status": model.RegistrationStatusUnconfirmed})
+}
+
+// ConfirmRegistration handles POST /api/public/registrations/confirm
+func (h *RegistrationHandler) ConfirmRegistration(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	registration, err := h.registrationService.Confirm(req.Token, time.Now())
+	if errors.Is(err, service.ErrRegistrationNotFound) {
+		http.Error(w, "Registration not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, service.ErrRegistrationExpired) {
+		http.Error(w, "Confirmation link expired", http.StatusGone)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to confirm registration", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": registration.Status})
+}
+
+// GetRegistrations handles GET /api/registrations?status=pending
+func (h *RegistrationHandler) GetRegistrations(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	registrations, err := h.registrationService.GetRegistrations(r.URL.Query().Get("status"))
+	if err != nil {
+		http.Error(w, "Failed to retrieve registrations", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(registrations)
+}
+
+// ApproveRegistration handles POST /api/registrations/:id/approve
+func (h *RegistrationHandler) ApproveRegistration(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid registration ID", http.StatusBadRequest)
+		return
+	}
+	registration, err := h.registrationService.Approve(actor(r), uint(id), time.Now())
+	if !h.writeDecisionError(w, err) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(registration)
+}
+
+// RejectRegistration handles POST /api/registrations/:id/reject
+func (h *RegistrationHandler) RejectRegistration(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid registration ID", http.StatusBadRequest)
+		return
+	}
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	registration, err := h.registrationService.Reject(uint(id), req.Reason, time.Now())
+	if !h.writeDecisionError(w, err) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(registration)
+}
+
+// writeDecisionError reports errors of approving or rejecting a registration; it returns true if there was none
+func (h *RegistrationHandler) writeDecisionError(w http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, service.ErrRegistrationNotFound):
+		http.Error(w, "Registration not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrRegistrationState):
+		http.Error(w, "Registration is not awaiting approval", http.StatusConflict)
+	default:
+		http.Error(w, "Failed to update registration", http.StatusInternalServerError)
+	}
+	return false
+}
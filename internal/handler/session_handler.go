@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"azh/internal/service"
+	"github.com/julienschmidt/httprouter"
+)
+
+// SessionHandler handles HTTP requests for session notes and training content
+type SessionHandler struct {
+	sessionService *service.SessionService
+}
+
+// NewSessionHandler creates a new SessionHandler
+func NewSessionHandler(sessionService *service.SessionService) *SessionHandler {
+	return &SessionHandler{sessionService: sessionService}
+}
+
+// GetSession handles GET /api/courses/:id/dates/:date/session
+func (h *SessionHandler) GetSession(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	courseID, date, ok := parseSession(w, ps)
+	if !ok {
+		return
+	}
+	session, err := h.sessionService.GetSession(courseID, date)
+	if errors.Is(err, service.ErrCourseNotFound) {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to retrieve session", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(session)
+}
+
+// UpdateSession handles PUT /api/courses/:id/dates/:date/session
+func (h *SessionHandler) UpdateSession(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	courseID, date, ok := parseSession(w, ps)
+	if !ok {
+		return
+	}
+	var req service.SessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	session, err := h.sessionService.UpdateSession(actor(r), courseID, date, req, time.Now())
+	switch {
+	case errors.Is(err, service.ErrCourseNotFound):
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrInvalidSession):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, service.ErrSessionLocked):
+		http.Error(w, "Session locked", http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "Failed to update session", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(session)
+}
+
+// CancelSession handles POST /api/courses/:id/dates/:date/cancel
+func (h *SessionHandler) CancelSession(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	courseID, date, ok := parseSession(w, ps)
+	if !ok {
+		return
+	}
+	var req service.CancelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	result, err := h.sessionService.CancelSession(actor(r), courseID, date, req, time.Now())
+	switch {
+	case errors.Is(err, service.ErrCourseNotFound):
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrInvalidSession):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, service.ErrSessionLocked):
+		http.Error(w, "Session locked", http.StatusConflict)
+		return
+	case errors.Is(err, service.ErrSessionCancelled):
+		http.Error(w, "Session already cancelled", http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "Failed to cancel session", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetNotifications handles GET /api/courses/:id/dates/:date/notifications
+func (h *SessionHandler) GetNotifications(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	courseID, date, ok := parseSession(w, ps)
+	if !ok {
+		return
+	}
+	result, err := h.sessionService.GetNotifications(courseID, date)
+	if errors.Is(err, service.ErrCourseNotFound) {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to retrieve notifications", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetSessions handles GET /api/courses/:id/sessions?minDate=YYYY-MM-DD&maxDate=YYYY-MM-DD
+func (h *SessionHandler) GetSessions(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	courseID, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+	query := r.URL.Query()
+	minDate := query.Get("minDate")
+	maxDate := query.Get("maxDate")
+	if minDate == "" {
+		minDate = "0001-01-01"
+	}
+	if maxDate == "" {
+		maxDate = "9999-12-31"
+	}
+	if _, err := time.Parse("2006-01-02", minDate); err != nil {
+		http.Error(w, "Invalid date format", http.StatusBadRequest)
+		return
+	}
+	if _, err := time.Parse("2006-01-02", maxDate); err != nil {
+		http.Error(w, "Invalid date format", http.StatusBadRequest)
+		return
+	}
+	sessions, err := h.sessionService.GetSessions(uint(courseID), minDate, maxDate)
+	if errors.Is(err, service.ErrCourseNotFound) {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to retrieve sessions", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sessions)
+}
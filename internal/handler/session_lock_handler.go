@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"azh/internal/service"
+	"github.com/julienschmidt/httprouter"
+)
+
+// SessionLockHandler handles HTTP requests for session locks and unlock requests
+type SessionLockHandler struct {
+	sessionLockService *service.SessionLockService
+}
+
+// NewSessionLockHandler creates a new SessionLockHandler
+func NewSessionLockHandler(sessionLockService *service.SessionLockService) *SessionLockHandler {
+	return &SessionLockHandler{sessionLockService: sessionLockService}
+}
+
+// GetLock handles GET /api/courses/:id/dates/:date/lock
+func (h *SessionLockHandler) GetLock(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	courseID, date, ok := parseSession(w, ps)
+	if !ok {
+		return
+	}
+	status, err := h.sessionLockService.GetLockStatus(courseID, date, time.Now())
+	if err != nil {
+		http.Error(w, "Failed to retrieve lock", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// Lock handles POST /api/courses/:id/dates/:date/lock
+func (h *SessionLockHandler) Lock(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	courseID, date, ok := parseSession(w, ps)
+	if !ok {
+		return
+	}
+	status, err := h.sessionLockService.Lock(actor(r), courseID, date, time.Now())
+	if errors.Is(err, service.ErrCourseNotFound) {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to lock session", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// RequestUnlock handles POST /api/courses/:id/dates/:date/unlock-requests
+func (h *SessionLockHandler) RequestUnlock(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	courseID, date, ok := parseSession(w, ps)
+	if !ok {
+		return
+	}
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	request, err := h.sessionLockService.RequestUnlock(actor(r), courseID, date, req.Reason, time.Now())
+	switch {
+	case errors.Is(err, service.ErrInvalidUnlockRequest):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, service.ErrCourseNotFound):
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrSessionNotLocked):
+		http.Error(w, "Session not locked", http.StatusConflict)
+		return
+	case errors.Is(err, service.ErrUnlockRequestDuplicated):
+		http.Error(w, "Unlock request already pending", http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "Failed to request unlock", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(request)
+}
+
+// GetUnlockRequests handles GET /api/admin/unlock-requests?status=pending
+func (h *SessionLockHandler) GetUnlockRequests(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	requests, err := h.sessionLockService.GetUnlockRequests(r.URL.Query().Get("status"))
+	if err != nil {
+		http.Error(w, "Failed to retrieve unlock requests", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(requests)
+}
+
+// ApproveUnlock handles POST /api/admin/unlock-requests/:id/approve
+func (h *SessionLockHandler) ApproveUnlock(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid unlock request ID", http.StatusBadRequest)
+		return
+	}
+	request, err := h.sessionLockService.ApproveUnlock(actor(r), uint(id), time.Now())
+	if !h.writeDecisionError(w, err) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(request)
+}
+
+// RejectUnlock handles POST /api/admin/unlock-requests/:id/reject
+func (h *SessionLockHandler) RejectUnlock(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid unlock request ID", http.StatusBadRequest)
+		return
+	}
+	request, err := h.sessionLockService.RejectUnlock(actor(r), uint(id), time.Now())
+	if !h.writeDecisionError(w, err) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(request)
+}
+
+// writeDecisionError reports errors of approving or rejecting an unlock request; it returns true if there was none
+func (h *SessionLockHandler) writeDecisionError(w http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, service.ErrUnlockRequestNotFound):
+		http.Error(w, "Unlock request not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrUnlockRequestState):
+		http.Error(w, "Unlock request already decided", http.StatusConflict)
+	default:
+		http.Error(w, "Failed to decide unlock request", http.StatusInternalServerError)
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"azh/internal/repository"
+	"azh/internal/service"
+	"github.com/julienschmidt/httprouter"
+)
+
+// StatsHandler handles HTTP requests for attendance statistics
+type StatsHandler struct {
+	statsService *service.StatsService
+}
+
+// NewStatsHandler creates a new StatsHandler
+func NewStatsHandler(statsService *service.StatsService) *StatsHandler {
+	return &StatsHandler{statsService: statsService}
+}
+
+// GetMemberStats handles GET /api/stats/members?minDate=YYYY-MM-DD&maxDate=YYYY-MM-DD[&courseId=][&memberId=]
+func (h *StatsHandler) GetMemberStats(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	filter, ok := parseStatsFilter(w, r)
+	if !ok {
+		return
+	}
+	stats, err := h.statsService.GetMemberStats(filter)
+	if err != nil {
+		http.Error(w, "Failed to compute member statistics", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// GetCourseStats handles GET /api/stats/courses?minDate=YYYY-MM-DD&maxDate=YYYY-MM-DD[&courseId=]
+func (h *StatsHandler) GetCourseStats(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	filter, ok := parseStatsFilter(w, r)
+	if !ok {
+		return
+	}
+	stats, err := h.statsService.GetCourseStats(filter)
+	if err != nil {
+		http.Error(w, "Failed to compute course statistics", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// GetSessionHeadCounts handles GET /api/stats/courses/:id/sessions?minDate=YYYY-MM-DD&maxDate=YYYY-MM-DD
+func (h *StatsHandler) GetSessionHeadCounts(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	filter, ok := parseStatsFilter(w, r)
+	if !ok {
+		return
+	}
+	courseID, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+	filter.CourseID = uint(courseID)
+	counts, err := h.statsService.GetSessionHeadCounts(filter)
+	if err != nil {
+		http.Error(w, "Failed to compute session head counts", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(counts)
+}
+
+// GetStreaks handles GET /api/stats/streaks?minDate=YYYY-MM-DD&maxDate=YYYY-MM-DD[&courseId=][&memberId=]
+func (h *StatsHandler) GetStreaks(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	filter, ok := parseStatsFilter(w, r)
+	if !ok {
+		return
+	}
+	streaks, err := h.statsService.GetStreaks(filter)
+	if err != nil {
+		http.Error(w, "Failed to compute streaks", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(streaks)
+}
+
+// parseStatsFilter reads the date range and optional course and member IDs from the query string
+func parseStatsFilter(w http.ResponseWriter, r *http.Request) (repository.StatsFilter, bool) {
+	query := r.URL.Query()
+	filter := repository.StatsFilter{
+		MinDate: query.Get("minDate"),
+		MaxDate: query.Get("maxDate"),
+	}
+	if filter.MinDate == "" || filter.MaxDate == "" {
+		http.Error(w, "minDate and maxDate are required", http.StatusBadRequest)
+		return filter, false
+	}
+	minDate, err := time.Parse("2006-01-02", filter.MinDate)
+	if err != nil {
+		http.Error(w, "Invalid date format", http.StatusBadRequest)
+		return filter, false
+	}
+	maxDate, err := time.Parse("2006-01-02", filter.MaxDate)
+	if err != nil || maxDate.Before(minDate) {
+		http.Error(w, "Invalid date range", http.StatusBadRequest)
+		return filter, false
+	}
+	if courseIDStr := query.Get("courseId"); courseIDStr != "" {
+		courseID, err := strconv.ParseUint(courseIDStr, 10, 32)
+		if err != nil {
+			http.Error(w, "Invalid course ID", http.StatusBadRequest)
+			return filter, false
+		}
+		filter.CourseID = uint(courseID)
+	}
+	if memberIDStr := query.Get("memberId"); memberIDStr != "" {
+		memberID, err := strconv.ParseUint(memberIDStr, 10, 32)
+		if err != nil {
+			http.Error(w, "Invalid member ID", http.StatusBadRequest)
+			return filter, false
+		}
+		filter.MemberID = uint(memberID)
+	}
+	return filter, true
+}
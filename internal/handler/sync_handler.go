@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"azh/internal/service"
+	"github.com/julienschmidt/httprouter"
+)
+
+// maxSyncChanges limits the number of changes accepted per sync request
+const maxSyncChanges = 1000
+
+// SyncHandler handles HTTP requests for syncing offline attendance changes
+type SyncHandler struct {
+	syncService *service.SyncService
+}
+
+// NewSyncHandler creates a new SyncHandler
+func NewSyncHandler(syncService *service.SyncService) *SyncHandler {
+	return &SyncHandler{syncService: syncService}
+}
+
+// Sync handles POST /api/sync
+func (h *SyncHandler) Sync(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var req struct {
+		ClientID string               `json:"client_id"`
+		Changes  []service.SyncChange `json:"changes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.Changes) > maxSyncChanges {
+		http.Error(w, "Too many changes", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	result, err := h.syncService.Sync(actor(r), req.ClientID, req.Changes, time.Now())
+	if errors.Is(err, service.ErrInvalidSync) {
+		http.Error(w, "Client ID and change IDs are required", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to sync changes", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"azh/internal/service"
+	"github.com/julienschmidt/httprouter"
+)
+
+// TrainerHandler handles HTTP requests for trainers
+type TrainerHandler struct {
+	trainerService *service.TrainerService
+}
+
+// NewTrainerHandler creates a new TrainerHandler
+func NewTrainerHandler(trainerService *service.TrainerService) *TrainerHandler {
+	return &TrainerHandler{trainerService: trainerService}
+}
+
+// GetTrainers handles GET /api/trainers
+func (h *TrainerHandler) GetTrainers(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	trainers, err := h.trainerService.GetTrainers()
+	if err != nil {
+		http.Error(w, "Failed to retrieve trainers", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(trainers)
+}
+
+// CreateTrainer handles POST /api/admin/trainers
+func (h *TrainerHandler) CreateTrainer(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var req service.TrainerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	trainer, err := h.trainerService.CreateTrainer(actor(r), req)
+	if !h.writeTrainerError(w, err) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(trainer)
+}
+
+// UpdateTrainer handles PUT /api/admin/trainers/:id
+func (h *TrainerHandler) UpdateTrainer(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	trainerID, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid trainer ID", http.StatusBadRequest)
+		return
+	}
+	var req service.TrainerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	trainer, err := h.trainerService.UpdateTrainer(actor(r), uint(trainerID), req)
+	if !h.writeTrainerError(w, err) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(trainer)
+}
+
+// writeTrainerError reports errors of trainer requests; it returns true if there was none
+func (h *TrainerHandler) writeTrainerError(w http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, service.ErrInvalidTrainer):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrTrainerNotFound):
+		http.Error(w, "Trainer not found", http.StatusNotFound)
+	default:
+		http.Error(w, "Failed to save trainer", http.StatusInternalServerError)
+	}
+	return false
+}
+
+// GetSessionTrainers handles GET /api/courses/:id/dates/:date/trainers
+func (h *TrainerHandler) GetSessionTrainers(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	courseID, date, ok := parseCourseAndDate(w, ps)
+	if !ok {
+		return
+	}
+	trainers, err := h.trainerService.GetSessionTrainers(courseID, date)
+	if errors.Is(err, service.ErrCourseNotFound) {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to retrieve session trainers", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(trainers)
+}
+
+// SetSessionTrainers handles PUT /api/courses/:id/dates/:date/trainers
+func (h *TrainerHandler) SetSessionTrainers(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	courseID, date, ok := parseCourseAndDate(w, ps)
+	if !ok {
+		return
+	}
+	var req struct {
+		Trainers []service.TrainerAssignment `json:"trainers"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	trainers, err := h.trainerService.SetSessionTrainers(courseID, date, req.Trainers)
+	if errors.Is(err, service.ErrCourseNotFound) {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, service.ErrTrainerNotFound) {
+		http.Error(w, "Trainer not found", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to update session trainers", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(trainers)
+}
+
+// AddUnavailability handles POST /api/trainers/:id/unavailability
+func (h *TrainerHandler) AddUnavailability(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	trainerID, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid trainer ID", http.StatusBadRequest)
+		return
+	}
+	var req struct {
+		Date     string `json:"date"`
+		CourseID *uint  `json:"course_id"`
+		Reason   string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		http.Error(w, "Invalid date format", http.StatusBadRequest)
+		return
+	}
+
+	unavailability, err := h.trainerService.AddUnavailability(uint(trainerID), date, req.CourseID, req.Reason)
+	if errors.Is(err, service.ErrTrainerNotFound) {
+		http.Error(w, "Trainer not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, service.ErrCourseNotFound) {
+		http.Error(w, "Course not found", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to save unavailability", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(unavailability)
+}
+
+// RemoveUnavailability handles DELETE /api/trainers/:id/unavailability/:unavailabilityId
+func (h *TrainerHandler) RemoveUnavailability(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	trainerID, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid trainer ID", http.StatusBadRequest)
+		return
+	}
+	unavailabilityID, err := strconv.ParseUint(ps.ByName("unavailabilityId"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid unavailability ID", http.StatusBadRequest)
+		return
+	}
+	if err := h.trainerService.RemoveUnavailability(uint(trainerID), uint(unavailabilityID)); err != nil {
+		http.Error(w, "Failed to remove unavailability", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetUncoveredSessions handles GET /api/sessions/uncovered?minDate=YYYY-MM-DD&maxDate=YYYY-MM-DD
+func (h *TrainerHandler) GetUncoveredSessions(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	query := r.URL.Query()
+	minDate := query.Get("minDate")
+	maxDate := query.Get("maxDate")
+	if minDate == "" || maxDate == "" {
+		http.Error(w, "minDate and maxDate are required", http.StatusBadRequest)
+		return
+	}
+	from, err := time.Parse("2006-01-02", minDate)
+	if err != nil {
+		http.Error(w, "Invalid date format", http.StatusBadRequest)
+		return
+	}
+	to, err := time.Parse("2006-01-02", maxDate)
+	if err != nil || to.Before(from) {
+		http.Error(w, "Invalid date range", http.StatusBadRequest)
+		return
+	}
+	sessions, err := h.trainerService.GetUncoveredSessions(minDate, maxDate)
+	if err != nil {
+		http.Error(w, "Failed to retrieve uncovered sessions", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sessions)
+}
+
+// GetHoursReport handles GET /api/reports/trainer-hours?year=YYYY
+func (h *TrainerHandler) GetHoursReport(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	year := time.Now().Year()
+	if yearStr := r.URL.Query().Get("year"); yearStr != "" {
+		var err error
+		if year, err = strconv.Atoi(yearStr); err != nil || year < 2000 || year > 9999 {
+			http.Error(w, "Invalid year", http.StatusBadRequest)
+			return
+		}
+	}
+	report, err := h.trainerService.GetHoursReport(year)
+	if err != nil {
+		http.Error(w, "Failed to compute trainer hours", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// parseCourseAndDate reads the course ID and session date from the route parameters
+func parseCourseAndDate(w http.ResponseWriter, ps httprouter.Params) (uint, time.Time, bool) {
+	courseID, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return 0, time.Time{}, false
+	}
+	date, err := time.Parse("2006-01-02", ps.ByName("date"))
+	if err != nil {
+		http.Error(w, "Invalid date format", http.StatusBadRequest)
+		return 0, time.Time{}, false
+	}
+	return uint(courseID), date, true
+}
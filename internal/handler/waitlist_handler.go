@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"azh/internal/service"
+	"github.com/julienschmidt/httprouter"
+)
+
+// WaitlistHandler handles HTTP requests for course capacity, enrollments and waitlists
+type WaitlistHandler struct {
+	waitlistService *service.WaitlistService
+}
+
+// NewWaitlistHandler creates a new WaitlistHandler
+func NewWaitlistHandler(waitlistService *service.WaitlistService) *WaitlistHandler {
+	return &WaitlistHandler{waitlistService: waitlistService}
+}
+
+// GetWaitlist handles GET /api/courses/:id/waitlist
+func (h *WaitlistHandler) GetWaitlist(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	courseID, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+	waitlist, err := h.waitlistService.GetWaitlist(uint(courseID), time.Now())
+	if errors.Is(err, service.ErrCourseNotFound) {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to retrieve waitlist", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(waitlist)
+}
+
+// SetCapacity handles PUT /api/courses/:id/capacity
+func (h *WaitlistHandler) SetCapacity(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	courseID, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+	var req struct {
+		MaxParticipants int `json:"max_participants"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.MaxParticipants < 0 {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	err = h.waitlistService.SetCapacity(actor(r), uint(courseID), req.MaxParticipants, time.Now())
+	if errors.Is(err, service.ErrCourseNotFound) {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to update capacity", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Enroll handles POST /api/courses/:id/enrollments
+func (h *WaitlistHandler) Enroll(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	courseID, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+	var req struct {
+		MemberID uint `json:"member_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	enrollment, err := h.waitlistService.Enroll(actor(r), uint(courseID), req.MemberID, time.Now())
+	switch {
+	case errors.Is(err, service.ErrCourseNotFound):
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrMemberNotFound):
+		http.Error(w, "Member not found", http.StatusBadRequest)
+		return
+	case errors.Is(err, service.ErrMemberCancelled):
+		http.Error(w, "Membership cancelled", http.StatusUnprocessableEntity)
+		return
+	case errors.Is(err, service.ErrAlreadyEnrolled), errors.Is(err, service.ErrAlreadyWaiting):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "Failed to enroll member", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(enrollment)
+}
+
+// Unenroll handles DELETE /api/courses/:id/enrollments/:memberId
+func (h *WaitlistHandler) Unenroll(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	courseID, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+	memberID, err := strconv.ParseUint(ps.ByName("memberId"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid member ID", http.StatusBadRequest)
+		return
+	}
+	err = h.waitlistService.Unenroll(actor(r), uint(courseID), uint(memberID), time.Now())
+	if errors.Is(err, service.ErrCourseNotFound) {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to unenroll member", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"azh/internal/service"
+	"github.com/julienschmidt/httprouter"
+)
+
+// WebhookHandler handles HTTP requests for outgoing webhooks and their delivery log
+type WebhookHandler struct {
+	webhookService *service.WebhookService
+}
+
+// NewWebhookHandler creates a new WebhookHandler
+func NewWebhookHandler(webhookService *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService}
+}
+
+// GetWebhooks handles GET /api/admin/webhooks
+func (h *WebhookHandler) GetWebhooks(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	webhooks, err := h.webhookService.GetWebhooks()
+	if err != nil {
+		http.Error(w, "Failed to retrieve webhooks", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhooks)
+}
+
+// CreateWebhook handles POST /api/admin/webhooks; the response contains the signing secret
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var req service.WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	webhook, err := h.webhookService.CreateWebhook(actor(r), req)
+	if !h.writeWebhookError(w, err) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(webhook)
+}
+
+// UpdateWebhook handles PUT /api/admin/webhooks/:id
+func (h *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := parseWebhookID(w, ps)
+	if !ok {
+		return
+	}
+	var req service.WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	webhook, err := h.webhookService.UpdateWebhook(actor(r), id, req)
+	if !h.writeWebhookError(w, err) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhook)
+}
+
+// DeleteWebhook handles DELETE /api/admin/webhooks/:id
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := parseWebhookID(w, ps)
+	if !ok {
+		return
+	}
+	if !h.writeWebhookError(w, h.webhookService.DeleteWebhook(actor(r), id)) {
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SendTest handles POST /api/admin/webhooks/:id/test and answers with the logged delivery, which
+// tells whether the receiver accepted the test event
+func (h *WebhookHandler) SendTest(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := parseWebhookID(w, ps)
+	if !ok {
+		return
+	}
+	delivery, err := h.webhookService.SendTest(actor(r), id, time.Now())
+	if !h.writeWebhookError(w, err) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(delivery)
+}
+
+// GetDeliveries handles GET /api/admin/webhook-deliveries?webhookId=1&status=failed
+func (h *WebhookHandler) GetDeliveries(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	query := r.URL.Query()
+	var webhookID uint64
+	if value := query.Get("webhookId"); value != "" {
+		var err error
+		if webhookID, err = strconv.ParseUint(value, 10, 32); err != nil {
+			http.Error(w, "Invalid webhook ID", http.StatusBadRequest)
+			return
+		}
+	}
+	deliveries, err := h.webhookService.GetDeliveries(uint(webhookID), query.Get("status"))
+	if err != nil {
+		http.Error(w, "Failed to retrieve webhook deliveries", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+// RetryDelivery handles POST /api/admin/webhook-deliveries/:id/retry
+func (h *WebhookHandler) RetryDelivery(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid delivery ID", http.StatusBadRequest)
+		return
+	}
+	delivery, err := h.webhookService.RetryDelivery(uint(id), time.Now())
+	if !h.writeWebhookError(w, err) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(delivery)
+}
+
+// writeWebhookError reports errors of webhook requests; it returns true if there was none
+func (h *WebhookHandler) writeWebhookError(w http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, service.ErrInvalidWebhook):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrWebhookNotFound):
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrWebhookDeliveryNotFound):
+		http.Error(w, "Webhook delivery not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrWebhookDeliveryState):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, "Failed to process webhook request", http.StatusInternalServerError)
+	}
+	return false
+}
+
+// parseWebhookID reads the webhook ID from the route parameters
+func parseWebhookID(w http.ResponseWriter, ps httprouter.Params) (uint, bool) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid webhook ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return uint(id), true
+}
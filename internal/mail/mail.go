@@ -0,0 +1,61 @@
+package mail
+
+import (
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Mailer sends plain-text emails via SMTP
+type Mailer struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+// NewMailer creates a new Mailer; an empty host disables sending
+func NewMailer(host, port, username, password, from string) *Mailer {
+	return &Mailer{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+// Enabled reports whether an SMTP server is configured
+func (m *Mailer) Enabled() bool {
+	return m.host != ""
+}
+
+// Send delivers a plain-text email to the given recipients
+func (m *Mailer) Send(to []string, subject, body string) error {
+	if !m.Enabled() {
+		return fmt.Errorf("no SMTP server configured")
+	}
+	if len(to) == 0 {
+		return fmt.Errorf("no recipients given")
+	}
+
+	var msg strings.Builder
+	msg.WriteString("From: " + m.from + "\r\n")
+	msg.WriteString("To: " + strings.Join(to, ", ") + "\r\n")
+	msg.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	msg.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+	return smtp.SendMail(m.host+":"+m.port, auth, m.from, to, []byte(msg.String()))
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Outcomes of attendance changes
+const (
+	AttendanceChangeApplied    = "applied"
+	AttendanceChangeSuperseded = "superseded"
+	AttendanceChangeRejected   = "rejected"
+)
+
+// AttendanceChange journals an attendance change made online or synced from an offline device. Changes
+// made online have no client ID. The journal makes syncs idempotent and decides conflicts by ChangedAt.
+type AttendanceChange struct {
+	gorm.Model
+	ClientID  string    `gorm:"type:varchar(64);uniqueIndex:idx_attendance_change_client" json:"client_id"`
+	ChangeID  string    `gorm:"type:varchar(64);uniqueIndex:idx_attendance_change_client" json:"change_id"`
+	Actor     string    `gorm:"type:varchar(100)" json:"actor"`
+	MemberID  uint      `gorm:"index:idx_attendance_change_session" json:"member_id"`
+	CourseID  uint      `gorm:"index:idx_attendance_change_session" json:"course_id"`
+	Date      time.Time `gorm:"type:date;index:idx_attendance_change_session" json:"date"`
+	Status    string    `gorm:"type:varchar(20);not null" json:"status"`
+	ChangedAt time.Time `gorm:"not null" json:"changed_at"` // device time of the change
+	Outcome   string    `gorm:"type:varchar(20);not null" json:"outcome"`
+	Reason    string    `gorm:"type:text" json:"reason"`
+}
@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// Audit actions
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+	AuditActionImport = "import"
+)
+
+// Audited entities
+const (
+	AuditEntityParticipation       = "participation"
+	AuditEntityMember              = "member"
+	AuditEntityCourse              = "course"
+	AuditEntityEnrollment          = "enrollment"
+	AuditEntityImport              = "import"
+	AuditEntitySession             = "session"
+	AuditEntitySessionLock         = "session_lock"
+	AuditEntityUnlockRequest       = "unlock_request"
+	AuditEntityIncident            = "incident"
+	AuditEntityLocation            = "location"
+	AuditEntityNotificationSetting = "notification_setting"
+	AuditEntityGuardian            = "guardian"
+	AuditEntityAbsence             = "absence"
+	AuditEntityWebhook             = "webhook"
+	AuditEntityTrainer             = "trainer"
+)
+
+// AuditEntry records a change of application data. Entries are only ever appended, so the struct
+// has no update or soft delete timestamps. Before and After hold the changed values as JSON.
+type AuditEntry struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+	Actor     string    `gorm:"type:varchar(100);index" json:"actor"`
+	Action    string    `gorm:"type:varchar(20);not null" json:"action"`
+	Entity    string    `gorm:"type:varchar(50);not null;index:idx_audit_entity" json:"entity"`
+	EntityID  string    `gorm:"type:varchar(100);index:idx_audit_entity" json:"entity_id"`
+	Before    string    `gorm:"type:text" json:"before"`
+	After     string    `gorm:"type:text" json:"after"`
+}
@@ -0,0 +1,19 @@
+package model
+
+import "gorm.io/gorm"
+
+// Calendar feed kinds
+const (
+	CalendarFeedCourse  = "course"
+	CalendarFeedTrainer = "trainer"
+	CalendarFeedClub    = "club" // all courses, for the office
+)
+
+// CalendarFeed grants read access to an iCalendar feed through the secret token in its URL.
+// Rotating the token revokes the previous URL.
+type CalendarFeed struct {
+	gorm.Model
+	Kind      string `gorm:"type:varchar(20);not null;uniqueIndex:idx_calendar_feed" json:"kind"`
+	SubjectID uint   `gorm:"not null;uniqueIndex:idx_calendar_feed" json:"subject_id"` // course or trainer ID, 0 for the club feed
+	Token     string `gorm:"type:varchar(64);uniqueIndex" json:"-"`
+}
@@ -5,17 +5,40 @@ import (
 	"time"
 )
 
-// Course represents a training course
+// Course kinds
+const (
+	CourseKindWeekly = "course" // weekly course, scheduled by weekday
+	CourseKindEvent  = "event"  // one-off event or workshop with explicit dates
+)
+
+// EventIDOffset is the first ID of events created in this application; lower IDs are reserved for
+// courses imported from TrainingsStatistik.csv
+const EventIDOffset uint = 1000000
+
+// Course represents a training course or, with kind event, a one-off event
 type Course struct {
 	gorm.Model
-	ID            uint      `gorm:"primaryKey" json:"id"`
-	Name          string    `gorm:"type:varchar(255)" json:"name"`
-	Location      string    `gorm:"type:varchar(100)" json:"location"`
-	TrainingType  string    `gorm:"type:varchar(100)" json:"training_type"`
-	Weekday       string    `gorm:"type:varchar(20)" json:"weekday"`
-	StartTime     string    `gorm:"type:varchar(10)" json:"start_time"`
-	EndTime       string    `gorm:"type:varchar(10)" json:"end_time"`
-	FirstSchedule time.Time `gorm:"type:date" json:"first_schedule"`
-	LastSchedule  time.Time `gorm:"type:date" json:"last_schedule"`
-	TrainerNames  string    `gorm:"type:text" json:"trainer_names"`
+	ID              uint        `gorm:"primaryKey" json:"id"`
+	Name            string      `gorm:"type:varchar(255)" json:"name"`
+	Location        string      `gorm:"type:varchar(100)" json:"location"` // free text as imported
+	LocationID      *uint       `gorm:"index" json:"location_id"`
+	RoomID          *uint       `gorm:"index" json:"room_id"`
+	TrainingType    string      `gorm:"type:varchar(100)" json:"training_type"`
+	Weekday         string      `gorm:"type:varchar(20)" json:"weekday"`
+	StartTime       string      `gorm:"type:varchar(10)" json:"start_time"`
+	EndTime         string      `gorm:"type:varchar(10)" json:"end_time"`
+	FirstSchedule   time.Time   `gorm:"type:date" json:"first_schedule"`
+	LastSchedule    time.Time   `gorm:"type:date" json:"last_schedule"`
+	TrainerNames    string      `gorm:"type:text" json:"trainer_names"`
+	MaxParticipants int         `gorm:"not null;default:0" json:"max_participants"` // 0 means unlimited
+	Kind            string      `gorm:"type:varchar(20);not null;default:course" json:"kind"`
+	Description     string      `gorm:"type:text" json:"description"`
+	Fee             float64     `gorm:"type:numeric(10,2);not null;default:0" json:"fee"`
+	DropIn          bool        `gorm:"not null;default:false" json:"drop_in"` // open session paid with punch cards
+	EventDates      []EventDate `gorm:"foreignKey:CourseID" json:"event_dates,omitempty"`
+}
+
+// IsEvent reports whether the course is a one-off event
+func (c Course) IsEvent() bool {
+	return c.Kind == CourseKindEvent
 }
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// EventDate represents a date of a one-off event with its own times
+type EventDate struct {
+	gorm.Model
+	CourseID  uint      `gorm:"index" json:"course_id"`
+	Date      time.Time `gorm:"type:date" json:"date"`
+	StartTime string    `gorm:"type:varchar(10)" json:"start_time"`
+	EndTime   string    `gorm:"type:varchar(10)" json:"end_time"`
+}
+
+// EventRegistration represents the registration of a member or guest for an event, including the
+// fee due. The matching MemberCourse makes the participant show up for attendance recording.
+type EventRegistration struct {
+	gorm.Model
+	CourseID uint       `gorm:"index" json:"course_id"`
+	MemberID uint       `gorm:"index" json:"member_id"`
+	Fee      float64    `gorm:"type:numeric(10,2);not null;default:0" json:"fee"`
+	PaidAt   *time.Time `json:"paid_at"`
+	Notes    string     `gorm:"type:text" json:"notes"`
+}
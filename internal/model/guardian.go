@@ -0,0 +1,36 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Guardian is a parent or other guardian who excuses their children from sessions through a
+// personal link. The secret token in the link grants access to these children only; rotating
+// it revokes the previous link.
+type Guardian struct {
+	gorm.Model
+	Name     string          `gorm:"type:varchar(200);not null" json:"name"`
+	Email    string          `gorm:"type:varchar(255);index" json:"email"`
+	Phone    string          `gorm:"type:varchar(50)" json:"phone"`
+	Token    string          `gorm:"type:varchar(64);uniqueIndex" json:"-"`
+	Children []GuardianChild `json:"children"`
+}
+
+// GuardianChild links a guardian to a member they may excuse
+type GuardianChild struct {
+	gorm.Model
+	GuardianID uint `gorm:"not null;uniqueIndex:idx_guardian_child" json:"guardian_id"`
+	MemberID   uint `gorm:"not null;uniqueIndex:idx_guardian_child;index" json:"member_id"`
+}
+
+// Absence is an absence reported in advance by a guardian. The session's attendance is set to
+// excused at the same time, so the reason is only kept to show it on the participant list.
+type Absence struct {
+	gorm.Model
+	GuardianID uint      `gorm:"not null;index" json:"guardian_id"`
+	MemberID   uint      `gorm:"not null;uniqueIndex:idx_absence" json:"member_id"`
+	CourseID   uint      `gorm:"not null;uniqueIndex:idx_absence" json:"course_id"`
+	Date       time.Time `gorm:"type:date;not null;uniqueIndex:idx_absence" json:"date"`
+	Reason     string    `gorm:"type:text" json:"reason"`
+}
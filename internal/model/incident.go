@@ -0,0 +1,33 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Incident statuses
+const (
+	IncidentStatusReported  = "reported"
+	IncidentStatusForwarded = "forwarded" // forwarded to the sports insurance
+	IncidentStatusClosed    = "closed"
+)
+
+// Incident documents an accident or injury during a session for the sports insurance. Incidents
+// contain health data and are only visible through the admin API.
+type Incident struct {
+	gorm.Model
+	CourseID         uint       `gorm:"not null;index:idx_incident_session" json:"course_id"`
+	Date             time.Time  `gorm:"type:date;not null;index:idx_incident_session" json:"date"`
+	MemberID         uint       `gorm:"not null;index" json:"member_id"`
+	OccurredAt       time.Time  `json:"occurred_at"`
+	Location         string     `gorm:"type:varchar(200)" json:"location"`
+	Injury           string     `gorm:"type:varchar(200)" json:"injury"`
+	Description      string     `gorm:"type:text" json:"description"`
+	FirstAid         string     `gorm:"type:text" json:"first_aid"`
+	Witnesses        string     `gorm:"type:text" json:"witnesses"`
+	ReportedBy       string     `gorm:"type:varchar(100)" json:"reported_by"`
+	Status           string     `gorm:"type:varchar(20);index;not null" json:"status"`
+	InsurerReference string     `gorm:"type:varchar(100)" json:"insurer_reference"`
+	ForwardedAt      *time.Time `json:"forwarded_at"`
+	ClosedAt         *time.Time `json:"closed_at"`
+}
@@ -0,0 +1,41 @@
+package model
+
+import "gorm.io/gorm"
+
+// Location represents a place where courses take place, e.g. a gym with several rooms or areas
+type Location struct {
+	gorm.Model
+	Name         string          `gorm:"type:varchar(100);uniqueIndex" json:"name"`
+	Address      string          `gorm:"type:varchar(255)" json:"address"`
+	Capacity     int             `gorm:"not null;default:0" json:"capacity"` // 0 means unlimited
+	Rooms        []Room          `gorm:"foreignKey:LocationID" json:"rooms"`
+	OpeningHours []OpeningHours  `gorm:"foreignKey:LocationID" json:"opening_hours"`
+	Aliases      []LocationAlias `gorm:"foreignKey:LocationID" json:"aliases"`
+}
+
+// Room represents a room or area of a location that courses can be scheduled in
+type Room struct {
+	gorm.Model
+	LocationID uint   `gorm:"not null;uniqueIndex:idx_room_name" json:"location_id"`
+	Name       string `gorm:"type:varchar(100);uniqueIndex:idx_room_name" json:"name"`
+	Capacity   int    `gorm:"not null;default:0" json:"capacity"` // 0 means unlimited
+}
+
+// OpeningHours holds the time a location is open on a weekday. A location without opening hours
+// is considered always open.
+type OpeningHours struct {
+	gorm.Model
+	LocationID uint   `gorm:"not null;index" json:"location_id"`
+	Weekday    string `gorm:"type:varchar(20)" json:"weekday"` // German weekday name as in course data
+	Opens      string `gorm:"type:varchar(10)" json:"opens"`
+	Closes     string `gorm:"type:varchar(10)" json:"closes"`
+}
+
+// LocationAlias maps the free-text location of imported courses to a location and, optionally, a
+// room. Aliases are stored trimmed and in lower case.
+type LocationAlias struct {
+	gorm.Model
+	LocationID uint   `gorm:"not null;index" json:"location_id"`
+	RoomID     *uint  `json:"room_id"`
+	Alias      string `gorm:"type:varchar(100);uniqueIndex" json:"alias"`
+}
@@ -5,7 +5,16 @@ import (
 	"time"
 )
 
-// Member represents a club member
+// ID ranges of members not taken from the club's member numbers. Imports skip rows with IDs from
+// these ranges, so they never overwrite members created in the application.
+const (
+	// RegisteredIDOffset is the first ID of members created from online registrations; lower IDs are member numbers
+	RegisteredIDOffset uint = 900000000
+	// GuestIDOffset is the first ID of non-members registered for events
+	GuestIDOffset uint = 1000000000
+)
+
+// Member represents a club member, or a guest registered for an event
 type Member struct {
 	gorm.Model
 	ID               uint      `gorm:"primaryKey" json:"id"`
@@ -17,4 +26,5 @@ type Member struct {
 	CancellationDate time.Time `gorm:"type:date" json:"cancellation_date"`
 	Age              int       `json:"age"`
 	Notes            string    `gorm:"type:text" json:"notes"`
+	Guest            bool      `gorm:"not null;default:false" json:"guest"`
 }
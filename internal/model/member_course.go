@@ -2,9 +2,12 @@ package model
 
 import "gorm.io/gorm"
 
-// MemberCourse represents the n:m relationship between members and courses
+// MemberCourse represents the n:m relationship between members and courses. Manual enrollments
+// were made in this application, e.g. by waitlist promotion; the import keeps them until the
+// Trainingsanmeldungen file lists them as well.
 type MemberCourse struct {
 	gorm.Model
 	MemberID uint `gorm:"index" json:"member_id"`
 	CourseID uint `gorm:"index" json:"course_id"`
+	Manual   bool `gorm:"not null;default:false" json:"manual"`
 }
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Notification statuses
+const (
+	NotificationPending  = "pending"
+	NotificationSent     = "sent"
+	NotificationFailed   = "failed"    // given up after the maximum number of attempts
+	NotificationOptedOut = "opted_out" // not sent as the recipient opted out
+)
+
+// Notification is a rendered message in the outbox. Pending notifications are sent in the
+// background and retried with increasing delay until they are sent or the attempts run out.
+type Notification struct {
+	gorm.Model
+	Channel       string     `gorm:"type:varchar(20);not null" json:"channel"`
+	Recipient     string     `gorm:"type:varchar(255)" json:"recipient"`
+	MemberID      *uint      `gorm:"index" json:"member_id"`
+	Template      string     `gorm:"type:varchar(50);not null" json:"template"`
+	Language      string     `gorm:"type:varchar(5)" json:"language"`
+	Reference     string     `gorm:"type:varchar(100);index" json:"reference"` // what the notification is about, e.g. a session
+	Subject       string     `gorm:"type:varchar(255)" json:"subject"`
+	Body          string     `gorm:"type:text" json:"body"`
+	Status        string     `gorm:"type:varchar(20);not null;index:idx_notification_due" json:"status"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time  `gorm:"index:idx_notification_due" json:"next_attempt_at"`
+	SentAt        *time.Time `json:"sent_at"`
+	LastError     string     `gorm:"type:text" json:"last_error"`
+}
+
+// NotificationSetting holds how a member wants to be notified. Members without settings are
+// notified in German by email, or through the webhook channel if they have no email address.
+type NotificationSetting struct {
+	gorm.Model
+	MemberID uint   `gorm:"not null;uniqueIndex" json:"member_id"`
+	Language string `gorm:"type:varchar(5);not null;default:de" json:"language"`
+	Channel  string `gorm:"type:varchar(20)" json:"channel"` // empty picks the channel by contact data
+	OptOut   bool   `gorm:"not null;default:false" json:"opt_out"`
+}
@@ -5,10 +5,18 @@ import (
 	"time"
 )
 
+// Participation statuses; absent members have no participation record at all
+const (
+	ParticipationStatusPresent = "present"
+	ParticipationStatusExcused = "excused"
+	ParticipationStatusAbsent  = "absent"
+)
+
 // Participation represents a member's attendance at a specific course on a specific date
 type Participation struct {
 	gorm.Model
 	MemberID uint      `gorm:"index" json:"member_id"`
 	CourseID uint      `gorm:"index" json:"course_id"`
 	Date     time.Time `gorm:"index;type:date" json:"date"` // Format: YYYY-MM-DD
+	Status   string    `gorm:"type:varchar(20);not null;default:present" json:"status"`
 }
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// PunchCard represents a multi-visit card (e.g. 10er-Karte) sold to a member or guest for drop-in sessions
+type PunchCard struct {
+	gorm.Model
+	MemberID  uint       `gorm:"index" json:"member_id"`
+	Credits   int        `gorm:"not null" json:"credits"`
+	Remaining int        `gorm:"not null" json:"remaining"`
+	Price     float64    `gorm:"type:numeric(10,2);not null;default:0" json:"price"`
+	SoldAt    time.Time  `gorm:"type:date" json:"sold_at"`
+	ExpiresAt *time.Time `gorm:"type:date" json:"expires_at"` // nil never expires
+	Notes     string     `gorm:"type:text" json:"notes"`
+}
+
+// PunchCardUsage records a visit of a drop-in session paid with a punch card credit
+type PunchCardUsage struct {
+	gorm.Model
+	PunchCardID uint      `gorm:"index" json:"punch_card_id"`
+	MemberID    uint      `gorm:"uniqueIndex:idx_punch_card_usage_session" json:"member_id"`
+	CourseID    uint      `gorm:"uniqueIndex:idx_punch_card_usage_session" json:"course_id"`
+	Date        time.Time `gorm:"type:date;uniqueIndex:idx_punch_card_usage_session" json:"date"`
+}
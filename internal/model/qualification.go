@@ -0,0 +1,31 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Qualification types trainers may need to hold
+const (
+	QualificationLicense        = "uebungsleiter_license" // Übungsleiter-Lizenz
+	QualificationFirstAid       = "first_aid"             // Erste-Hilfe-Kurs
+	QualificationCriminalRecord = "criminal_record"       // erweitertes Führungszeugnis
+)
+
+// Qualification represents a license or certificate held by a trainer; a zero ExpiresAt never expires
+type Qualification struct {
+	gorm.Model
+	TrainerID uint      `gorm:"index" json:"trainer_id"`
+	Type      string    `gorm:"type:varchar(50);index" json:"type"`
+	IssuedAt  time.Time `gorm:"type:date" json:"issued_at"`
+	ExpiresAt time.Time `gorm:"type:date" json:"expires_at"`
+	Notes     string    `gorm:"type:text" json:"notes"`
+}
+
+// QualificationRequirement defines a qualification required to lead courses of a training type.
+// Training types without requirements need no qualifications.
+type QualificationRequirement struct {
+	gorm.Model
+	TrainingType      string `gorm:"type:varchar(100);index" json:"training_type"`
+	QualificationType string `gorm:"type:varchar(50)" json:"qualification_type"`
+}
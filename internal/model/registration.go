@@ -0,0 +1,36 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Registration statuses
+const (
+	RegistrationStatusUnconfirmed = "unconfirmed" // waiting for the double-opt-in confirmation
+	RegistrationStatusPending     = "pending"     // confirmed, waiting for approval by the office
+	RegistrationStatusApproved    = "approved"
+	RegistrationStatusRejected    = "rejected"
+)
+
+// Registration represents a course registration submitted through the public form
+type Registration struct {
+	gorm.Model
+	CourseID          uint       `gorm:"index" json:"course_id"`
+	FirstName         string     `gorm:"type:varchar(100)" json:"first_name"`
+	LastName          string     `gorm:"type:varchar(100)" json:"last_name"`
+	BirthDate         time.Time  `gorm:"type:date" json:"birth_date"`
+	GuardianName      string     `gorm:"type:varchar(200)" json:"guardian_name"`
+	Email             string     `gorm:"type:varchar(255)" json:"email"`
+	Phone             string     `gorm:"type:varchar(50)" json:"phone"`
+	Message           string     `gorm:"type:text" json:"message"`
+	ConsentPrivacy    bool       `json:"consent_privacy"`
+	ConsentPhotos     bool       `json:"consent_photos"`
+	Status            string     `gorm:"type:varchar(20);index;not null" json:"status"`
+	ConfirmationToken string     `gorm:"type:varchar(64);uniqueIndex" json:"-"`
+	ConfirmedAt       *time.Time `json:"confirmed_at"`
+	DecidedAt         *time.Time `json:"decided_at"`
+	MemberID          *uint      `json:"member_id"` // set when the member is created, before the approval completes
+	Waitlisted        bool       `json:"waitlisted"`
+	Notes             string     `gorm:"type:text" json:"notes"`
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Session records what happened at a course on a specific date. It is created when attendance is
+// first recorded or the session is cancelled; empty start and end times mean the session took
+// place as scheduled.
+type Session struct {
+	gorm.Model
+	CourseID        uint       `gorm:"not null;uniqueIndex:idx_session" json:"course_id"`
+	Date            time.Time  `gorm:"type:date;not null;uniqueIndex:idx_session" json:"date"`
+	StartTime       string     `gorm:"type:varchar(10)" json:"start_time"`
+	EndTime         string     `gorm:"type:varchar(10)" json:"end_time"`
+	Notes           string     `gorm:"type:text" json:"notes"`
+	ContentTags     string     `gorm:"type:text" json:"content_tags"` // comma-separated
+	Weather         string     `gorm:"type:varchar(50)" json:"weather"`
+	TrainersPresent int        `gorm:"not null;default:0" json:"trainers_present"`
+	CancelledAt     *time.Time `json:"cancelled_at"`
+	CancelledBy     string     `gorm:"type:varchar(100)" json:"cancelled_by"`
+	CancelReason    string     `gorm:"type:text" json:"cancel_reason"`
+}
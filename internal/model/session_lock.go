@@ -0,0 +1,35 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Unlock request statuses
+const (
+	UnlockRequestPending  = "pending"
+	UnlockRequestApproved = "approved"
+	UnlockRequestRejected = "rejected"
+)
+
+// SessionLock marks a session whose attendance was submitted and must no longer change
+type SessionLock struct {
+	gorm.Model
+	CourseID uint      `gorm:"not null;uniqueIndex:idx_session_lock" json:"course_id"`
+	Date     time.Time `gorm:"type:date;not null;uniqueIndex:idx_session_lock" json:"date"`
+	LockedBy string    `gorm:"type:varchar(100)" json:"locked_by"`
+}
+
+// UnlockRequest asks an administrator to reopen a locked session for a correction. Once approved,
+// the session can be edited until UnlockedUntil.
+type UnlockRequest struct {
+	gorm.Model
+	CourseID      uint       `gorm:"not null;index:idx_unlock_request_session" json:"course_id"`
+	Date          time.Time  `gorm:"type:date;not null;index:idx_unlock_request_session" json:"date"`
+	RequestedBy   string     `gorm:"type:varchar(100)" json:"requested_by"`
+	Reason        string     `gorm:"type:text" json:"reason"`
+	Status        string     `gorm:"type:varchar(20);index;not null" json:"status"`
+	DecidedBy     string     `gorm:"type:varchar(100)" json:"decided_by"`
+	DecidedAt     *time.Time `json:"decided_at"`
+	UnlockedUntil *time.Time `json:"unlocked_until"`
+}
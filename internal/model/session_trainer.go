@@ -0,0 +1,17 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// SessionTrainer records a trainer assigned to a course on a specific date, optionally as
+// substitute for one of the course's regular trainers. Without any records for a session,
+// the course's trainers are assumed.
+type SessionTrainer struct {
+	gorm.Model
+	CourseID        uint      `gorm:"index" json:"course_id"`
+	Date            time.Time `gorm:"index;type:date" json:"date"`
+	TrainerID       uint      `gorm:"index" json:"trainer_id"`
+	SubstituteForID *uint     `json:"substitute_for_id"`
+}
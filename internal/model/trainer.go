@@ -0,0 +1,31 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Trainer represents a trainer, normalized from the comma-separated trainer list of the courses.
+// The email address is maintained by administrators, as the import only knows the names.
+type Trainer struct {
+	gorm.Model
+	Name  string `gorm:"type:varchar(255);uniqueIndex" json:"name"`
+	Email string `gorm:"type:varchar(255)" json:"email"`
+}
+
+// CourseTrainer represents the n:m relationship between courses and their regular trainers
+type CourseTrainer struct {
+	gorm.Model
+	CourseID  uint `gorm:"index" json:"course_id"`
+	TrainerID uint `gorm:"index" json:"trainer_id"`
+}
+
+// TrainerUnavailability records that a trainer cannot lead sessions on a date, either for a
+// specific course or, without CourseID, for all courses
+type TrainerUnavailability struct {
+	gorm.Model
+	TrainerID uint      `gorm:"index" json:"trainer_id"`
+	CourseID  *uint     `gorm:"index" json:"course_id"`
+	Date      time.Time `gorm:"index;type:date" json:"date"`
+	Reason    string    `gorm:"type:text" json:"reason"`
+}
@@ -0,0 +1,15 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// WaitlistEntry represents a member waiting for a free spot in a full course
+type WaitlistEntry struct {
+	gorm.Model
+	CourseID   uint       `gorm:"index" json:"course_id"`
+	MemberID   uint       `gorm:"index" json:"member_id"`
+	Position   int        `gorm:"not null" json:"position"`
+	PromotedAt *time.Time `json:"promoted_at"`
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Webhook events; the test event is only sent through the test endpoint
+const (
+	WebhookEventMemberCreated     = "member.created"
+	WebhookEventMemberCancelled   = "member.cancelled"
+	WebhookEventEnrollmentChanged = "enrollment.changed"
+	WebhookEventAttendance        = "attendance.recorded"
+	WebhookEventImportCompleted   = "import.completed"
+	WebhookEventTest              = "webhook.test"
+)
+
+// WebhookEvents lists the events webhooks can subscribe to
+var WebhookEvents = []string{
+	WebhookEventMemberCreated, WebhookEventMemberCancelled, WebhookEventEnrollmentChanged,
+	WebhookEventAttendance, WebhookEventImportCompleted,
+}
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed" // given up after the maximum number of attempts
+)
+
+// Webhook posts the events it subscribes to as signed JSON to an external system, e.g. the
+// accounting or newsletter software of the club
+type Webhook struct {
+	gorm.Model
+	Name   string `gorm:"type:varchar(100);not null" json:"name"`
+	URL    string `gorm:"type:varchar(500);not null" json:"url"`
+	Secret string `gorm:"type:varchar(64);not null" json:"-"`
+	Events string `gorm:"type:text" json:"events"` // comma-separated
+	Active bool   `gorm:"not null;default:true" json:"active"`
+}
+
+// WebhookDelivery is an event queued for or sent to a webhook. Pending deliveries are retried with
+// increasing delay until the receiver accepts them or the attempts run out; the records form the
+// delivery log.
+type WebhookDelivery struct {
+	gorm.Model
+	WebhookID      uint       `gorm:"not null;index" json:"webhook_id"`
+	Event          string     `gorm:"type:varchar(50);not null" json:"event"`
+	EventID        string     `gorm:"type:varchar(64);index" json:"event_id"` // the same for all webhooks receiving an event
+	Payload        string     `gorm:"type:text" json:"payload"`
+	Status         string     `gorm:"type:varchar(20);not null;index:idx_webhook_delivery_due" json:"status"`
+	Attempts       int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time  `gorm:"index:idx_webhook_delivery_due" json:"next_attempt_at"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	ResponseStatus int        `json:"response_status"`
+	ResponseBody   string     `gorm:"type:text" json:"response_body"`
+	LastError      string     `gorm:"type:text" json:"last_error"`
+}
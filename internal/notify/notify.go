@@ -0,0 +1,118 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"azh/internal/mail"
+)
+
+// Channel names
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+	ChannelFile    = "file"
+)
+
+// Message is a rendered notification addressed to a single recipient
+type Message struct {
+	To      string `json:"to"` // email address or phone number, depending on the channel
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Channel delivers messages over one medium. A failed delivery returns an error and is retried
+// later by the outbox, so Send must not retry on its own.
+type Channel interface {
+	// Name returns the channel name stored with queued notifications
+	Name() string
+	// Send delivers a message
+	Send(msg Message) error
+}
+
+// EmailChannel delivers messages by email
+type EmailChannel struct {
+	mailer *mail.Mailer
+}
+
+// NewEmailChannel creates a new EmailChannel
+func NewEmailChannel(mailer *mail.Mailer) *EmailChannel {
+	return &EmailChannel{mailer: mailer}
+}
+
+// Name returns the channel name
+func (c *EmailChannel) Name() string {
+	return ChannelEmail
+}
+
+// Send delivers a message by email
+func (c *EmailChannel) Send(msg Message) error {
+	return c.mailer.Send([]string{msg.To}, msg.Subject, msg.Body)
+}
+
+// WebhookChannel posts messages as JSON to an HTTP endpoint, e.g. a gateway to a messenger or SMS
+// service that delivers them to the recipient's phone number
+type WebhookChannel struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookChannel creates a new WebhookChannel posting to the given URL
+func NewWebhookChannel(url string) *WebhookChannel {
+	return &WebhookChannel{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Name returns the channel name
+func (c *WebhookChannel) Name() string {
+	return ChannelWebhook
+}
+
+// Send posts a message to the webhook; any status other than 2xx counts as failure
+func (c *WebhookChannel) Send(msg Message) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Post(c.url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// FileChannel writes messages as text files into a directory instead of delivering them, for
+// testing templates and the outbox without sending anything
+type FileChannel struct {
+	dir string
+	seq atomic.Uint64
+}
+
+// NewFileChannel creates a new FileChannel writing into dir, which is created if missing
+func NewFileChannel(dir string) (*FileChannel, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileChannel{dir: dir}, nil
+}
+
+// Name returns the channel name
+func (c *FileChannel) Name() string {
+	return ChannelFile
+}
+
+// Send writes a message into a new file named after the current time
+func (c *FileChannel) Send(msg Message) error {
+	name := fmt.Sprintf("%s-%04d.txt", time.Now().Format("20060102-150405"), c.seq.Add(1))
+	content := fmt.Sprintf("To: %s\nSubject: %s\n\n%s\n", msg.To, msg.Subject, msg.Body)
+	return os.WriteFile(filepath.Join(c.dir, name), []byte(content), 0o644)
+}
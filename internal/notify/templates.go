@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"embed"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Languages of the notification templates
+const (
+	LanguageGerman  = "de"
+	LanguageEnglish = "en"
+)
+
+//go:embed templates/*.tmpl
+var templateFiles embed.FS
+
+// Templates renders notification messages from the templates in the templates directory. Each
+// template file is named <name>.<language>.tmpl and defines a "subject" and a "body" template.
+type Templates struct {
+	templates map[string]*template.Template
+}
+
+// LoadTemplates parses the embedded notification templates
+func LoadTemplates() (*Templates, error) {
+	files, err := templateFiles.ReadDir("templates")
+	if err != nil {
+		return nil, err
+	}
+	t := &Templates{templates: make(map[string]*template.Template)}
+	for _, file := range files {
+		key := strings.TrimSuffix(file.Name(), ".tmpl")
+		parsed, err := template.ParseFS(templateFiles, "templates/"+file.Name())
+		if err != nil {
+			return nil, fmt.Errorf("error parsing template %s: %v", file.Name(), err)
+		}
+		for _, part := range []string{"subject", "body"} {
+			if parsed.Lookup(part) == nil {
+				return nil, fmt.Errorf("template %s does not define %q", file.Name(), part)
+			}
+		}
+		t.templates[key] = parsed
+	}
+	return t, nil
+}
+
+// Has reports whether a template exists in German, the fallback language
+func (t *Templates) Has(name string) bool {
+	_, ok := t.templates[name+"."+LanguageGerman]
+	return ok
+}
+
+// Render renders a template in the given language, falling back to German
+func (t *Templates) Render(name, language string, data interface{}) (string, string, error) {
+	parsed, ok := t.templates[name+"."+language]
+	if !ok {
+		parsed, ok = t.templates[name+"."+LanguageGerman]
+	}
+	if !ok {
+		return "", "", fmt.Errorf("unknown template %s", name)
+	}
+	var subject, body strings.Builder
+	if err := parsed.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return "", "", err
+	}
+	if err := parsed.ExecuteTemplate(&body, "body", data); err != nil {
+		return "", "", err
+	}
+	return strings.TrimSpace(subject.String()), strings.TrimSpace(body.String()) + "\n", nil
+}
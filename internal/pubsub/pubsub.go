@@ -0,0 +1,65 @@
+package pubsub
+
+import "sync"
+
+// subscriberBuffer is the number of messages buffered per subscriber before further messages are dropped
+const subscriberBuffer = 16
+
+// Broker publishes messages to all subscribers of a topic. The in-process MemoryBroker serves a
+// single instance; running several instances requires a broker shared between them, for example
+// one backed by Postgres LISTEN/NOTIFY.
+type Broker interface {
+	// Publish sends a message to the current subscribers of a topic
+	Publish(topic string, message []byte) error
+	// Subscribe returns a channel receiving the messages of a topic and a function ending the subscription
+	Subscribe(topic string) (<-chan []byte, func())
+}
+
+// MemoryBroker is an in-process Broker
+type MemoryBroker struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan []byte]struct{}
+}
+
+// NewMemoryBroker creates a new MemoryBroker
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{subscribers: make(map[string]map[chan []byte]struct{})}
+}
+
+// Publish sends a message to the current subscribers of a topic. Subscribers that do not keep up
+// miss the message instead of blocking the publisher.
+func (b *MemoryBroker) Publish(topic string, message []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers[topic] {
+		select {
+		case ch <- message:
+		default:
+		}
+	}
+	return nil
+}
+
+// Subscribe returns a channel receiving the messages of a topic and a function ending the subscription
+func (b *MemoryBroker) Subscribe(topic string) (<-chan []byte, func()) {
+	ch := make(chan []byte, subscriberBuffer)
+	b.mu.Lock()
+	if b.subscribers[topic] == nil {
+		b.subscribers[topic] = make(map[chan []byte]struct{})
+	}
+	b.subscribers[topic][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.subscribers[topic], ch)
+			if len(b.subscribers[topic]) == 0 {
+				delete(b.subscribers, topic)
+			}
+			close(ch)
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"azh/internal/model"
+	"gorm.io/gorm"
+	"time"
+)
+
+// AttendanceChangeRepository handles database operations for the attendance change journal
+type AttendanceChangeRepository struct {
+	db *gorm.DB
+}
+
+// NewAttendanceChangeRepository creates a new AttendanceChangeRepository
+func NewAttendanceChangeRepository(db *gorm.DB) *AttendanceChangeRepository {
+	return &AttendanceChangeRepository{db: db}
+}
+
+// Create stores a journal entry
+func (r *AttendanceChangeRepository) Create(change *model.AttendanceChange) error {
+	return r.db.Create(change).Error
+}
+
+// GetByClientChange retrieves the journal entries of changes already synced by a client
+func (r *AttendanceChangeRepository) GetByClientChange(clientID string, changeIDs []string) ([]model.AttendanceChange, error) {
+	var changes []model.AttendanceChange
+	err := r.db.Where("client_id = ? AND change_id IN ?", clientID, changeIDs).Find(&changes).Error
+	return changes, err
+}
+
+// GetLatestApplied retrieves the most recent applied change of a member's attendance at a session
+func (r *AttendanceChangeRepository) GetLatestApplied(memberID, courseID uint, date time.Time) (model.AttendanceChange, error) {
+	var change model.AttendanceChange
+	err := r.db.Where("member_id = ? AND course_id = ? AND date = ? AND outcome = ?", memberID, courseID, date, model.AttendanceChangeApplied).
+		Order("changed_at DESC, id DESC").
+		First(&change).Error
+	return change, err
+}
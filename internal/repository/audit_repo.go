@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"azh/internal/model"
+	"gorm.io/gorm"
+	"time"
+)
+
+// AuditFilter restricts the audit entries returned by AuditRepository.Find; zero values match everything
+type AuditFilter struct {
+	Entity   string
+	EntityID string
+	Actor    string
+	From     time.Time
+	To       time.Time
+	Limit    int
+}
+
+// AuditRepository handles database operations for the audit log
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository creates a new AuditRepository
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Create appends entries to the audit log
+func (r *AuditRepository) Create(entries []model.AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return createAuditEntries(r.db, entries)
+}
+
+// Find retrieves the audit entries matching a filter, newest first
+func (r *AuditRepository) Find(filter AuditFilter) ([]model.AuditEntry, error) {
+	query := r.db.Model(&model.AuditEntry{})
+	if filter.Entity != "" {
+		query = query.Where("entity = ?", filter.Entity)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	var entries []model.AuditEntry
+	err := query.Order("created_at DESC, id DESC").Find(&entries).Error
+	return entries, err
+}
+
+// createAuditEntries appends entries to the audit log using the given connection or transaction
+func createAuditEntries(db *gorm.DB, entries []model.AuditEntry) error {
+	return db.CreateInBatches(entries, 500).Error
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"azh/internal/model"
+	"gorm.io/gorm"
+)
+
+// CalendarFeedRepository handles database operations for calendar feeds
+type CalendarFeedRepository struct {
+	db *gorm.DB
+}
+
+// NewCalendarFeedRepository creates a new CalendarFeedRepository
+func NewCalendarFeedRepository(db *gorm.DB) *CalendarFeedRepository {
+	return &CalendarFeedRepository{db: db}
+}
+
+// Get retrieves the feed of a course, a trainer or, with subject 0, the club
+func (r *CalendarFeedRepository) Get(kind string, subjectID uint) (model.CalendarFeed, error) {
+	var feed model.CalendarFeed
+	err := r.db.Where("kind = ? AND subject_id = ?", kind, subjectID).First(&feed).Error
+	return feed, err
+}
+
+// GetByToken retrieves a feed by its secret token
+func (r *CalendarFeedRepository) GetByToken(token string) (model.CalendarFeed, error) {
+	var feed model.CalendarFeed
+	err := r.db.Where("token = ?", token).First(&feed).Error
+	return feed, err
+}
+
+// Save creates or updates a feed
+func (r *CalendarFeedRepository) Save(feed *model.CalendarFeed) error {
+	return r.db.Save(feed).Error
+}
@@ -23,7 +23,7 @@ func (r *CourseRepository) GetAll() ([]model.Course, error) {
 	eightDaysAgo := today.AddDate(0, 0, -8)
 	eightDaysAhead := today.AddDate(0, 0, 8)
 
-	err := r.db.Where("((first_schedule IS NULL OR first_schedule <= ?) AND (last_schedule IS NULL OR last_schedule >= ?))", eightDaysAhead, eightDaysAgo).
+	err := r.withEventDates().Where("((first_schedule IS NULL OR first_schedule <= ?) AND (last_schedule IS NULL OR last_schedule >= ?))", eightDaysAhead, eightDaysAgo).
 		Order("id ASC, start_time ASC").
 		Find(&courses).Error
 	return courses, err
@@ -32,6 +32,85 @@ func (r *CourseRepository) GetAll() ([]model.Course, error) {
 // GetByID retrieves a course by ID
 func (r *CourseRepository) GetByID(id string) (model.Course, error) {
 	var course model.Course
-	err := r.db.Where("id = ?", id).First(&course).Error
+	err := r.withEventDates().Where("id = ?", id).First(&course).Error
 	return course, err
 }
+
+// GetByIDs retrieves courses by their IDs
+func (r *CourseRepository) GetByIDs(ids []uint) ([]model.Course, error) {
+	var courses []model.Course
+	err := r.withEventDates().Where("id IN ?", ids).Order("id ASC").Find(&courses).Error
+	return courses, err
+}
+
+// GetActiveBetween retrieves all courses scheduled at some point within a date range
+func (r *CourseRepository) GetActiveBetween(minDate, maxDate string) ([]model.Course, error) {
+	var courses []model.Course
+	err := r.withEventDates().Where("((first_schedule IS NULL OR first_schedule <= ?) AND (last_schedule IS NULL OR last_schedule >= ?))", maxDate, minDate).
+		Order("id ASC, start_time ASC").
+		Find(&courses).Error
+	return courses, err
+}
+
+// GetAllUnfiltered retrieves all courses regardless of their schedule
+func (r *CourseRepository) GetAllUnfiltered() ([]model.Course, error) {
+	var courses []model.Course
+	err := r.withEventDates().Order("id ASC").Find(&courses).Error
+	return courses, err
+}
+
+// SetMaxParticipants updates the capacity of a course
+func (r *CourseRepository) SetMaxParticipants(id uint, maxParticipants int) error {
+	return r.db.Model(&model.Course{}).Where("id = ?", id).Update("max_participants", maxParticipants).Error
+}
+
+// CreateEvent stores a new event with its dates under the next free event ID
+func (r *CourseRepository) CreateEvent(event *model.Course) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Serialize concurrent event creation so two events never get the same ID
+		if err := tx.Exec("LOCK TABLE courses IN SHARE ROW EXCLUSIVE MODE").Error; err != nil {
+			return err
+		}
+		var maxID uint
+		if err := tx.Unscoped().Model(&model.Course{}).
+			Where("id >= ?", model.EventIDOffset).
+			Select("COALESCE(MAX(id), 0)").
+			Scan(&maxID).Error; err != nil {
+			return err
+		}
+		event.ID = max(maxID+1, model.EventIDOffset)
+		return tx.Create(event).Error
+	})
+}
+
+// UpdateEvent updates an event and replaces its dates
+func (r *CourseRepository) UpdateEvent(event *model.Course) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("course_id = ?", event.ID).Delete(&model.EventDate{}).Error; err != nil {
+			return err
+		}
+		for i := range event.EventDates {
+			event.EventDates[i].ID = 0
+			event.EventDates[i].CourseID = event.ID
+		}
+		return tx.Save(event).Error
+	})
+}
+
+// withEventDates preloads the dates of events in date order
+func (r *CourseRepository) withEventDates() *gorm.DB {
+	return r.db.Preload("EventDates", func(db *gorm.DB) *gorm.DB {
+		return db.Order("date ASC, start_time ASC")
+	})
+}
+
+// SetDropIn updates whether a course is a drop-in session paid with punch cards
+func (r *CourseRepository) SetDropIn(id uint, dropIn bool) error {
+	return r.db.Model(&model.Course{}).Where("id = ?", id).Update("drop_in", dropIn).Error
+}
+
+// SetLocation updates the location and room a course takes place in
+func (r *CourseRepository) SetLocation(id uint, locationID, roomID *uint) error {
+	return r.db.Model(&model.Course{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"location_id": locationID, "room_id": roomID}).Error
+}
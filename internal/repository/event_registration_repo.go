@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"azh/internal/model"
+	"gorm.io/gorm"
+	"time"
+)
+
+// EventRegistrationRepository handles database operations for event registrations
+type EventRegistrationRepository struct {
+	db *gorm.DB
+}
+
+// NewEventRegistrationRepository creates a new EventRegistrationRepository
+func NewEventRegistrationRepository(db *gorm.DB) *EventRegistrationRepository {
+	return &EventRegistrationRepository{db: db}
+}
+
+// GetByCourseID retrieves the registrations of an event in registration order
+func (r *EventRegistrationRepository) GetByCourseID(courseID uint) ([]model.EventRegistration, error) {
+	var registrations []model.EventRegistration
+	err := r.db.Where("course_id = ?", courseID).Order("created_at ASC").Find(&registrations).Error
+	return registrations, err
+}
+
+// Create registers a member for an event and enrolls them for attendance recording, if the event
+// still has room on the reference date; it returns whether the member was registered. A guest, if
+// given, is stored as a new member in the same transaction and registered instead of the member of
+// the registration, so no guest is left behind when the registration fails.
+func (r *EventRegistrationRepository) Create(registration *model.EventRegistration, guest *model.Member, referenceDate time.Time) (bool, error) {
+	registered := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		free, err := lockCourseHasRoom(tx, registration.CourseID, referenceDate)
+		if err != nil || !free {
+			return err
+		}
+		if guest != nil {
+			guest.Guest = true
+			if err := createMemberWithNextID(tx, guest, model.GuestIDOffset, 0); err != nil {
+				return err
+			}
+			registration.MemberID = guest.ID
+		}
+		enrollment := model.MemberCourse{MemberID: registration.MemberID, CourseID: registration.CourseID, Manual: true}
+		if err := tx.Create(&enrollment).Error; err != nil {
+			return err
+		}
+		registered = true
+		return tx.Create(registration).Error
+	})
+	return registered && err == nil, err
+}
+
+// Delete removes the registration and enrollment of a member for an event and returns the number
+// of registrations deleted
+func (r *EventRegistrationRepository) Delete(courseID, memberID uint) (int64, error) {
+	var deleted int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("course_id = ? AND member_id = ?", courseID, memberID).
+			Delete(&model.MemberCourse{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("course_id = ? AND member_id = ?", courseID, memberID).
+			Delete(&model.EventRegistration{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted, err
+}
+
+// SetPaidAt records or clears the payment of an event's fee
+func (r *EventRegistrationRepository) SetPaidAt(courseID, memberID uint, paidAt *time.Time) (int64, error) {
+	result := r.db.Model(&model.EventRegistration{}).
+		Where("course_id = ? AND member_id = ?", courseID, memberID).
+		Update("paid_at", paidAt)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"azh/internal/model"
+	"gorm.io/gorm"
+	"time"
+)
+
+// GuardianRepository handles database operations for guardians, their children and the absences
+// they report
+type GuardianRepository struct {
+	db *gorm.DB
+}
+
+// NewGuardianRepository creates a new GuardianRepository
+func NewGuardianRepository(db *gorm.DB) *GuardianRepository {
+	return &GuardianRepository{db: db}
+}
+
+// GetAll retrieves all guardians with their children
+func (r *GuardianRepository) GetAll() ([]model.Guardian, error) {
+	var guardians []model.Guardian
+	err := r.db.Preload("Children").Order("name ASC").Find(&guardians).Error
+	return guardians, err
+}
+
+// GetByID retrieves a guardian by ID with their children
+func (r *GuardianRepository) GetByID(id uint) (model.Guardian, error) {
+	var guardian model.Guardian
+	err := r.db.Preload("Children").Where("id = ?", id).First(&guardian).Error
+	return guardian, err
+}
+
+// GetByToken retrieves a guardian by the secret token of their link
+func (r *GuardianRepository) GetByToken(token string) (model.Guardian, error) {
+	var guardian model.Guardian
+	err := r.db.Preload("Children").Where("token = ?", token).First(&guardian).Error
+	return guardian, err
+}
+
+// FindByEmail retrieves a guardian by email address, ignoring case
+func (r *GuardianRepository) FindByEmail(email string) (model.Guardian, error) {
+	var guardian model.Guardian
+	err := r.db.Preload("Children").Where("LOWER(email) = LOWER(?)", email).First(&guardian).Error
+	return guardian, err
+}
+
+// Save creates or updates a guardian without touching their children
+func (r *GuardianRepository) Save(guardian *model.Guardian) error {
+	return r.db.Omit("Children").Save(guardian).Error
+}
+
+// SetChildren replaces the children of a guardian
+func (r *GuardianRepository) SetChildren(guardianID uint, memberIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("guardian_id = ?", guardianID).Delete(&model.GuardianChild{}).Error; err != nil {
+			return err
+		}
+		for _, memberID := range memberIDs {
+			if err := tx.Create(&model.GuardianChild{GuardianID: guardianID, MemberID: memberID}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// AddChild links a member to a guardian unless they are already linked
+func (r *GuardianRepository) AddChild(guardianID, memberID uint) error {
+	child := model.GuardianChild{GuardianID: guardianID, MemberID: memberID}
+	return r.db.Where(&child).FirstOrCreate(&child).Error
+}
+
+// GetAbsences retrieves the absences of members within [minDate, maxDate]
+func (r *GuardianRepository) GetAbsences(memberIDs []uint, minDate, maxDate string) ([]model.Absence, error) {
+	var absences []model.Absence
+	err := r.db.Where("member_id IN ? AND date BETWEEN ? AND ?", memberIDs, minDate, maxDate).Find(&absences).Error
+	return absences, err
+}
+
+// GetAbsencesBySession retrieves the absences reported for a session
+func (r *GuardianRepository) GetAbsencesBySession(courseID string, date string) ([]model.Absence, error) {
+	var absences []model.Absence
+	err := r.db.Where("course_id = ? AND date = ?", courseID, date).Find(&absences).Error
+	return absences, err
+}
+
+// GetAbsence retrieves the absence of a member from a session
+func (r *GuardianRepository) GetAbsence(memberID, courseID uint, date time.Time) (model.Absence, error) {
+	var absence model.Absence
+	err := r.db.Where("member_id = ? AND course_id = ? AND date = ?", memberID, courseID, date.Format("2006-01-02")).
+		First(&absence).Error
+	return absence, err
+}
+
+// SaveAbsenceWithChanges creates or updates an absence together with the attendance changes
+// excusing the member in one transaction, see ParticipationRepository.ApplyChanges
+func (r *GuardianRepository) SaveAbsenceWithChanges(absence *model.Absence, changes []model.AttendanceChange, dropIn map[uint]bool, auditEntries []model.AuditEntry) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := applyChanges(tx, changes, dropIn, auditEntries); err != nil {
+			return err
+		}
+		return tx.Save(absence).Error
+	})
+}
+
+// DeleteAbsence removes an absence, so it can be reported again
+func (r *GuardianRepository) DeleteAbsence(id uint) error {
+	return r.db.Unscoped().Delete(&model.Absence{}, id).Error
+}
+
+// DeleteAbsenceWithChanges removes an absence together with the attendance changes resetting the
+// member's attendance in one transaction, see ParticipationRepository.ApplyChanges
+func (r *GuardianRepository) DeleteAbsenceWithChanges(id uint, changes []model.AttendanceChange, dropIn map[uint]bool, auditEntries []model.AuditEntry) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := applyChanges(tx, changes, dropIn, auditEntries); err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&model.Absence{}, id).Error
+	})
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"azh/internal/model"
+	"gorm.io/gorm"
+)
+
+// IncidentRepository handles database operations for incidents
+type IncidentRepository struct {
+	db *gorm.DB
+}
+
+// NewIncidentRepository creates a new IncidentRepository
+func NewIncidentRepository(db *gorm.DB) *IncidentRepository {
+	return &IncidentRepository{db: db}
+}
+
+// Create stores a new incident together with the session it happened at
+func (r *IncidentRepository) Create(incident *model.Incident) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureSession(tx, incident.CourseID, incident.Date); err != nil {
+			return err
+		}
+		return tx.Create(incident).Error
+	})
+}
+
+// Save updates an incident
+func (r *IncidentRepository) Save(incident *model.Incident) error {
+	return r.db.Save(incident).Error
+}
+
+// GetByID retrieves an incident by ID
+func (r *IncidentRepository) GetByID(id uint) (model.Incident, error) {
+	var incident model.Incident
+	err := r.db.First(&incident, id).Error
+	return incident, err
+}
+
+// GetByStatus retrieves incidents with the given status, newest first; an empty status returns all
+func (r *IncidentRepository) GetByStatus(status string) ([]model.Incident, error) {
+	var incidents []model.Incident
+	query := r.db.Order("occurred_at DESC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Find(&incidents).Error
+	return incidents, err
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"azh/internal/model"
+	"gorm.io/gorm"
+)
+
+// LocationRepository handles database operations for locations, their rooms and aliases
+type LocationRepository struct {
+	db *gorm.DB
+}
+
+// NewLocationRepository creates a new LocationRepository
+func NewLocationRepository(db *gorm.DB) *LocationRepository {
+	return &LocationRepository{db: db}
+}
+
+// GetAll retrieves all locations with their rooms, opening hours and aliases
+func (r *LocationRepository) GetAll() ([]model.Location, error) {
+	var locations []model.Location
+	err := r.withDetails().Order("name ASC").Find(&locations).Error
+	return locations, err
+}
+
+// GetByID retrieves a location by ID with its rooms, opening hours and aliases
+func (r *LocationRepository) GetByID(id uint) (model.Location, error) {
+	var location model.Location
+	err := r.withDetails().Where("id = ?", id).First(&location).Error
+	return location, err
+}
+
+// FindByName retrieves a location by its name, ignoring case
+func (r *LocationRepository) FindByName(name string) (model.Location, error) {
+	var location model.Location
+	err := r.db.Where("LOWER(name) = LOWER(?)", name).First(&location).Error
+	return location, err
+}
+
+// Create stores a new location with its opening hours
+func (r *LocationRepository) Create(location *model.Location) error {
+	return r.db.Omit("Rooms", "Aliases").Create(location).Error
+}
+
+// Update updates a location and replaces its opening hours
+func (r *LocationRepository) Update(location *model.Location) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("location_id = ?", location.ID).Delete(&model.OpeningHours{}).Error; err != nil {
+			return err
+		}
+		for i := range location.OpeningHours {
+			location.OpeningHours[i].ID = 0
+			location.OpeningHours[i].LocationID = location.ID
+		}
+		return tx.Omit("Rooms", "Aliases").Save(location).Error
+	})
+}
+
+// CreateRoom stores a new room
+func (r *LocationRepository) CreateRoom(room *model.Room) error {
+	return r.db.Create(room).Error
+}
+
+// GetRoomsByIDs retrieves rooms by their IDs
+func (r *LocationRepository) GetRoomsByIDs(ids []uint) ([]model.Room, error) {
+	var rooms []model.Room
+	err := r.db.Where("id IN ?", ids).Order("id ASC").Find(&rooms).Error
+	return rooms, err
+}
+
+// FindAlias retrieves the alias matching a normalized free-text location
+func (r *LocationRepository) FindAlias(alias string) (model.LocationAlias, error) {
+	var locationAlias model.LocationAlias
+	err := r.db.Where("alias = ?", alias).First(&locationAlias).Error
+	return locationAlias, err
+}
+
+// CreateAlias stores a new alias
+func (r *LocationRepository) CreateAlias(alias *model.LocationAlias) error {
+	return r.db.Create(alias).Error
+}
+
+// DeleteAlias removes an alias of a location, so its text can be mapped anew
+func (r *LocationRepository) DeleteAlias(locationID, aliasID uint) (model.LocationAlias, error) {
+	var alias model.LocationAlias
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND location_id = ?", aliasID, locationID).First(&alias).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&alias).Error
+	})
+	return alias, err
+}
+
+// withDetails preloads the rooms, opening hours and aliases of locations
+func (r *LocationRepository) withDetails() *gorm.DB {
+	return r.db.
+		Preload("Rooms", func(db *gorm.DB) *gorm.DB { return db.Order("name ASC") }).
+		Preload("OpeningHours", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Aliases", func(db *gorm.DB) *gorm.DB { return db.Order("alias ASC") })
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"azh/internal/model"
 	"gorm.io/gorm"
+	"time"
 )
 
 // MemberCourseRepository handles database operations for member-course relationships
@@ -28,3 +29,66 @@ func (r *MemberCourseRepository) GetMembersByCourseID(courseID string) ([]uint,
 	}
 	return memberIDs, nil
 }
+
+// GetAll retrieves all member-course relationships
+func (r *MemberCourseRepository) GetAll() ([]model.MemberCourse, error) {
+	var memberCourses []model.MemberCourse
+	err := r.db.Order("course_id ASC, member_id ASC").Find(&memberCourses).Error
+	return memberCourses, err
+}
+
+// GetByMemberIDs retrieves the enrollments of the given members
+func (r *MemberCourseRepository) GetByMemberIDs(memberIDs []uint) ([]model.MemberCourse, error) {
+	var memberCourses []model.MemberCourse
+	err := r.db.Where("member_id IN ?", memberIDs).Order("member_id ASC, course_id ASC").Find(&memberCourses).Error
+	return memberCourses, err
+}
+
+// Exists reports whether a member is enrolled in a course
+func (r *MemberCourseRepository) Exists(memberID, courseID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.MemberCourse{}).
+		Where("member_id = ? AND course_id = ?", memberID, courseID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Create stores a new enrollment
+func (r *MemberCourseRepository) Create(memberCourse *model.MemberCourse) error {
+	return r.db.Create(memberCourse).Error
+}
+
+// Delete removes the enrollment of a member in a course
+func (r *MemberCourseRepository) Delete(memberID, courseID uint) error {
+	return r.db.Unscoped().
+		Where("member_id = ? AND course_id = ?", memberID, courseID).
+		Delete(&model.MemberCourse{}).Error
+}
+
+// CountActive counts per course the enrolled members whose membership is not cancelled on the given date
+func (r *MemberCourseRepository) CountActive(date time.Time) (map[uint]int, error) {
+	var rows []struct {
+		CourseID uint
+		Count    int
+	}
+	err := activeEnrollments(r.db, date).
+		Select("mc.course_id, COUNT(DISTINCT mc.member_id) AS count").
+		Group("mc.course_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		counts[row.CourseID] = row.Count
+	}
+	return counts, nil
+}
+
+// activeEnrollments selects the enrollments of members whose membership was not cancelled before the date
+func activeEnrollments(db *gorm.DB, date time.Time) *gorm.DB {
+	return db.Table("member_courses mc").
+		Joins("JOIN members m ON m.id = mc.member_id AND m.deleted_at IS NULL").
+		Where("mc.deleted_at IS NULL").
+		Where("(m.cancellation_date IS NULL OR m.cancellation_date >= ?)", date)
+}
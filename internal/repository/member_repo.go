@@ -35,3 +35,42 @@ func (r *MemberRepository) GetByIDsAndDate(ids []uint, selectedDate time.Time) (
 		Find(&members).Error
 	return members, err
 }
+
+// CreateRegistered stores a member registered online under the next free ID of the range reserved
+// for them, apart from the member numbers of the imports
+func (r *MemberRepository) CreateRegistered(member *model.Member) error {
+	return r.createWithNextID(member, model.RegisteredIDOffset, model.GuestIDOffset)
+}
+
+// CreateGuest stores a new guest under the next free guest ID
+func (r *MemberRepository) CreateGuest(member *model.Member) error {
+	member.Guest = true
+	return r.createWithNextID(member, model.GuestIDOffset, 0)
+}
+
+// createWithNextID stores a member under the next free ID of the range [first, limit); a zero
+// limit leaves the range open
+func (r *MemberRepository) createWithNextID(member *model.Member, first, limit uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return createMemberWithNextID(tx, member, first, limit)
+	})
+}
+
+// createMemberWithNextID stores a member under the next free ID of the range [first, limit) within
+// a transaction
+func createMemberWithNextID(tx *gorm.DB, member *model.Member, first, limit uint) error {
+	// Serialize concurrent registrations so two members never get the same number
+	if err := tx.Exec("LOCK TABLE members IN SHARE ROW EXCLUSIVE MODE").Error; err != nil {
+		return err
+	}
+	query := tx.Unscoped().Model(&model.Member{}).Where("id >= ?", first)
+	if limit > 0 {
+		query = query.Where("id < ?", limit)
+	}
+	var maxID uint
+	if err := query.Select("COALESCE(MAX(id), 0)").Scan(&maxID).Error; err != nil {
+		return err
+	}
+	member.ID = max(maxID+1, first)
+	return tx.Create(member).Error
+}
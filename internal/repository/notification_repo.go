@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"azh/internal/model"
+	"gorm.io/gorm"
+	"time"
+)
+
+// NotificationRepository handles database operations for the notification outbox and settings
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository creates a new NotificationRepository
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// CreateAll stores new notifications
+func (r *NotificationRepository) CreateAll(notifications []model.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return r.db.Create(&notifications).Error
+}
+
+// Save updates a notification
+func (r *NotificationRepository) Save(notification *model.Notification) error {
+	return r.db.Save(notification).Error
+}
+
+// GetByID retrieves a notification by ID
+func (r *NotificationRepository) GetByID(id uint) (model.Notification, error) {
+	var notification model.Notification
+	err := r.db.First(&notification, id).Error
+	return notification, err
+}
+
+// GetDue retrieves pending notifications whose next attempt is due, oldest first
+func (r *NotificationRepository) GetDue(now time.Time, limit int) ([]model.Notification, error) {
+	var notifications []model.Notification
+	err := r.db.Where("status = ? AND next_attempt_at <= ?", model.NotificationPending, now).
+		Order("next_attempt_at ASC, id ASC").
+		Limit(limit).
+		Find(&notifications).Error
+	return notifications, err
+}
+
+// Find retrieves notifications by reference and status, newest first; empty filters match all
+func (r *NotificationRepository) Find(reference, status string, limit int) ([]model.Notification, error) {
+	var notifications []model.Notification
+	query := r.db.Order("id DESC").Limit(limit)
+	if reference != "" {
+		query = query.Where("reference = ?", reference)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Find(&notifications).Error
+	return notifications, err
+}
+
+// GetSettings retrieves the notification settings of members
+func (r *NotificationRepository) GetSettings(memberIDs []uint) ([]model.NotificationSetting, error) {
+	var settings []model.NotificationSetting
+	err := r.db.Where("member_id IN ?", memberIDs).Find(&settings).Error
+	return settings, err
+}
+
+// SaveSetting creates or updates the notification settings of a member
+func (r *NotificationRepository) SaveSetting(setting *model.NotificationSetting) error {
+	return r.db.Where("member_id = ?", setting.MemberID).
+		Assign(map[string]interface{}{"language": setting.Language, "channel": setting.Channel, "opt_out": setting.OptOut}).
+		FirstOrCreate(setting).Error
+}
@@ -23,14 +23,93 @@ func (r *ParticipationRepository) GetByCourseAndDate(courseID, date string) ([]m
 	return participations, err
 }
 
-// Upsert updates or inserts a participation record
+// Upsert updates or inserts a participation record, keyed by member, course and date
 func (r *ParticipationRepository) Upsert(participation *model.Participation) error {
-	return r.db.Save(participation).Error
+	return upsertParticipation(r.db, participation)
 }
 
 // Delete removes a participation record
 func (r *ParticipationRepository) Delete(memberID, courseID uint, date time.Time) error {
-	return r.db.Unscoped().Where("member_id = ? AND course_id = ? AND date = ?", memberID, courseID, date).Delete(&model.Participation{}).Error
+	return deleteParticipation(r.db, memberID, courseID, date)
+}
+
+// GetStatus retrieves the attendance status of a member at a session
+func (r *ParticipationRepository) GetStatus(memberID, courseID uint, date time.Time) (string, error) {
+	var participations []model.Participation
+	err := r.db.Where("member_id = ? AND course_id = ? AND date = ?", memberID, courseID, date).Limit(1).Find(&participations).Error
+	if err != nil || len(participations) == 0 {
+		return model.ParticipationStatusAbsent, err
+	}
+	return participations[0].Status, nil
+}
+
+// ApplyChanges stores attendance changes together with their journal and audit entries in one
+// transaction, creating the sessions on their first change. Members in dropIn pay with a punch card
+// credit; if one of them has no credits left, nothing is stored and gorm.ErrRecordNotFound is returned.
+func (r *ParticipationRepository) ApplyChanges(changes []model.AttendanceChange, dropIn map[uint]bool, auditEntries []model.AuditEntry) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return applyChanges(tx, changes, dropIn, auditEntries)
+	})
+}
+
+// applyChanges stores attendance changes together with their journal and audit entries within a transaction
+func applyChanges(tx *gorm.DB, changes []model.AttendanceChange, dropIn map[uint]bool, auditEntries []model.AuditEntry) error {
+	for i := range changes {
+		change := &changes[i]
+		if i == 0 || change.CourseID != changes[i-1].CourseID || !change.Date.Equal(changes[i-1].Date) {
+			if err := ensureSession(tx, change.CourseID, change.Date); err != nil {
+				return err
+			}
+		}
+		participation := &model.Participation{
+			MemberID: change.MemberID,
+			CourseID: change.CourseID,
+			Date:     change.Date,
+			Status:   change.Status,
+		}
+		var err error
+		switch {
+		case dropIn[change.MemberID] && change.Status == model.ParticipationStatusPresent:
+			err = recordVisit(tx, participation)
+		case dropIn[change.MemberID]:
+			err = cancelVisit(tx, change.MemberID, change.CourseID, change.Date, change.Status)
+		case change.Status == model.ParticipationStatusAbsent:
+			err = deleteParticipation(tx, change.MemberID, change.CourseID, change.Date)
+		default:
+			err = upsertParticipation(tx, participation)
+		}
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(change).Error; err != nil {
+			return err
+		}
+	}
+	if len(auditEntries) == 0 {
+		return nil
+	}
+	return createAuditEntries(tx, auditEntries)
+}
+
+// GetPreviousSessionDate retrieves the latest date before the given one with participations in a course
+func (r *ParticipationRepository) GetPreviousSessionDate(courseID uint, before time.Time) (time.Time, error) {
+	var participation model.Participation
+	err := r.db.Where("course_id = ? AND date < ?", courseID, before).
+		Order("date DESC").
+		First(&participation).Error
+	return participation.Date, err
+}
+
+// upsertParticipation updates or inserts a participation record using the given connection or transaction
+func upsertParticipation(db *gorm.DB, participation *model.Participation) error {
+	return db.Where("member_id = ? AND course_id = ? AND date = ?", participation.MemberID, participation.CourseID, participation.Date).
+		Assign(model.Participation{Status: participation.Status}).
+		FirstOrCreate(participation).Error
+}
+
+// deleteParticipation removes a participation record using the given connection or transaction
+func deleteParticipation(db *gorm.DB, memberID, courseID uint, date time.Time) error {
+	return db.Unscoped().Where("member_id = ? AND course_id = ? AND date = ?", memberID, courseID, date).Delete(&model.Participation{}).Error
 }
 
 // GetExportData retrieves participation data within a date range for export
@@ -41,3 +120,20 @@ func (r *ParticipationRepository) GetExportData(minDate, maxDate string) ([]mode
 		Find(&participations).Error
 	return participations, err
 }
+
+// GetHeldSessions retrieves one record per course and date within a date range on which at
+// least one member was present, leaving out sessions cancelled afterwards like attendanceCTE does
+func (r *ParticipationRepository) GetHeldSessions(minDate, maxDate string) ([]model.Participation, error) {
+	var sessions []model.Participation
+	err := r.db.Model(&model.Participation{}).
+		Distinct("course_id", "date").
+		Where("status = ? AND date >= ? AND date <= ?", model.ParticipationStatusPresent, minDate, maxDate).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM sessions cs
+			WHERE cs.deleted_at IS NULL AND cs.course_id = participations.course_id AND cs.date = participations.date
+				AND cs.cancelled_at IS NOT NULL
+		)`).
+		Order("date ASC, course_id ASC").
+		Find(&sessions).Error
+	return sessions, err
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"azh/internal/model"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// PunchCardSales holds the punch cards sold within a date range
+type PunchCardSales struct {
+	Cards   int     `json:"cards"`
+	Credits int     `json:"credits"`
+	Revenue float64 `json:"revenue"`
+}
+
+// PunchCardVisits holds the number of drop-in visits paid with punch cards per course
+type PunchCardVisits struct {
+	CourseID   uint   `json:"course_id"`
+	CourseName string `json:"course_name"`
+	Visits     int    `json:"visits"`
+}
+
+// PunchCardRepository handles database operations for punch cards and their usage
+type PunchCardRepository struct {
+	db *gorm.DB
+}
+
+// NewPunchCardRepository creates a new PunchCardRepository
+func NewPunchCardRepository(db *gorm.DB) *PunchCardRepository {
+	return &PunchCardRepository{db: db}
+}
+
+// Create stores a newly sold punch card
+func (r *PunchCardRepository) Create(card *model.PunchCard) error {
+	return r.db.Create(card).Error
+}
+
+// GetByMemberID retrieves the punch cards of a member, the latest first
+func (r *PunchCardRepository) GetByMemberID(memberID uint) ([]model.PunchCard, error) {
+	var cards []model.PunchCard
+	err := r.db.Where("member_id = ?", memberID).Order("sold_at DESC, id DESC").Find(&cards).Error
+	return cards, err
+}
+
+// GetBalances sums the remaining credits of the members' cards valid on the given date
+func (r *PunchCardRepository) GetBalances(memberIDs []uint, date time.Time) (map[uint]int, error) {
+	var rows []struct {
+		MemberID uint
+		Balance  int
+	}
+	err := validOn(r.db.Model(&model.PunchCard{}), date).
+		Select("member_id, SUM(remaining) AS balance").
+		Where("member_id IN ?", memberIDs).
+		Group("member_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	balances := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		balances[row.MemberID] = row.Balance
+	}
+	return balances, nil
+}
+
+// GetHolderIDs retrieves the IDs of members holding a card with credits left on the given date
+func (r *PunchCardRepository) GetHolderIDs(date time.Time) ([]uint, error) {
+	var memberIDs []uint
+	err := validOn(r.db.Model(&model.PunchCard{}), date).
+		Where("remaining > 0").
+		Distinct().
+		Pluck("member_id", &memberIDs).Error
+	return memberIDs, err
+}
+
+// GetSales sums the punch cards sold within a date range
+func (r *PunchCardRepository) GetSales(minDate, maxDate string) (PunchCardSales, error) {
+	var sales PunchCardSales
+	err := r.db.Model(&model.PunchCard{}).
+		Select("COUNT(*) AS cards, COALESCE(SUM(credits), 0) AS credits, COALESCE(SUM(price), 0) AS revenue").
+		Where("sold_at >= ? AND sold_at <= ?", minDate, maxDate).
+		Scan(&sales).Error
+	return sales, err
+}
+
+// GetVisits counts the visits paid with punch cards per course within a date range
+func (r *PunchCardRepository) GetVisits(minDate, maxDate string) ([]PunchCardVisits, error) {
+	var visits []PunchCardVisits
+	err := r.db.Table("punch_card_usages u").
+		Select("u.course_id, c.name AS course_name, COUNT(*) AS visits").
+		Joins("JOIN courses c ON c.id = u.course_id").
+		Where("u.deleted_at IS NULL AND u.date >= ? AND u.date <= ?", minDate, maxDate).
+		Group("u.course_id, c.name").
+		Order("u.course_id ASC").
+		Scan(&visits).Error
+	return visits, err
+}
+
+// GetOutstandingCredits sums the credits left on all cards valid on the given date
+func (r *PunchCardRepository) GetOutstandingCredits(date time.Time) (int, error) {
+	var credits int
+	err := validOn(r.db.Model(&model.PunchCard{}), date).
+		Select("COALESCE(SUM(remaining), 0)").
+		Scan(&credits).Error
+	return credits, err
+}
+
+// recordVisit marks a member present and deducts one credit from the card expiring first, unless
+// the session was already paid. It returns gorm.ErrRecordNotFound if no valid card has credits left.
+func recordVisit(tx *gorm.DB, participation *model.Participation) error {
+	var usage model.PunchCardUsage
+	err := tx.Where("member_id = ? AND course_id = ? AND date = ?", participation.MemberID, participation.CourseID, participation.Date).
+		First(&usage).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		var card model.PunchCard
+		if err := validOn(tx, participation.Date).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("member_id = ? AND remaining > 0", participation.MemberID).
+			Order("expires_at ASC NULLS LAST, sold_at ASC, id ASC").
+			First(&card).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&card).Update("remaining", gorm.Expr("remaining - 1")).Error; err != nil {
+			return err
+		}
+		usage = model.PunchCardUsage{
+			PunchCardID: card.ID,
+			MemberID:    participation.MemberID,
+			CourseID:    participation.CourseID,
+			Date:        participation.Date,
+		}
+		if err := tx.Create(&usage).Error; err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+	return upsertParticipation(tx, participation)
+}
+
+// cancelVisit refunds the credit used for a session and sets the member's status to excused or
+// absent (no record)
+func cancelVisit(tx *gorm.DB, memberID, courseID uint, date time.Time, status string) error {
+	var usage model.PunchCardUsage
+	err := tx.Where("member_id = ? AND course_id = ? AND date = ?", memberID, courseID, date).First(&usage).Error
+	if err == nil {
+		if err := tx.Model(&model.PunchCard{}).Where("id = ?", usage.PunchCardID).
+			Update("remaining", gorm.Expr("remaining + 1")).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Delete(&usage).Error; err != nil {
+			return err
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	if status == model.ParticipationStatusExcused {
+		return upsertParticipation(tx, &model.Participation{MemberID: memberID, CourseID: courseID, Date: date, Status: status})
+	}
+	return deleteParticipation(tx, memberID, courseID, date)
+}
+
+// validOn restricts a query to cards sold until and not expired on the given date
+func validOn(db *gorm.DB, date time.Time) *gorm.DB {
+	return db.Where("sold_at <= ? AND (expires_at IS NULL OR expires_at >= ?)", date, date)
+}
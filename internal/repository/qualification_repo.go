@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"azh/internal/model"
+	"gorm.io/gorm"
+)
+
+// QualificationRepository handles database operations for trainer qualifications
+type QualificationRepository struct {
+	db *gorm.DB
+}
+
+// NewQualificationRepository creates a new QualificationRepository
+func NewQualificationRepository(db *gorm.DB) *QualificationRepository {
+	return &QualificationRepository{db: db}
+}
+
+// GetByTrainerIDs retrieves the qualifications of the given trainers
+func (r *QualificationRepository) GetByTrainerIDs(trainerIDs []uint) ([]model.Qualification, error) {
+	var qualifications []model.Qualification
+	err := r.db.Where("trainer_id IN ?", trainerIDs).
+		Order("trainer_id ASC, type ASC, expires_at ASC").
+		Find(&qualifications).Error
+	return qualifications, err
+}
+
+// GetAll retrieves all qualifications
+func (r *QualificationRepository) GetAll() ([]model.Qualification, error) {
+	var qualifications []model.Qualification
+	err := r.db.Order("trainer_id ASC, type ASC, expires_at ASC").Find(&qualifications).Error
+	return qualifications, err
+}
+
+// Create stores a new qualification
+func (r *QualificationRepository) Create(qualification *model.Qualification) error {
+	return r.db.Create(qualification).Error
+}
+
+// Delete removes a qualification of a trainer
+func (r *QualificationRepository) Delete(trainerID, id uint) error {
+	return r.db.Where("trainer_id = ? AND id = ?", trainerID, id).Delete(&model.Qualification{}).Error
+}
+
+// GetRequirements retrieves the qualification requirements of all training types
+func (r *QualificationRepository) GetRequirements() ([]model.QualificationRequirement, error) {
+	var requirements []model.QualificationRequirement
+	err := r.db.Order("training_type ASC, qualification_type ASC").Find(&requirements).Error
+	return requirements, err
+}
+
+// ReplaceRequirements replaces the qualification requirements of a training type
+func (r *QualificationRepository) ReplaceRequirements(trainingType string, qualificationTypes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("training_type = ?", trainingType).Delete(&model.QualificationRequirement{}).Error; err != nil {
+			return err
+		}
+		for _, qualificationType := range qualificationTypes {
+			requirement := model.QualificationRequirement{TrainingType: trainingType, QualificationType: qualificationType}
+			if err := tx.Create(&requirement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"azh/internal/model"
+	"gorm.io/gorm"
+)
+
+// RegistrationRepository handles database operations for online registrations
+type RegistrationRepository struct {
+	db *gorm.DB
+}
+
+// NewRegistrationRepository creates a new RegistrationRepository
+func NewRegistrationRepository(db *gorm.DB) *RegistrationRepository {
+	return &RegistrationRepository{db: db}
+}
+
+// Create stores a new registration
+func (r *RegistrationRepository) Create(registration *model.Registration) error {
+	return r.db.Create(registration).Error
+}
+
+// Save updates a registration
+func (r *RegistrationRepository) Save(registration *model.Registration) error {
+	return r.db.Save(registration).Error
+}
+
+// CreateMember stores the member of a registration under the next free ID reserved for online
+// registrations and records it on the registration in the same transaction, so a retried approval
+// finds the member instead of creating another one. It returns gorm.ErrRecordNotFound if the
+// registration got a member in the meantime.
+func (r *RegistrationRepository) CreateMember(registration *model.Registration, member *model.Member) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := createMemberWithNextID(tx, member, model.RegisteredIDOffset, model.GuestIDOffset); err != nil {
+			return err
+		}
+		result := tx.Model(&model.Registration{}).
+			Where("id = ? AND member_id IS NULL", registration.ID).
+			Update("member_id", member.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		registration.MemberID = &member.ID
+		return nil
+	})
+}
+
+// Delete removes a registration
+func (r *RegistrationRepository) Delete(id uint) error {
+	return r.db.Unscoped().Delete(&model.Registration{}, id).Error
+}
+
+// GetByID retrieves a registration by ID
+func (r *RegistrationRepository) GetByID(id uint) (model.Registration, error) {
+	var registration model.Registration
+	err := r.db.First(&registration, id).Error
+	return registration, err
+}
+
+// GetByToken retrieves a registration by its confirmation token
+func (r *RegistrationRepository) GetByToken(token string) (model.Registration, error) {
+	var registration model.Registration
+	err := r.db.Where("confirmation_token = ?", token).First(&registration).Error
+	return registration, err
+}
+
+// GetByStatus retrieves registrations with the given status, oldest first; an empty status returns all
+func (r *RegistrationRepository) GetByStatus(status string) ([]model.Registration, error) {
+	var registrations []model.Registration
+	query := r.db.Order("created_at ASC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Find(&registrations).Error
+	return registrations, err
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"azh/internal/model"
+	"gorm.io/gorm"
+	"time"
+)
+
+// SessionLockRepository handles database operations for session locks and unlock requests
+type SessionLockRepository struct {
+	db *gorm.DB
+}
+
+// NewSessionLockRepository creates a new SessionLockRepository
+func NewSessionLockRepository(db *gorm.DB) *SessionLockRepository {
+	return &SessionLockRepository{db: db}
+}
+
+// GetLock retrieves the lock of a session; it returns gorm.ErrRecordNotFound if the session was not locked manually
+func (r *SessionLockRepository) GetLock(courseID uint, date time.Time) (model.SessionLock, error) {
+	var lock model.SessionLock
+	err := r.db.Where("course_id = ? AND date = ?", courseID, date).First(&lock).Error
+	return lock, err
+}
+
+// CreateLock stores a session lock
+func (r *SessionLockRepository) CreateLock(lock *model.SessionLock) error {
+	return r.db.Create(lock).Error
+}
+
+// CreateRequest stores a new unlock request
+func (r *SessionLockRepository) CreateRequest(request *model.UnlockRequest) error {
+	return r.db.Create(request).Error
+}
+
+// SaveRequest updates an unlock request
+func (r *SessionLockRepository) SaveRequest(request *model.UnlockRequest) error {
+	return r.db.Save(request).Error
+}
+
+// GetRequestByID retrieves an unlock request by ID
+func (r *SessionLockRepository) GetRequestByID(id uint) (model.UnlockRequest, error) {
+	var request model.UnlockRequest
+	err := r.db.First(&request, id).Error
+	return request, err
+}
+
+// GetRequestsByStatus retrieves unlock requests with the given status, oldest first; an empty status returns all
+func (r *SessionLockRepository) GetRequestsByStatus(status string) ([]model.UnlockRequest, error) {
+	var requests []model.UnlockRequest
+	query := r.db.Order("created_at ASC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Find(&requests).Error
+	return requests, err
+}
+
+// GetSessionRequests retrieves the unlock requests of a session, newest first
+func (r *SessionLockRepository) GetSessionRequests(courseID uint, date time.Time) ([]model.UnlockRequest, error) {
+	var requests []model.UnlockRequest
+	err := r.db.Where("course_id = ? AND date = ?", courseID, date).Order("created_at DESC").Find(&requests).Error
+	return requests, err
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"azh/internal/model"
+	"gorm.io/gorm"
+	"time"
+)
+
+// SessionRepository handles database operations for sessions
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+// NewSessionRepository creates a new SessionRepository
+func NewSessionRepository(db *gorm.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// Get retrieves the session of a course on a specific date; it returns gorm.ErrRecordNotFound if
+// nothing was recorded for it yet
+func (r *SessionRepository) Get(courseID uint, date time.Time) (model.Session, error) {
+	var session model.Session
+	err := r.db.Where("course_id = ? AND date = ?", courseID, date).First(&session).Error
+	return session, err
+}
+
+// Save creates or updates a session
+func (r *SessionRepository) Save(session *model.Session) error {
+	return r.db.Save(session).Error
+}
+
+// GetByCourse retrieves the sessions of a course within a date range, oldest first
+func (r *SessionRepository) GetByCourse(courseID uint, minDate, maxDate string) ([]model.Session, error) {
+	var sessions []model.Session
+	err := r.db.Where("course_id = ? AND date >= ? AND date <= ?", courseID, minDate, maxDate).
+		Order("date ASC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// GetInRange retrieves all sessions within a date range, e.g. for exports and calendar feeds
+func (r *SessionRepository) GetInRange(minDate, maxDate string) ([]model.Session, error) {
+	var sessions []model.Session
+	err := r.db.Where("date >= ? AND date <= ?", minDate, maxDate).
+		Order("date ASC, course_id ASC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// ensureSession creates the session of a course and date unless it exists, using the given transaction
+func ensureSession(tx *gorm.DB, courseID uint, date time.Time) error {
+	return tx.Where("course_id = ? AND date = ?", courseID, date).
+		FirstOrCreate(&model.Session{CourseID: courseID, Date: date}).Error
+}
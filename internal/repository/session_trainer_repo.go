@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"azh/internal/model"
+	"gorm.io/gorm"
+	"time"
+)
+
+// SessionTrainerRepository handles database operations for session trainers
+type SessionTrainerRepository struct {
+	db *gorm.DB
+}
+
+// NewSessionTrainerRepository creates a new SessionTrainerRepository
+func NewSessionTrainerRepository(db *gorm.DB) *SessionTrainerRepository {
+	return &SessionTrainerRepository{db: db}
+}
+
+// GetByCourseAndDate retrieves the trainers recorded for a specific course and date
+func (r *SessionTrainerRepository) GetByCourseAndDate(courseID uint, date time.Time) ([]model.SessionTrainer, error) {
+	var trainers []model.SessionTrainer
+	err := r.db.Where("course_id = ? AND date = ?", courseID, date).Order("id ASC").Find(&trainers).Error
+	return trainers, err
+}
+
+// GetInRange retrieves all trainers recorded for sessions within a date range
+func (r *SessionTrainerRepository) GetInRange(minDate, maxDate string) ([]model.SessionTrainer, error) {
+	var trainers []model.SessionTrainer
+	err := r.db.Where("date >= ? AND date <= ?", minDate, maxDate).
+		Order("date ASC, course_id ASC, id ASC").
+		Find(&trainers).Error
+	return trainers, err
+}
+
+// Replace replaces the trainers recorded for a specific course and date
+func (r *SessionTrainerRepository) Replace(courseID uint, date time.Time, trainers []model.SessionTrainer) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("course_id = ? AND date = ?", courseID, date).Delete(&model.SessionTrainer{}).Error; err != nil {
+			return err
+		}
+		if len(trainers) == 0 {
+			return nil
+		}
+		return tx.Create(&trainers).Error
+	})
+}
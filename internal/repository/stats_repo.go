@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+// attendanceCTE expands the held sessions within [@minDate, @maxDate] into one row per
+// enrolled member. A session counts as held once at least one member has been marked
+// present, so weeks without any attendance (holidays, cancelled trainings) are not held
+// against the members. Sessions cancelled in the application never count, even with
+// attendance recorded before. Excused absences are left out of the expected sessions as well.
+const attendanceCTE = `
+WITH held AS (
+	SELECT DISTINCT p.course_id, p.date
+	FROM participations p
+	WHERE p.deleted_at IS NULL
+		AND p.status = 'present'
+		AND p.date BETWEEN @minDate AND @maxDate
+		AND (@courseID = 0 OR p.course_id = @courseID)
+		AND NOT EXISTS (
+			SELECT 1 FROM sessions cs
+			WHERE cs.deleted_at IS NULL AND cs.course_id = p.course_id AND cs.date = p.date
+				AND cs.cancelled_at IS NOT NULL
+		)
+),
+marks AS (
+	SELECT s.course_id, s.date, mc.member_id,
+		(
+			SELECT p.status FROM participations p
+			WHERE p.deleted_at IS NULL AND p.course_id = s.course_id AND p.date = s.date AND p.member_id = mc.member_id
+			LIMIT 1
+		) AS status
+	FROM held s
+	JOIN (SELECT DISTINCT course_id, member_id FROM member_courses WHERE deleted_at IS NULL) mc ON mc.course_id = s.course_id
+	JOIN members m ON m.id = mc.member_id AND m.deleted_at IS NULL
+		AND (m.sign_up_date IS NULL OR m.sign_up_date <= s.date)
+		AND (m.cancellation_date IS NULL OR m.cancellation_date >= s.date)
+	WHERE (@memberID = 0 OR mc.member_id = @memberID)
+),
+attendance AS (
+	SELECT course_id, date, member_id, COALESCE(status = 'present', false) AS attended
+	FROM marks
+	WHERE status IS DISTINCT FROM 'excused'
+)`
+
+// StatsFilter restricts statistics to a date range and optionally to a course or member
+type StatsFilter struct {
+	MinDate  string
+	MaxDate  string
+	CourseID uint
+	MemberID uint
+}
+
+func (f StatsFilter) params() map[string]interface{} {
+	return map[string]interface{}{
+		"minDate":  f.MinDate,
+		"maxDate":  f.MaxDate,
+		"courseID": f.CourseID,
+		"memberID": f.MemberID,
+	}
+}
+
+// MemberAttendanceStat holds the attendance rate of a member in a course
+type MemberAttendanceStat struct {
+	MemberID   uint    `json:"member_id"`
+	FirstName  string  `json:"first_name"`
+	LastName   string  `json:"last_name"`
+	CourseID   uint    `json:"course_id"`
+	CourseName string  `json:"course_name"`
+	Sessions   int     `json:"sessions"`
+	Attended   int     `json:"attended"`
+	Rate       float64 `json:"rate"`
+}
+
+// CourseAttendanceStat holds aggregated attendance figures of a course
+type CourseAttendanceStat struct {
+	CourseID           uint    `json:"course_id"`
+	CourseName         string  `json:"course_name"`
+	CourseKind         string  `json:"course_kind"`
+	Sessions           int     `json:"sessions"`
+	AverageEnrolled    float64 `json:"average_enrolled"`
+	AverageAttendance  float64 `json:"average_attendance"`
+	AverageRate        float64 `json:"average_rate"`
+	TotalParticipation int     `json:"total_participation"`
+}
+
+// SessionHeadCount holds the head count of a single session
+type SessionHeadCount struct {
+	CourseID uint   `json:"course_id"`
+	Date     string `json:"date"`
+	Enrolled int    `json:"enrolled"`
+	Present  int    `json:"present"`
+}
+
+// AttendanceStreak holds the attendance streaks of a member in a course
+type AttendanceStreak struct {
+	MemberID      uint `json:"member_id"`
+	CourseID      uint `json:"course_id"`
+	CurrentStreak int  `json:"current_streak"`
+	LongestStreak int  `json:"longest_streak"`
+	MissedInARow  int  `json:"missed_in_a_row"`
+}
+
+// StatsRepository computes attendance statistics directly in the database
+type StatsRepository struct {
+	db *gorm.DB
+}
+
+// NewStatsRepository creates a new StatsRepository
+func NewStatsRepository(db *gorm.DB) *StatsRepository {
+	return &StatsRepository{db: db}
+}
+
+// GetMemberStats retrieves the attendance rate per member and course
+func (r *StatsRepository) GetMemberStats(filter StatsFilter) ([]MemberAttendanceStat, error) {
+	var stats []MemberAttendanceStat
+	err := r.db.Raw(attendanceCTE+`
+		SELECT a.member_id, m.first_name, m.last_name, a.course_id, c.name AS course_name,
+			COUNT(*) AS sessions,
+			COUNT(*) FILTER (WHERE a.attended) AS attended,
+			ROUND(COUNT(*) FILTER (WHERE a.attended)::numeric / COUNT(*), 4) AS rate
+		FROM attendance a
+		JOIN members m ON m.id = a.member_id
+		JOIN courses c ON c.id = a.course_id
+		GROUP BY a.member_id, m.first_name, m.last_name, a.course_id, c.name
+		ORDER BY c.name ASC, m.first_name ASC, m.last_name ASC, a.member_id ASC`, filter.params()).
+		Scan(&stats).Error
+	return stats, err
+}
+
+// GetCourseStats retrieves average attendance figures per course
+func (r *StatsRepository) GetCourseStats(filter StatsFilter) ([]CourseAttendanceStat, error) {
+	var stats []CourseAttendanceStat
+	err := r.db.Raw(attendanceCTE+`,
+		per_session AS (
+			SELECT course_id, date, COUNT(*) AS enrolled, COUNT(*) FILTER (WHERE attended) AS present
+			FROM attendance
+			GROUP BY course_id, date
+		)
+		SELECT s.course_id, c.name AS course_name, c.kind AS course_kind,
+			COUNT(*) AS sessions,
+			ROUND(AVG(s.enrolled), 2) AS average_enrolled,
+			ROUND(AVG(s.present), 2) AS average_attendance,
+			ROUND(SUM(s.present)::numeric / NULLIF(SUM(s.enrolled), 0), 4) AS average_rate,
+			SUM(s.present) AS total_participation
+		FROM per_session s
+		JOIN courses c ON c.id = s.course_id
+		GROUP BY s.course_id, c.name, c.kind
+		ORDER BY s.course_id ASC`, filter.params()).
+		Scan(&stats).Error
+	return stats, err
+}
+
+// GetSessionHeadCounts retrieves the head count per held session
+func (r *StatsRepository) GetSessionHeadCounts(filter StatsFilter) ([]SessionHeadCount, error) {
+	var counts []SessionHeadCount
+	err := r.db.Raw(attendanceCTE+`
+		SELECT course_id, to_char(date, 'YYYY-MM-DD') AS date,
+			COUNT(*) AS enrolled,
+			COUNT(*) FILTER (WHERE attended) AS present
+		FROM attendance
+		GROUP BY course_id, date
+		ORDER BY course_id ASC, date ASC`, filter.params()).
+		Scan(&counts).Error
+	return counts, err
+}
+
+// GetStreaks retrieves the current and longest attendance streak per member and course.
+// Consecutive sessions with the same attendance form an island; the island containing
+// the latest session determines the current streak or the number of sessions missed in a row.
+func (r *StatsRepository) GetStreaks(filter StatsFilter) ([]AttendanceStreak, error) {
+	var streaks []AttendanceStreak
+	err := r.db.Raw(attendanceCTE+`,
+		numbered AS (
+			SELECT member_id, course_id, date, attended,
+				ROW_NUMBER() OVER (PARTITION BY member_id, course_id ORDER BY date)
+					- ROW_NUMBER() OVER (PARTITION BY member_id, course_id, attended ORDER BY date) AS grp,
+				MAX(date) OVER (PARTITION BY member_id, course_id) AS last_date
+			FROM attendance
+		),
+		islands AS (
+			SELECT member_id, course_id, attended, COUNT(*) AS len, MAX(date) = MAX(last_date) AS is_current
+			FROM numbered
+			GROUP BY member_id, course_id, attended, grp
+		)
+		SELECT member_id, course_id,
+			COALESCE(MAX(len) FILTER (WHERE attended AND is_current), 0) AS current_streak,
+			COALESCE(MAX(len) FILTER (WHERE attended), 0) AS longest_streak,
+			COALESCE(MAX(len) FILTER (WHERE NOT attended AND is_current), 0) AS missed_in_a_row
+		FROM islands
+		GROUP BY member_id, course_id
+		ORDER BY course_id ASC, member_id ASC`, filter.params()).
+		Scan(&streaks).Error
+	return streaks, err
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"azh/internal/model"
+	"gorm.io/gorm"
+)
+
+// TrainerRepository handles database operations for trainers and their courses
+type TrainerRepository struct {
+	db *gorm.DB
+}
+
+// NewTrainerRepository creates a new TrainerRepository
+func NewTrainerRepository(db *gorm.DB) *TrainerRepository {
+	return &TrainerRepository{db: db}
+}
+
+// GetAll retrieves all trainers
+func (r *TrainerRepository) GetAll() ([]model.Trainer, error) {
+	var trainers []model.Trainer
+	err := r.db.Order("name ASC, id ASC").Find(&trainers).Error
+	return trainers, err
+}
+
+// GetByID retrieves a trainer by ID
+func (r *TrainerRepository) GetByID(id uint) (model.Trainer, error) {
+	var trainer model.Trainer
+	err := r.db.Where("id = ?", id).First(&trainer).Error
+	return trainer, err
+}
+
+// GetByIDs retrieves trainers by their IDs
+func (r *TrainerRepository) GetByIDs(ids []uint) ([]model.Trainer, error) {
+	var trainers []model.Trainer
+	err := r.db.Where("id IN ?", ids).Order("name ASC, id ASC").Find(&trainers).Error
+	return trainers, err
+}
+
+// GetByName retrieves a trainer by name
+func (r *TrainerRepository) GetByName(name string) (model.Trainer, error) {
+	var trainer model.Trainer
+	err := r.db.Where("name = ?", name).First(&trainer).Error
+	return trainer, err
+}
+
+// FindOrCreateByName retrieves a trainer by name or creates it
+func (r *TrainerRepository) FindOrCreateByName(name string) (model.Trainer, error) {
+	trainer := model.Trainer{Name: name}
+	err := r.db.Where("name = ?", name).FirstOrCreate(&trainer).Error
+	return trainer, err
+}
+
+// Save creates or updates a trainer
+func (r *TrainerRepository) Save(trainer *model.Trainer) error {
+	return r.db.Save(trainer).Error
+}
+
+// GetCourseTrainers retrieves the regular trainer links of the given courses
+func (r *TrainerRepository) GetCourseTrainers(courseIDs []uint) ([]model.CourseTrainer, error) {
+	var links []model.CourseTrainer
+	err := r.db.Where("course_id IN ?", courseIDs).Order("course_id ASC, id ASC").Find(&links).Error
+	return links, err
+}
+
+// GetCoursesByTrainerID retrieves the IDs of the courses a trainer regularly leads
+func (r *TrainerRepository) GetCoursesByTrainerID(trainerID uint) ([]uint, error) {
+	var courseIDs []uint
+	err := r.db.Model(&model.CourseTrainer{}).Where("trainer_id = ?", trainerID).Order("course_id ASC").Pluck("course_id", &courseIDs).Error
+	return courseIDs, err
+}
+
+// ReplaceCourseTrainers replaces the regular trainers of a course
+func (r *TrainerRepository) ReplaceCourseTrainers(courseID uint, trainerIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("course_id = ?", courseID).Delete(&model.CourseTrainer{}).Error; err != nil {
+			return err
+		}
+		for _, trainerID := range trainerIDs {
+			if err := tx.Create(&model.CourseTrainer{CourseID: courseID, TrainerID: trainerID}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CreateUnavailability stores a trainer unavailability
+func (r *TrainerRepository) CreateUnavailability(unavailability *model.TrainerUnavailability) error {
+	return r.db.Create(unavailability).Error
+}
+
+// DeleteUnavailability removes a trainer unavailability
+func (r *TrainerRepository) DeleteUnavailability(trainerID, id uint) error {
+	return r.db.Where("trainer_id = ? AND id = ?", trainerID, id).Delete(&model.TrainerUnavailability{}).Error
+}
+
+// GetUnavailabilities retrieves all trainer unavailabilities within a date range
+func (r *TrainerRepository) GetUnavailabilities(minDate, maxDate string) ([]model.TrainerUnavailability, error) {
+	var unavailabilities []model.TrainerUnavailability
+	err := r.db.Where("date >= ? AND date <= ?", minDate, maxDate).
+		Order("date ASC, trainer_id ASC").
+		Find(&unavailabilities).Error
+	return unavailabilities, err
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"azh/internal/model"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// WaitlistRepository handles database operations for course waitlists
+type WaitlistRepository struct {
+	db *gorm.DB
+}
+
+// NewWaitlistRepository creates a new WaitlistRepository
+func NewWaitlistRepository(db *gorm.DB) *WaitlistRepository {
+	return &WaitlistRepository{db: db}
+}
+
+// GetWaiting retrieves the members still waiting for a course in waitlist order
+func (r *WaitlistRepository) GetWaiting(courseID uint) ([]model.WaitlistEntry, error) {
+	var entries []model.WaitlistEntry
+	err := r.db.Where("course_id = ? AND promoted_at IS NULL", courseID).
+		Order("position ASC, created_at ASC").
+		Find(&entries).Error
+	return entries, err
+}
+
+// GetCourseIDsWithWaiting retrieves the IDs of courses with members waiting
+func (r *WaitlistRepository) GetCourseIDsWithWaiting() ([]uint, error) {
+	var courseIDs []uint
+	err := r.db.Model(&model.WaitlistEntry{}).
+		Where("promoted_at IS NULL").
+		Distinct().
+		Order("course_id ASC").
+		Pluck("course_id", &courseIDs).Error
+	return courseIDs, err
+}
+
+// Enroll enrolls a member in a course if it has room on the reference date and puts them at the
+// end of its waitlist otherwise, unless they are waiting already. The course is locked while its
+// enrollments are counted, so concurrent enrollments cannot take the same last spot. It returns the
+// waitlist entry, which is empty if the member was enrolled.
+func (r *WaitlistRepository) Enroll(courseID, memberID uint, referenceDate time.Time) (model.WaitlistEntry, error) {
+	var entry model.WaitlistEntry
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		free, err := lockCourseHasRoom(tx, courseID, referenceDate)
+		if err != nil {
+			return err
+		}
+		if free {
+			if err := removeWaiting(tx, courseID, memberID); err != nil {
+				return err
+			}
+			return tx.Create(&model.MemberCourse{MemberID: memberID, CourseID: courseID, Manual: true}).Error
+		}
+		err = tx.Where("course_id = ? AND member_id = ? AND promoted_at IS NULL", courseID, memberID).First(&entry).Error
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		entry = model.WaitlistEntry{CourseID: courseID, MemberID: memberID}
+		var last int
+		if err := tx.Model(&model.WaitlistEntry{}).
+			Where("course_id = ?", courseID).
+			Select("COALESCE(MAX(position), 0)").
+			Scan(&last).Error; err != nil {
+			return err
+		}
+		entry.Position = last + 1
+		return tx.Create(&entry).Error
+	})
+	return entry, err
+}
+
+// Remove removes a waiting member from a course's waitlist
+func (r *WaitlistRepository) Remove(courseID, memberID uint) error {
+	return removeWaiting(r.db, courseID, memberID)
+}
+
+// Promote enrolls the member of a waitlist entry and marks the entry as promoted, if the course
+// still has room on the reference date; it returns whether the member was promoted
+func (r *WaitlistRepository) Promote(entry model.WaitlistEntry, referenceDate, promotedAt time.Time) (bool, error) {
+	promoted := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		free, err := lockCourseHasRoom(tx, entry.CourseID, referenceDate)
+		if err != nil || !free {
+			return err
+		}
+		promoted = true
+		enrollment := model.MemberCourse{MemberID: entry.MemberID, CourseID: entry.CourseID, Manual: true}
+		if err := tx.Create(&enrollment).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.WaitlistEntry{}).
+			Where("id = ?", entry.ID).
+			Update("promoted_at", promotedAt).Error
+	})
+	return promoted && err == nil, err
+}
+
+// lockCourseHasRoom locks a course for the rest of the transaction and reports whether it has room for
+// another member on the reference date; courses without capacity have unlimited room
+func lockCourseHasRoom(tx *gorm.DB, courseID uint, referenceDate time.Time) (bool, error) {
+	var course model.Course
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "max_participants").First(&course, courseID).Error; err != nil {
+		return false, err
+	}
+	if course.MaxParticipants <= 0 {
+		return true, nil
+	}
+	var enrolled int64
+	if err := activeEnrollments(tx, referenceDate).
+		Where("mc.course_id = ?", courseID).
+		Distinct("mc.member_id").
+		Count(&enrolled).Error; err != nil {
+		return false, err
+	}
+	return enrolled < int64(course.MaxParticipants), nil
+}
+
+// removeWaiting removes a waiting member from a course's waitlist
+func removeWaiting(tx *gorm.DB, courseID, memberID uint) error {
+	return tx.Where("course_id = ? AND member_id = ? AND promoted_at IS NULL", courseID, memberID).
+		Delete(&model.WaitlistEntry{}).Error
+}
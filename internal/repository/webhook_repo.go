@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"azh/internal/model"
+	"gorm.io/gorm"
+	"time"
+)
+
+// WebhookRepository handles database operations for webhooks and their delivery log
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository creates a new WebhookRepository
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// GetAll retrieves all webhooks
+func (r *WebhookRepository) GetAll() ([]model.Webhook, error) {
+	var webhooks []model.Webhook
+	err := r.db.Order("name ASC").Find(&webhooks).Error
+	return webhooks, err
+}
+
+// GetActive retrieves the webhooks that receive events
+func (r *WebhookRepository) GetActive() ([]model.Webhook, error) {
+	var webhooks []model.Webhook
+	err := r.db.Where("active = ?", true).Find(&webhooks).Error
+	return webhooks, err
+}
+
+// GetByID retrieves a webhook by ID
+func (r *WebhookRepository) GetByID(id uint) (model.Webhook, error) {
+	var webhook model.Webhook
+	err := r.db.First(&webhook, id).Error
+	return webhook, err
+}
+
+// Save creates or updates a webhook
+func (r *WebhookRepository) Save(webhook *model.Webhook) error {
+	return r.db.Save(webhook).Error
+}
+
+// Delete removes a webhook; its delivery log is kept
+func (r *WebhookRepository) Delete(id uint) error {
+	return r.db.Delete(&model.Webhook{}, id).Error
+}
+
+// CreateDeliveries stores new deliveries
+func (r *WebhookRepository) CreateDeliveries(deliveries []model.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.Create(&deliveries).Error
+}
+
+// SaveDelivery creates or updates a delivery
+func (r *WebhookRepository) SaveDelivery(delivery *model.WebhookDelivery) error {
+	return r.db.Save(delivery).Error
+}
+
+// GetDelivery retrieves a delivery by ID
+func (r *WebhookRepository) GetDelivery(id uint) (model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	err := r.db.First(&delivery, id).Error
+	return delivery, err
+}
+
+// GetDueDeliveries retrieves pending deliveries whose next attempt is due, oldest first
+func (r *WebhookRepository) GetDueDeliveries(now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+	err := r.db.Where("status = ? AND next_attempt_at <= ?", model.WebhookDeliveryPending, now).
+		Order("next_attempt_at ASC, id ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// FindDeliveries retrieves deliveries by webhook and status, newest first; empty filters match all
+func (r *WebhookRepository) FindDeliveries(webhookID uint, status string, limit int) ([]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+	query := r.db.Order("id DESC").Limit(limit)
+	if webhookID != 0 {
+		query = query.Where("webhook_id = ?", webhookID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Find(&deliveries).Error
+	return deliveries, err
+}
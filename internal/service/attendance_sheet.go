@@ -0,0 +1,329 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"azh/internal/model"
+	"github.com/xuri/excelize/v2"
+)
+
+// Marks used in attendance sheets
+var attendanceMarks = map[string]string{
+	model.ParticipationStatusPresent: "x",
+	model.ParticipationStatusExcused: "e",
+	model.ParticipationStatusAbsent:  "-",
+}
+
+const attendanceLegend = "x = anwesend, e = entschuldigt, - = fehlt, leer = nicht angemeldet"
+
+// AttendanceSheet is the attendance matrix of a course with members as rows and session dates as columns
+type AttendanceSheet struct {
+	Course       model.Course
+	Dates        []string
+	Rows         []AttendanceSheetRow
+	ColumnTotals []int
+	Total        int
+}
+
+// AttendanceSheetRow holds a member's statuses per session date; the status is empty for
+// dates on which the member was not enrolled
+type AttendanceSheetRow struct {
+	Member   model.Member
+	Statuses []string
+	Present  int
+}
+
+// BuildAttendanceSheets builds one attendance sheet per course with recorded attendance between
+// minDate and maxDate. A non-zero courseID restricts the result to that course. With withSchedule,
+// the columns also cover all scheduled dates of the course, e.g. to fill in a paper list by hand.
+func (s *ParticipationService) BuildAttendanceSheets(minDate, maxDate string, courseID uint, withSchedule bool) ([]AttendanceSheet, error) {
+	from, err := time.Parse("2006-01-02", minDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid minDate: %v", err)
+	}
+	to, err := time.Parse("2006-01-02", maxDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid maxDate: %v", err)
+	}
+
+	participations, err := s.participationRepo.GetExportData(minDate, maxDate)
+	if err != nil {
+		return nil, err
+	}
+	memberCourses, err := s.memberCourseRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect dates, statuses and members per course
+	type courseData struct {
+		dates     map[string]struct{}
+		statuses  map[uint]map[string]string
+		memberIDs map[uint]struct{}
+	}
+	data := make(map[uint]*courseData)
+	get := func(id uint) *courseData {
+		if data[id] == nil {
+			data[id] = &courseData{
+				dates:     make(map[string]struct{}),
+				statuses:  make(map[uint]map[string]string),
+				memberIDs: make(map[uint]struct{}),
+			}
+		}
+		return data[id]
+	}
+	if courseID != 0 {
+		get(courseID)
+	}
+	for _, p := range participations {
+		if courseID != 0 && p.CourseID != courseID {
+			continue
+		}
+		d := get(p.CourseID)
+		date := p.Date.Format("2006-01-02")
+		d.dates[date] = struct{}{}
+		if d.statuses[p.MemberID] == nil {
+			d.statuses[p.MemberID] = make(map[string]string)
+		}
+		d.statuses[p.MemberID][date] = p.Status
+		d.memberIDs[p.MemberID] = struct{}{}
+	}
+	enrolled := make(map[uint]map[uint]struct{})
+	for _, mc := range memberCourses {
+		d, ok := data[mc.CourseID]
+		if !ok {
+			continue
+		}
+		d.memberIDs[mc.MemberID] = struct{}{}
+		if enrolled[mc.CourseID] == nil {
+			enrolled[mc.CourseID] = make(map[uint]struct{})
+		}
+		enrolled[mc.CourseID][mc.MemberID] = struct{}{}
+	}
+	if len(data) == 0 {
+		return []AttendanceSheet{}, nil
+	}
+
+	courseIDs := make([]uint, 0, len(data))
+	for id := range data {
+		courseIDs = append(courseIDs, id)
+	}
+	courses, err := s.courseRepo.GetByIDs(courseIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	sheets := make([]AttendanceSheet, 0, len(courses))
+	for _, course := range courses {
+		d := data[course.ID]
+		if withSchedule {
+			for _, date := range scheduledDates(course, from, to) {
+				d.dates[date] = struct{}{}
+			}
+		}
+		sheet := AttendanceSheet{Course: course, Dates: sortedKeys(d.dates)}
+		sheet.ColumnTotals = make([]int, len(sheet.Dates))
+
+		memberIDs := make([]uint, 0, len(d.memberIDs))
+		for id := range d.memberIDs {
+			memberIDs = append(memberIDs, id)
+		}
+		members, err := s.memberRepo.GetByIDs(memberIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range members {
+			row := AttendanceSheetRow{Member: member, Statuses: make([]string, len(sheet.Dates))}
+			_, isEnrolled := enrolled[course.ID][member.ID]
+			if _, recorded := d.statuses[member.ID]; !recorded && !isActiveBetween(member, from, to) {
+				continue
+			}
+			for i, date := range sheet.Dates {
+				if status, ok := d.statuses[member.ID][date]; ok {
+					row.Statuses[i] = status
+				} else if isEnrolled && isActiveOn(member, date) {
+					row.Statuses[i] = model.ParticipationStatusAbsent
+				}
+				if row.Statuses[i] == model.ParticipationStatusPresent {
+					row.Present++
+					sheet.ColumnTotals[i]++
+				}
+			}
+			sheet.Total += row.Present
+			sheet.Rows = append(sheet.Rows, row)
+		}
+		sheets = append(sheets, sheet)
+	}
+	return sheets, nil
+}
+
+// isActiveOn reports whether a member's membership covers the given date (YYYY-MM-DD)
+func isActiveOn(member model.Member, date string) bool {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return false
+	}
+	return !member.SignUpDate.After(day) && (member.CancellationDate.IsZero() || !member.CancellationDate.Before(day))
+}
+
+// isActiveBetween reports whether a member's membership overlaps the given range
+func isActiveBetween(member model.Member, from, to time.Time) bool {
+	return !member.SignUpDate.After(to) && (member.CancellationDate.IsZero() || !member.CancellationDate.Before(from))
+}
+
+// sortedKeys returns the keys of a set in ascending order
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sheetTable renders an attendance sheet as a table including header, totals and legend
+func sheetTable(sheet AttendanceSheet) [][]string {
+	header := []string{"Mitgliedsnummer", "Vorname", "Nachname"}
+	for _, date := range sheet.Dates {
+		if day, err := time.Parse("2006-01-02", date); err == nil {
+			date = day.Format("02.01.2006")
+		}
+		header = append(header, date)
+	}
+	header = append(header, "Summe")
+
+	table := [][]string{
+		{fmt.Sprintf("%d %s", sheet.Course.ID, sheet.Course.Name)},
+		{fmt.Sprintf("%s, %s %s-%s, Trainer: %s", sheet.Course.Location, sheet.Course.Weekday,
+			sheet.Course.StartTime, sheet.Course.EndTime, sheet.Course.TrainerNames)},
+		header,
+	}
+	for _, row := range sheet.Rows {
+		line := []string{fmt.Sprintf("%d", row.Member.ID), row.Member.FirstName, row.Member.LastName}
+		for _, status := range row.Statuses {
+			line = append(line, attendanceMarks[status])
+		}
+		table = append(table, append(line, fmt.Sprintf("%d", row.Present)))
+	}
+	totals := []string{"", "", "Summe"}
+	for _, total := range sheet.ColumnTotals {
+		totals = append(totals, fmt.Sprintf("%d", total))
+	}
+	table = append(table, append(totals, fmt.Sprintf("%d", sheet.Total)))
+	table = append(table, []string{attendanceLegend})
+	return table
+}
+
+// writeSheetsCSV writes all sheets into a single CSV, separated by empty lines
+func writeSheetsCSV(sheets []AttendanceSheet) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	for i, sheet := range sheets {
+		if i > 0 {
+			if err := writer.Write([]string{""}); err != nil {
+				return nil, err
+			}
+		}
+		if err := writer.WriteAll(sheetTable(sheet)); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+// writeSheetsXLSX writes every sheet into its own worksheet
+func writeSheetsXLSX(sheets []AttendanceSheet) ([]byte, error) {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	used := make(map[string]struct{})
+	for _, sheet := range sheets {
+		name := worksheetName(fmt.Sprintf("%d %s", sheet.Course.ID, sheet.Course.Name), used)
+		if err := writeWorksheet(file, name, sheetTable(sheet)); err != nil {
+			return nil, err
+		}
+	}
+	if len(sheets) == 0 {
+		if err := writeWorksheet(file, "Anwesenheit", [][]string{{"Keine Anwesenheiten im gewählten Zeitraum"}}); err != nil {
+			return nil, err
+		}
+	}
+	if err := file.DeleteSheet("Sheet1"); err != nil {
+		return nil, err
+	}
+	buf, err := file.WriteToBuffer()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeTablesXLSX writes tables as the worksheets of an Excel file, named in the same order
+func writeTablesXLSX(names []string, tables [][][]string) ([]byte, error) {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	for i, table := range tables {
+		if err := writeWorksheet(file, names[i], table); err != nil {
+			return nil, err
+		}
+	}
+	if err := file.DeleteSheet("Sheet1"); err != nil {
+		return nil, err
+	}
+	buf, err := file.WriteToBuffer()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeWorksheet creates a worksheet and fills it row by row
+func writeWorksheet(file *excelize.File, name string, table [][]string) error {
+	if _, err := file.NewSheet(name); err != nil {
+		return err
+	}
+	for i, row := range table {
+		cells := make([]interface{}, len(row))
+		for j, value := range row {
+			cells[j] = value
+		}
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return err
+		}
+		if err := file.SetSheetRow(name, cell, &cells); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// worksheetName derives a unique, valid Excel worksheet name (max. 31 characters)
+func worksheetName(name string, used map[string]struct{}) string {
+	name = strings.NewReplacer(":", " ", "\\", " ", "/", " ", "?", " ", "*", " ", "[", "(", "]", ")").Replace(name)
+	runes := []rune(name)
+	if len(runes) > 31 {
+		runes = runes[:31]
+	}
+	candidate := strings.TrimSpace(string(runes))
+	for i := 2; ; i++ {
+		if _, exists := used[candidate]; !exists {
+			break
+		}
+		suffix := fmt.Sprintf(" (%d)", i)
+		base := []rune(strings.TrimSpace(string(runes)))
+		if len(base)+len(suffix) > 31 {
+			base = base[:31-len(suffix)]
+		}
+		candidate = string(base) + suffix
+	}
+	used[candidate] = struct{}{}
+	return candidate
+}
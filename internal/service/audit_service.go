@@ -0,0 +1,141 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"azh/internal/model"
+	"azh/internal/repository"
+)
+
+// AuditEntryDTO represents an audit entry with its values decoded
+type AuditEntryDTO struct {
+	ID        uint            `json:"id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Actor     string          `json:"actor"`
+	Action    string          `json:"action"`
+	Entity    string          `json:"entity"`
+	EntityID  string          `json:"entity_id"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+}
+
+// AuditListener is called with audit entries after they were stored
+type AuditListener func(entries []model.AuditEntry)
+
+// AuditService records who changed which data and lets administrators query the log. As every
+// change of application data is audited, listeners use the stored entries as a change feed.
+type AuditService struct {
+	auditRepo *repository.AuditRepository
+	listeners []AuditListener
+}
+
+// NewAuditService creates a new AuditService
+func NewAuditService(auditRepo *repository.AuditRepository) *AuditService {
+	return &AuditService{auditRepo: auditRepo}
+}
+
+// Record appends a change to the audit log. Before and after are encoded as JSON; nil stands for
+// an entity that did not exist before or no longer exists after the change.
+func (s *AuditService) Record(actor, action, entity, entityID string, before, after any) error {
+	entry, err := newAuditEntry(actor, action, entity, entityID, before, after)
+	if err != nil {
+		return err
+	}
+	return s.RecordAll([]model.AuditEntry{entry})
+}
+
+// RecordAll appends several changes to the audit log at once
+func (s *AuditService) RecordAll(entries []model.AuditEntry) error {
+	if err := s.auditRepo.Create(entries); err != nil {
+		return err
+	}
+	s.Publish(entries)
+	return nil
+}
+
+// Subscribe registers a listener for stored audit entries. Listeners run in the request that made
+// the change, so they must not block. Subscribe is not safe for concurrent use and must be called
+// during startup.
+func (s *AuditService) Subscribe(listener AuditListener) {
+	s.listeners = append(s.listeners, listener)
+}
+
+// Publish passes audit entries to the listeners. Record and RecordAll publish on their own; entries
+// stored in the same transaction as the change itself are published once it is committed.
+func (s *AuditService) Publish(entries []model.AuditEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	for _, listener := range s.listeners {
+		listener(entries)
+	}
+}
+
+// GetEntries retrieves the audit entries matching a filter, newest first
+func (s *AuditService) GetEntries(filter repository.AuditFilter) ([]AuditEntryDTO, error) {
+	entries, err := s.auditRepo.Find(filter)
+	if err != nil {
+		return nil, err
+	}
+	dtos := make([]AuditEntryDTO, 0, len(entries))
+	for _, entry := range entries {
+		dto := AuditEntryDTO{
+			ID:        entry.ID,
+			CreatedAt: entry.CreatedAt,
+			Actor:     entry.Actor,
+			Action:    entry.Action,
+			Entity:    entry.Entity,
+			EntityID:  entry.EntityID,
+		}
+		if entry.Before != "" {
+			dto.Before = json.RawMessage(entry.Before)
+		}
+		if entry.After != "" {
+			dto.After = json.RawMessage(entry.After)
+		}
+		dtos = append(dtos, dto)
+	}
+	return dtos, nil
+}
+
+// newAuditEntry builds an audit entry with the values encoded as JSON
+func newAuditEntry(actor, action, entity, entityID string, before, after any) (model.AuditEntry, error) {
+	entry := model.AuditEntry{Actor: actor, Action: action, Entity: entity, EntityID: entityID}
+	if before != nil {
+		encoded, err := json.Marshal(before)
+		if err != nil {
+			return entry, fmt.Errorf("error encoding audit values: %v", err)
+		}
+		entry.Before = string(encoded)
+	}
+	if after != nil {
+		encoded, err := json.Marshal(after)
+		if err != nil {
+			return entry, fmt.Errorf("error encoding audit values: %v", err)
+		}
+		entry.After = string(encoded)
+	}
+	return entry, nil
+}
+
+// participationAuditID identifies a member's attendance at a session in the audit log
+func participationAuditID(courseID uint, date time.Time, memberID uint) string {
+	return fmt.Sprintf("%d/%s/%d", courseID, date.Format("2006-01-02"), memberID)
+}
+
+// sessionAuditID identifies a session of a course in the audit log
+func sessionAuditID(courseID uint, date time.Time) string {
+	return fmt.Sprintf("%d/%s", courseID, date.Format("2006-01-02"))
+}
+
+// enrollmentAuditID identifies a member's enrollment in a course in the audit log
+func enrollmentAuditID(courseID, memberID uint) string {
+	return fmt.Sprintf("%d/%d", courseID, memberID)
+}
+
+// attendanceAuditValue is the audited value of an attendance status
+func attendanceAuditValue(status string) map[string]string {
+	return map[string]string{"status": status}
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"azh/internal/model"
+	"azh/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ErrCalendarFeedNotFound is returned for unknown or revoked feed tokens
+var ErrCalendarFeedNotFound = errors.New("calendar feed not found")
+
+// Range of sessions included in calendar feeds, relative to today
+const (
+	calendarPastDays   = 28
+	calendarFutureDays = 180
+)
+
+// CalendarFeedDTO represents the secret URL of a calendar feed
+type CalendarFeedDTO struct {
+	Kind      string `json:"kind"`
+	SubjectID uint   `json:"subject_id"`
+	URL       string `json:"url"`
+}
+
+// CalendarService renders iCalendar feeds of the sessions of a course, a trainer or the whole club.
+// Every session is a separate event with a UID derived from course and date, so calendar apps
+// update moved sessions in place and drop sessions that disappear from a feed.
+type CalendarService struct {
+	courseRepo     *repository.CourseRepository
+	sessionRepo    *repository.SessionRepository
+	locationRepo   *repository.LocationRepository
+	feedRepo       *repository.CalendarFeedRepository
+	trainerService *TrainerService
+	club           ClubInfo
+	publicURL      string
+	timezone       *time.Location
+}
+
+// NewCalendarService creates a new CalendarService
+func NewCalendarService(
+	courseRepo *repository.CourseRepository,
+	sessionRepo *repository.SessionRepository,
+	locationRepo *repository.LocationRepository,
+	feedRepo *repository.CalendarFeedRepository,
+	trainerService *TrainerService,
+	club ClubInfo,
+	publicURL string,
+	timezone *time.Location,
+) *CalendarService {
+	return &CalendarService{
+		courseRepo:     courseRepo,
+		sessionRepo:    sessionRepo,
+		locationRepo:   locationRepo,
+		feedRepo:       feedRepo,
+		trainerService: trainerService,
+		club:           club,
+		publicURL:      strings.TrimSuffix(publicURL, "/"),
+		timezone:       timezone,
+	}
+}
+
+// GetFeed returns the URL of the feed of a course, a trainer or, with subject 0, the club and
+// creates the feed on first use. With rotate, the feed gets a new token and the old URL stops working.
+func (s *CalendarService) GetFeed(kind string, subjectID uint, rotate bool) (CalendarFeedDTO, error) {
+	switch kind {
+	case model.CalendarFeedCourse:
+		if _, err := getCourse(s.courseRepo, subjectID); err != nil {
+			return CalendarFeedDTO{}, err
+		}
+	case model.CalendarFeedTrainer:
+		if _, err := s.trainerService.getTrainer(subjectID); err != nil {
+			return CalendarFeedDTO{}, err
+		}
+	default:
+		kind, subjectID = model.CalendarFeedClub, 0
+	}
+
+	feed, err := s.feedRepo.Get(kind, subjectID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return CalendarFeedDTO{}, err
+	}
+	if feed.Token == "" || rotate {
+		feed.Kind, feed.SubjectID = kind, subjectID
+		if feed.Token, err = newToken(); err != nil {
+			return CalendarFeedDTO{}, err
+		}
+		if err := s.feedRepo.Save(&feed); err != nil {
+			return CalendarFeedDTO{}, err
+		}
+	}
+	return CalendarFeedDTO{
+		Kind:      feed.Kind,
+		SubjectID: feed.SubjectID,
+		URL:       fmt.Sprintf("%s/calendar/%s.ics", s.publicURL, feed.Token),
+	}, nil
+}
+
+// Render renders the feed with the given token as iCalendar data, covering the sessions of the
+// past four weeks and the next six months
+func (s *CalendarService) Render(token string, now time.Time) ([]byte, error) {
+	feed, err := s.feedRepo.GetByToken(token)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCalendarFeedNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	now = now.In(s.timezone)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	from, to := today.AddDate(0, 0, -calendarPastDays), today.AddDate(0, 0, calendarFutureDays)
+	minDate, maxDate := from.Format("2006-01-02"), to.Format("2006-01-02")
+
+	var courses []model.Course
+	name := s.club.Name
+	switch feed.Kind {
+	case model.CalendarFeedCourse:
+		courses, err = s.courseRepo.GetByIDs([]uint{feed.SubjectID})
+		if len(courses) > 0 {
+			name = s.club.Name + ": " + courses[0].Name
+		}
+	case model.CalendarFeedTrainer:
+		var trainer model.Trainer
+		if trainer, err = s.trainerService.getTrainer(feed.SubjectID); err == nil {
+			name = s.club.Name + ": " + trainer.Name
+			courses, err = s.courseRepo.GetActiveBetween(minDate, maxDate)
+		}
+	default:
+		courses, err = s.courseRepo.GetActiveBetween(minDate, maxDate)
+	}
+	if err != nil && !errors.Is(err, ErrTrainerNotFound) {
+		return nil, err
+	}
+
+	courseIDs := make([]uint, 0, len(courses))
+	for _, course := range courses {
+		courseIDs = append(courseIDs, course.ID)
+	}
+	assignments, err := s.trainerService.loadAssignments(courseIDs, minDate, maxDate)
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.sessionRepo.GetInRange(minDate, maxDate)
+	if err != nil {
+		return nil, err
+	}
+	sessions := make(map[string]model.Session, len(records))
+	for _, record := range records {
+		sessions[sessionKey(record.CourseID, record.Date)] = record
+	}
+	locations, err := s.locationRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect the sessions of the feed with their trainers
+	type calendarSession struct {
+		course   model.Course
+		date     time.Time
+		trainers []uint
+	}
+	var entries []calendarSession
+	var trainerIDs []uint
+	for _, course := range courses {
+		for _, dateStr := range scheduledDates(course, from, to) {
+			date, _ := time.Parse("2006-01-02", dateStr)
+			assigned, _ := assignments.forSession(course.ID, date)
+			ids := assignedTrainerIDs(assigned)
+			if feed.Kind == model.CalendarFeedTrainer && !slices.Contains(ids, feed.SubjectID) {
+				continue
+			}
+			entries = append(entries, calendarSession{course: course, date: date, trainers: ids})
+			trainerIDs = append(trainerIDs, ids...)
+		}
+	}
+	trainers, err := s.trainerService.trainersByID(trainerIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var w icsWriter
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", "-//"+icsText(s.club.Name)+"//Anwesenheit//DE")
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("METHOD", "PUBLISH")
+	w.line("X-WR-CALNAME", icsText(name))
+	w.line("REFRESH-INTERVAL;VALUE=DURATION", "PT1H")
+	w.line("X-PUBLISHED-TTL", "PT1H")
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, entry := range entries {
+		session := sessions[sessionKey(entry.course.ID, entry.date)]
+		names := make([]string, 0, len(entry.trainers))
+		for _, trainerID := range entry.trainers {
+			names = append(names, trainers[trainerID].Name)
+		}
+		modified := entry.course.UpdatedAt
+		if session.UpdatedAt.After(modified) {
+			modified = session.UpdatedAt
+		}
+
+		w.line("BEGIN", "VEVENT")
+		w.line("UID", calendarUID(entry.course.ID, entry.date))
+		w.line("DTSTAMP", stamp)
+		if !modified.IsZero() {
+			w.line("LAST-MODIFIED", modified.UTC().Format("20060102T150405Z"))
+		}
+		if start, end, ok := calendarTimes(entry.course, session, entry.date, s.timezone); ok {
+			w.line("DTSTART", start.UTC().Format("20060102T150405Z"))
+			w.line("DTEND", end.UTC().Format("20060102T150405Z"))
+		} else {
+			w.line("DTSTART;VALUE=DATE", entry.date.Format("20060102"))
+		}
+		w.line("SUMMARY", icsText(entry.course.Name))
+		if location := courseLocationText(entry.course, locations); location != "" {
+			w.line("LOCATION", icsText(location))
+		}
+		// Cancelled sessions stay in the feed so subscribed calendars show the cancellation
+		if session.CancelledAt != nil {
+			w.line("DESCRIPTION", icsText("Abgesagt: "+session.CancelReason))
+			w.line("STATUS", "CANCELLED")
+		} else {
+			if len(names) > 0 {
+				w.line("DESCRIPTION", icsText("Trainer: "+strings.Join(names, ", ")))
+			}
+			w.line("STATUS", "CONFIRMED")
+		}
+		w.line("END", "VEVENT")
+	}
+	w.line("END", "VCALENDAR")
+	return w.buf.Bytes(), nil
+}
+
+// calendarUID identifies a session in calendar feeds; it must stay the same for the session's lifetime
+func calendarUID(courseID uint, date time.Time) string {
+	return fmt.Sprintf("course-%d-%s@azh", courseID, date.Format("2006-01-02"))
+}
+
+// calendarTimes returns the start and end of a session in the time zone of the club, preferring
+// the actual times recorded for the session over the scheduled ones
+func calendarTimes(course model.Course, session model.Session, date time.Time, timezone *time.Location) (time.Time, time.Time, bool) {
+	startTime, endTime := sessionTimes(course, date)
+	if session.StartTime != "" {
+		startTime = session.StartTime
+	}
+	if session.EndTime != "" {
+		endTime = session.EndTime
+	}
+	start, err := time.Parse("15:04", strings.TrimSpace(startTime))
+	if err != nil {
+		return start, start, false
+	}
+	end, err := time.Parse("15:04", strings.TrimSpace(endTime))
+	if err != nil || !end.After(start) {
+		return start, end, false
+	}
+	return atClock(date, start, timezone), atClock(date, end, timezone), true
+}
+
+// courseLocationText describes where a course takes place, using its location and room if it is
+// linked to one and its free-text location otherwise
+func courseLocationText(course model.Course, locations []model.Location) string {
+	if course.LocationID == nil {
+		return course.Location
+	}
+	for _, location := range locations {
+		if location.ID != *course.LocationID {
+			continue
+		}
+		parts := []string{location.Name}
+		for _, room := range location.Rooms {
+			if course.RoomID != nil && room.ID == *course.RoomID {
+				parts = append(parts, room.Name)
+			}
+		}
+		if location.Address != "" {
+			parts = append(parts, location.Address)
+		}
+		return strings.Join(parts, ", ")
+	}
+	return course.Location
+}
+
+// icsText escapes a value of an iCalendar text property
+func icsText(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+}
+
+// icsWriter writes iCalendar content lines, folded at 75 octets as RFC 5545 requires
+type icsWriter struct {
+	buf bytes.Buffer
+}
+
+// line writes a property; continuation lines start with a space, which counts towards their length
+func (w *icsWriter) line(name, value string) {
+	line := name + ":" + value
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.buf.WriteString(line[:cut])
+		w.buf.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74
+	}
+	w.buf.WriteString(line)
+	w.buf.WriteString("\r\n")
+}
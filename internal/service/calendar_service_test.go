@@ -0,0 +1,76 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"azh/internal/model"
+)
+
+func TestCalendarUID(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("loading time zone: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		courseID uint
+		date     time.Time
+		want     string
+	}{
+		{"course session", 12, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), "course-12-2026-10-19@azh"},
+		{"time of day ignored", 12, time.Date(2026, 10, 19, 23, 59, 0, 0, time.UTC), "course-12-2026-10-19@azh"},
+		{"date in club time zone", 12, time.Date(2026, 10, 19, 0, 30, 0, 0, berlin), "course-12-2026-10-19@azh"},
+		{"event session", model.EventIDOffset + 3, time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC), "course-1000003-2026-12-31@azh"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := calendarUID(tt.courseID, tt.date); got != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	date := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	if calendarUID(12, date) == calendarUID(13, date) || calendarUID(12, date) == calendarUID(12, date.AddDate(0, 0, 7)) {
+		t.Fatal("sessions of different courses or dates share a UID")
+	}
+}
+
+func TestCalendarTimes(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("loading time zone: %v", err)
+	}
+	course := model.Course{StartTime: "18:00", EndTime: "19:30"}
+
+	tests := []struct {
+		name      string
+		session   model.Session
+		date      time.Time
+		wantStart time.Time
+		wantEnd   time.Time
+		wantOK    bool
+	}{
+		{"summer time", model.Session{}, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+			time.Date(2026, 10, 19, 16, 0, 0, 0, time.UTC), time.Date(2026, 10, 19, 17, 30, 0, 0, time.UTC), true},
+		{"winter time", model.Session{}, time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC),
+			time.Date(2026, 11, 2, 17, 0, 0, 0, time.UTC), time.Date(2026, 11, 2, 18, 30, 0, 0, time.UTC), true},
+		{"recorded times", model.Session{StartTime: "18:15", EndTime: "20:00"}, time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC),
+			time.Date(2026, 11, 2, 17, 15, 0, 0, time.UTC), time.Date(2026, 11, 2, 19, 0, 0, 0, time.UTC), true},
+		{"end before start", model.Session{EndTime: "17:00"}, time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC),
+			time.Time{}, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, ok := calendarTimes(course, tt.session, tt.date, berlin)
+			if ok != tt.wantOK {
+				t.Fatalf("expected ok %v, got %v", tt.wantOK, ok)
+			}
+			if ok && (!start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd)) {
+				t.Fatalf("expected %v-%v, got %v-%v", tt.wantStart, tt.wantEnd, start, end)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"azh/internal/model"
+	"azh/internal/repository"
+	"github.com/skip2/go-qrcode"
+)
+
+// Errors returned by the self check-in
+var (
+	ErrInvalidToken     = errors.New("invalid check-in token")
+	ErrNotEnrolled      = errors.New("member not enrolled in course")
+	ErrOutsideTimeframe = errors.New("check-in outside the session time window")
+)
+
+// checkInTokenPrefix versions the token format so it can be changed later
+const checkInTokenPrefix = "azh1"
+
+// CheckInWindow configures how long before the start of a session the check-in opens; it closes
+// at the end of the session
+type CheckInWindow struct {
+	OpensBefore time.Duration
+}
+
+// CheckInDTO represents the result of a check-in
+type CheckInDTO struct {
+	MemberID         uint   `json:"member_id"`
+	FirstName        string `json:"first_name"`
+	Status           string `json:"status"`
+	AlreadyCheckedIn bool   `json:"already_checked_in"`
+	Credits          *int   `json:"credits,omitempty"`
+}
+
+// CheckInService handles the QR code self check-in of participants. The QR code holds a token
+// with the member ID signed by HMAC-SHA256, so it contains no personal data and cannot be forged.
+type CheckInService struct {
+	courseRepo           *repository.CourseRepository
+	memberRepo           *repository.MemberRepository
+	memberCourseRepo     *repository.MemberCourseRepository
+	participationService *ParticipationService
+	secret               []byte
+	window               CheckInWindow
+	timezone             *time.Location
+}
+
+// NewCheckInService creates a new CheckInService
+func NewCheckInService(
+	courseRepo *repository.CourseRepository,
+	memberRepo *repository.MemberRepository,
+	memberCourseRepo *repository.MemberCourseRepository,
+	participationService *ParticipationService,
+	secret []byte,
+	window CheckInWindow,
+	timezone *time.Location,
+) *CheckInService {
+	return &CheckInService{
+		courseRepo:           courseRepo,
+		memberRepo:           memberRepo,
+		memberCourseRepo:     memberCourseRepo,
+		participationService: participationService,
+		secret:               secret,
+		window:               window,
+		timezone:             timezone,
+	}
+}
+
+// QRCode renders the check-in QR code of a member as PNG
+func (s *CheckInService) QRCode(memberID uint, size int) ([]byte, error) {
+	members, err := s.memberRepo.GetByIDs([]uint{memberID})
+	if err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return nil, ErrMemberNotFound
+	}
+	return qrcode.Encode(s.Token(memberID), qrcode.Medium, size)
+}
+
+// Token creates the signed check-in token of a member
+func (s *CheckInService) Token(memberID uint) string {
+	payload := binary.BigEndian.AppendUint64(nil, uint64(memberID))
+	return checkInTokenPrefix + "." +
+		base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(s.sign(payload))
+}
+
+// CheckIn validates a scanned token against the enrolled members and the time window of the
+// session and marks the member present using the regular attendance logic
+func (s *CheckInService) CheckIn(courseID uint, date time.Time, token string, now time.Time) (CheckInDTO, error) {
+	memberID, err := s.parseToken(token)
+	if err != nil {
+		return CheckInDTO{}, err
+	}
+	course, err := getCourse(s.courseRepo, courseID)
+	if err != nil {
+		return CheckInDTO{}, err
+	}
+	if !s.withinWindow(course, date, now) {
+		return CheckInDTO{}, ErrOutsideTimeframe
+	}
+
+	members, err := s.memberRepo.GetByIDsAndDate([]uint{memberID}, date)
+	if err != nil {
+		return CheckInDTO{}, err
+	}
+	if len(members) == 0 {
+		return CheckInDTO{}, ErrNotEnrolled
+	}
+	enrolled, err := s.memberCourseRepo.Exists(memberID, courseID)
+	if err != nil {
+		return CheckInDTO{}, err
+	}
+	// Drop-in sessions are open to everyone paying with a punch card
+	if !enrolled && !course.DropIn {
+		return CheckInDTO{}, ErrNotEnrolled
+	}
+
+	participants, err := s.participationService.GetParticipants(strconv.FormatUint(uint64(courseID), 10), date.Format("2006-01-02"))
+	if err != nil {
+		return CheckInDTO{}, err
+	}
+	result := CheckInDTO{MemberID: memberID, FirstName: members[0].FirstName, Status: model.ParticipationStatusPresent}
+	for _, participant := range participants {
+		if participant.ID != memberID {
+			continue
+		}
+		result.Credits = participant.Credits
+		if participant.Status == model.ParticipationStatusPresent {
+			result.AlreadyCheckedIn = true
+			return result, nil
+		}
+	}
+	if err := s.participationService.SetAttendance(fmt.Sprintf("self check-in (member %d)", memberID), courseID, date, memberID, model.ParticipationStatusPresent); err != nil {
+		return CheckInDTO{}, err
+	}
+	// Drop-in visits of members not enrolled in the course use up a punch card credit
+	if result.Credits != nil && !enrolled {
+		credits := *result.Credits - 1
+		result.Credits = &credits
+	}
+	return result, nil
+}
+
+// withinWindow reports whether now lies between the opening of the check-in and the end of the
+// session on the given date; the course times are local times of the club
+func (s *CheckInService) withinWindow(course model.Course, date, now time.Time) bool {
+	if !slices.Contains(scheduledDates(course, date, date), date.Format("2006-01-02")) {
+		return false
+	}
+	start, end, ok := sessionClock(course, date)
+	if !ok {
+		return false
+	}
+	return !now.Before(atClock(date, start, s.timezone).Add(-s.window.OpensBefore)) && !now.After(atClock(date, end, s.timezone))
+}
+
+// parseToken verifies the signature of a check-in token and extracts the member ID
+func (s *CheckInService) parseToken(token string) (uint, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 || parts[0] != checkInTokenPrefix {
+		return 0, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || len(payload) != 8 {
+		return 0, ErrInvalidToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, s.sign(payload)) {
+		return 0, ErrInvalidToken
+	}
+	return uint(binary.BigEndian.Uint64(payload)), nil
+}
+
+// sign computes the truncated HMAC-SHA256 of a token payload
+func (s *CheckInService) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(checkInTokenPrefix))
+	mac.Write(payload)
+	return mac.Sum(nil)[:16]
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"azh/internal/model"
+)
+
+func newTestCheckInService(t *testing.T, secret string) *CheckInService {
+	t.Helper()
+	timezone, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("loading time zone: %v", err)
+	}
+	return NewCheckInService(nil, nil, nil, nil, []byte(secret), CheckInWindow{OpensBefore: 30 * time.Minute}, timezone)
+}
+
+func TestCheckInTokenRoundTrip(t *testing.T) {
+	s := newTestCheckInService(t, "secret")
+	for _, memberID := range []uint{1, 4711, model.RegisteredIDOffset + 1, model.GuestIDOffset} {
+		got, err := s.parseToken(s.Token(memberID))
+		if err != nil {
+			t.Fatalf("token of member %d rejected: %v", memberID, err)
+		}
+		if got != memberID {
+			t.Fatalf("expected member %d, got %d", memberID, got)
+		}
+	}
+}
+
+func TestCheckInTokenRejected(t *testing.T) {
+	s := newTestCheckInService(t, "secret")
+	token := s.Token(4711)
+	parts := strings.Split(token, ".")
+	other := s.Token(4712)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"empty", ""},
+		{"signed with another secret", newTestCheckInService(t, "other").Token(4711)},
+		{"other prefix", "azh0." + parts[1] + "." + parts[2]},
+		{"missing signature", parts[0] + "." + parts[1]},
+		{"signature of another member", parts[0] + "." + parts[1] + "." + strings.Split(other, ".")[2]},
+		{"changed member ID", parts[0] + "." + strings.Split(other, ".")[1] + "." + parts[2]},
+		{"invalid encoding", parts[0] + ".!!!." + parts[2]},
+		{"truncated signature", parts[0] + "." + parts[1] + "." + parts[2][:10]},
+		{"extra part", token + ".x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.parseToken(tt.token); !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("expected ErrInvalidToken, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckInWindow(t *testing.T) {
+	s := newTestCheckInService(t, "secret")
+	course := model.Course{Weekday: "Montag", StartTime: "18:00", EndTime: "19:30"}
+	monday := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	// Berlin is UTC+2 until the end of October 2026
+	local := func(hour, minute int) time.Time {
+		return time.Date(2026, 10, 19, hour, minute, 0, 0, time.UTC).Add(-2 * time.Hour)
+	}
+
+	tests := []struct {
+		name string
+		date time.Time
+		now  time.Time
+		want bool
+	}{
+		{"before the window opens", monday, local(17, 29), false},
+		{"window opens", monday, local(17, 30), true},
+		{"during the session", monday, local(18, 45), true},
+		{"end of the session", monday, local(19, 30), true},
+		{"after the session", monday, local(19, 31), false},
+		{"session time read as UTC", monday, time.Date(2026, 10, 19, 19, 0, 0, 0, time.UTC), false},
+		{"day without session", monday.AddDate(0, 0, 1), local(18, 0).AddDate(0, 0, 1), false},
+		{"token of last week", monday.AddDate(0, 0, -7), local(18, 0), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.withinWindow(course, tt.date, tt.now); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"azh/internal/mail"
+	"azh/internal/model"
+	"azh/internal/repository"
+)
+
+// ChurnCriteria configures when a member is considered at risk of dropping out
+type ChurnCriteria struct {
+	MissedSessions int     `json:"missed_sessions"`
+	MinRate        float64 `json:"min_rate"`
+	MaxDrop        float64 `json:"max_drop"`
+	Weeks          int     `json:"weeks"`
+}
+
+// AtRiskMember represents an enrolled member who stopped coming regularly
+type AtRiskMember struct {
+	MemberID     uint     `json:"member_id"`
+	FirstName    string   `json:"first_name"`
+	LastName     string   `json:"last_name"`
+	Email        string   `json:"email"`
+	Phone        string   `json:"phone"`
+	CourseID     uint     `json:"course_id"`
+	MissedInARow int      `json:"missed_in_a_row"`
+	RecentRate   float64  `json:"recent_rate"`
+	PreviousRate *float64 `json:"previous_rate"`
+	Reasons      []string `json:"reasons"`
+}
+
+// ChurnService detects members at risk of cancelling and reports them to trainers and the office
+type ChurnService struct {
+	courseRepo  *repository.CourseRepository
+	memberRepo  *repository.MemberRepository
+	statsRepo   *repository.StatsRepository
+	trainerRepo *repository.TrainerRepository
+	mailer      *mail.Mailer
+	officeEmail string
+	criteria    ChurnCriteria
+}
+
+// NewChurnService creates a new ChurnService
+func NewChurnService(
+	courseRepo *repository.CourseRepository,
+	memberRepo *repository.MemberRepository,
+	statsRepo *repository.StatsRepository,
+	trainerRepo *repository.TrainerRepository,
+	mailer *mail.Mailer,
+	officeEmail string,
+	criteria ChurnCriteria,
+) *ChurnService {
+	return &ChurnService{
+		courseRepo:  courseRepo,
+		memberRepo:  memberRepo,
+		statsRepo:   statsRepo,
+		trainerRepo: trainerRepo,
+		mailer:      mailer,
+		officeEmail: officeEmail,
+		criteria:    criteria,
+	}
+}
+
+// DefaultCriteria returns the configured detection criteria
+func (s *ChurnService) DefaultCriteria() ChurnCriteria {
+	return s.criteria
+}
+
+// GetAtRiskMembers flags members of a course who missed the last sessions in a row, whose
+// attendance rate within the last weeks fell below the threshold or dropped by at least MaxDrop
+// compared to the weeks before. The rate is only checked for members who were expected at least
+// MissedSessions times, so new members are not flagged early.
+func (s *ChurnService) GetAtRiskMembers(courseID uint, criteria ChurnCriteria, referenceDate time.Time) ([]AtRiskMember, error) {
+	recentStart := referenceDate.AddDate(0, 0, -7*criteria.Weeks)
+	recent := repository.StatsFilter{
+		MinDate:  recentStart.Format("2006-01-02"),
+		MaxDate:  referenceDate.Format("2006-01-02"),
+		CourseID: courseID,
+	}
+	previous := repository.StatsFilter{
+		MinDate:  recentStart.AddDate(0, 0, -7*criteria.Weeks).Format("2006-01-02"),
+		MaxDate:  recentStart.AddDate(0, 0, -1).Format("2006-01-02"),
+		CourseID: courseID,
+	}
+
+	recentStats, err := s.statsRepo.GetMemberStats(recent)
+	if err != nil {
+		return nil, err
+	}
+	previousStats, err := s.statsRepo.GetMemberStats(previous)
+	if err != nil {
+		return nil, err
+	}
+	streaks, err := s.statsRepo.GetStreaks(recent)
+	if err != nil {
+		return nil, err
+	}
+
+	previousRates := make(map[uint]float64)
+	for _, stat := range previousStats {
+		previousRates[stat.MemberID] = stat.Rate
+	}
+	missed := make(map[uint]int)
+	for _, streak := range streaks {
+		missed[streak.MemberID] = streak.MissedInARow
+	}
+
+	candidates := make(map[uint]AtRiskMember)
+	var candidateIDs []uint
+	for _, stat := range recentStats {
+		var reasons []string
+		if missed[stat.MemberID] >= criteria.MissedSessions {
+			reasons = append(reasons, fmt.Sprintf("%d Termine in Folge gefehlt", missed[stat.MemberID]))
+		}
+		if stat.Sessions >= criteria.MissedSessions && stat.Rate < criteria.MinRate {
+			reasons = append(reasons, fmt.Sprintf("Anwesenheit %.0f%% unter %.0f%%", stat.Rate*100, criteria.MinRate*100))
+		}
+		previousRate, hasPrevious := previousRates[stat.MemberID]
+		if hasPrevious && criteria.MaxDrop > 0 && stat.Sessions >= criteria.MissedSessions && previousRate-stat.Rate >= criteria.MaxDrop {
+			reasons = append(reasons, fmt.Sprintf("Anwesenheit von %.0f%% auf %.0f%% gefallen", previousRate*100, stat.Rate*100))
+		}
+		if len(reasons) == 0 {
+			continue
+		}
+		candidate := AtRiskMember{
+			MemberID:     stat.MemberID,
+			FirstName:    stat.FirstName,
+			LastName:     stat.LastName,
+			CourseID:     stat.CourseID,
+			MissedInARow: missed[stat.MemberID],
+			RecentRate:   stat.Rate,
+			Reasons:      reasons,
+		}
+		if hasPrevious {
+			candidate.PreviousRate = &previousRate
+		}
+		candidates[stat.MemberID] = candidate
+		candidateIDs = append(candidateIDs, stat.MemberID)
+	}
+	if len(candidateIDs) == 0 {
+		return []AtRiskMember{}, nil
+	}
+
+	// Only report members who are still enrolled today
+	members, err := s.memberRepo.GetByIDsAndDate(candidateIDs, referenceDate)
+	if err != nil {
+		return nil, err
+	}
+	atRisk := make([]AtRiskMember, 0, len(members))
+	for _, member := range members {
+		candidate := candidates[member.ID]
+		candidate.Email = member.Email
+		candidate.Phone = member.Phone
+		atRisk = append(atRisk, candidate)
+	}
+	return atRisk, nil
+}
+
+// SendDigest mails the at-risk members of every active course to its trainers and the office
+func (s *ChurnService) SendDigest(referenceDate time.Time) error {
+	courses, err := s.courseRepo.GetAll()
+	if err != nil {
+		return err
+	}
+	for _, course := range courses {
+		if course.IsEvent() {
+			continue
+		}
+		atRisk, err := s.GetAtRiskMembers(course.ID, s.criteria, referenceDate)
+		if err != nil {
+			return fmt.Errorf("error detecting at-risk members of course %d: %v", course.ID, err)
+		}
+		if len(atRisk) == 0 {
+			continue
+		}
+		recipients, err := s.trainerEmails(course.ID)
+		if err != nil {
+			return fmt.Errorf("error loading trainers of course %d: %v", course.ID, err)
+		}
+		if len(recipients) == 0 {
+			log.Printf("No trainer of course %d (%s) has an email address for the churn digest", course.ID, course.Name)
+		}
+		if s.officeEmail != "" {
+			recipients = append(recipients, s.officeEmail)
+		}
+		if len(recipients) == 0 {
+			log.Printf("Churn digest for course %d (%s) not sent: no recipient", course.ID, course.Name)
+			continue
+		}
+		subject := fmt.Sprintf("Wochenübersicht: %d Teilnehmende in %s kommen nicht mehr regelmäßig", len(atRisk), course.Name)
+		if err := s.mailer.Send(recipients, subject, formatDigest(course, atRisk)); err != nil {
+			return fmt.Errorf("error sending digest for course %d: %v", course.ID, err)
+		}
+	}
+	return nil
+}
+
+// StartWeeklyDigest sends the digest every week on the given weekday and hour in the background
+func (s *ChurnService) StartWeeklyDigest(weekday time.Weekday, hour int) {
+	go func() {
+		for {
+			next := nextWeeklyRun(time.Now(), weekday, hour)
+			time.Sleep(time.Until(next))
+			if err := s.SendDigest(next); err != nil {
+				log.Printf("Failed to send churn digest: %v", err)
+			}
+		}
+	}()
+}
+
+// nextWeeklyRun computes the next point in time on the given weekday and hour after now
+func nextWeeklyRun(now time.Time, weekday time.Weekday, hour int) time.Time {
+	daysToAdd := int(weekday) - int(now.Weekday())
+	if daysToAdd < 0 {
+		daysToAdd += 7
+	}
+	next := time.Date(now.Year(), now.Month(), now.Day()+daysToAdd, hour, 0, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 7)
+	}
+	return next
+}
+
+// formatDigest renders the plain-text digest for a course
+func formatDigest(course model.Course, atRisk []AtRiskMember) string {
+	var body strings.Builder
+	fmt.Fprintf(&body, "Hallo,\n\nfolgende Teilnehmende aus %s (%s, %s %s-%s) sind in letzter Zeit nicht mehr regelmäßig gekommen:\n\n",
+		course.Name, course.Location, course.Weekday, course.StartTime, course.EndTime)
+	for _, member := range atRisk {
+		fmt.Fprintf(&body, "- %s %s (Tel. %s, %s): %s\n",
+			member.FirstName, member.LastName, member.Phone, member.Email, strings.Join(member.Reasons, ", "))
+	}
+	body.WriteString("\nVielleicht lohnt sich eine kurze Nachfrage.\n")
+	return body.String()
+}
+
+// trainerEmails retrieves the email addresses of the regular trainers of a course
+func (s *ChurnService) trainerEmails(courseID uint) ([]string, error) {
+	links, err := s.trainerRepo.GetCourseTrainers([]uint{courseID})
+	if err != nil || len(links) == 0 {
+		return nil, err
+	}
+	trainerIDs := make([]uint, 0, len(links))
+	for _, link := range links {
+		trainerIDs = append(trainerIDs, link.TrainerID)
+	}
+	trainers, err := s.trainerRepo.GetByIDs(trainerIDs)
+	if err != nil {
+		return nil, err
+	}
+	var emails []string
+	for _, trainer := range trainers {
+		if trainer.Email != "" {
+			emails = append(emails, trainer.Email)
+		}
+	}
+	return emails, nil
+}
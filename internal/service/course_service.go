@@ -1,11 +1,18 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"azh/internal/model"
 	"azh/internal/repository"
-	"time"
+	"gorm.io/gorm"
 )
 
+// ErrCourseNotFound is returned when a requested course does not exist
+var ErrCourseNotFound = errors.New("course not found")
+
 // CourseService handles business logic for courses
 type CourseService struct {
 	courseRepo *repository.CourseRepository
@@ -21,26 +28,69 @@ func (s *CourseService) GetCourses() ([]model.Course, error) {
 	return s.courseRepo.GetAll()
 }
 
-// GetOccurrences calculates the previous, current, and next occurrence of a course
+// GetOccurrences calculates the previous, current, and next occurrence of a course; for events it
+// returns all event dates
 func (s *CourseService) GetOccurrences(courseID string, referenceDate time.Time) ([]string, error) {
 	course, err := s.courseRepo.GetByID(courseID)
 	if err != nil {
 		return nil, err
 	}
+	if course.IsEvent() {
+		occurrences := make([]string, 0, len(course.EventDates))
+		for _, eventDate := range course.EventDates {
+			occurrences = append(occurrences, eventDate.Date.Format("2006-01-02"))
+		}
+		return occurrences, nil
+	}
 	return calculateOccurrences(course.Weekday, referenceDate), nil
 }
 
+// weekdayMap maps the German weekday names used in course data to time.Weekday
+var weekdayMap = map[string]time.Weekday{
+	"Montag":     time.Monday,
+	"Dienstag":   time.Tuesday,
+	"Mittwoch":   time.Wednesday,
+	"Donnerstag": time.Thursday,
+	"Freitag":    time.Friday,
+	"Samstag":    time.Saturday,
+	"Sonntag":    time.Sunday,
+}
+
+// scheduledDates lists all dates between from and to (inclusive) on which the course takes place
+// according to its weekday and its first and last schedule, or its event dates
+func scheduledDates(course model.Course, from, to time.Time) []string {
+	if course.IsEvent() {
+		var dates []string
+		for _, eventDate := range course.EventDates {
+			if !eventDate.Date.Before(from) && !eventDate.Date.After(to) {
+				dates = append(dates, eventDate.Date.Format("2006-01-02"))
+			}
+		}
+		return dates
+	}
+	weekday, ok := weekdayMap[course.Weekday]
+	if !ok {
+		return nil
+	}
+	if !course.FirstSchedule.IsZero() && course.FirstSchedule.After(from) {
+		from = course.FirstSchedule
+	}
+	if !course.LastSchedule.IsZero() && course.LastSchedule.Before(to) {
+		to = course.LastSchedule
+	}
+	daysToAdd := int(weekday) - int(from.Weekday())
+	if daysToAdd < 0 {
+		daysToAdd += 7
+	}
+	var dates []string
+	for day := from.AddDate(0, 0, daysToAdd); !day.After(to); day = day.AddDate(0, 0, 7) {
+		dates = append(dates, day.Format("2006-01-02"))
+	}
+	return dates
+}
+
 // calculateOccurrences computes the three occurrences based on weekday
 func calculateOccurrences(courseWeekday string, referenceDate time.Time) []string {
-	weekdayMap := map[string]time.Weekday{
-		"Montag":     time.Monday,
-		"Dienstag":   time.Tuesday,
-		"Mittwoch":   time.Wednesday,
-		"Donnerstag": time.Thursday,
-		"Freitag":    time.Friday,
-		"Samstag":    time.Saturday,
-		"Sonntag":    time.Sunday,
-	}
 	courseWeekdayInt := weekdayMap[courseWeekday]
 	currentWeekdayInt := referenceDate.Weekday()
 	daysToAdd := int(courseWeekdayInt) - int(currentWeekdayInt)
@@ -56,3 +106,12 @@ func calculateOccurrences(courseWeekday string, referenceDate time.Time) []strin
 		next.Format("2006-01-02"),
 	}
 }
+
+// getCourse retrieves a course by ID and maps a missing record to ErrCourseNotFound
+func getCourse(courseRepo *repository.CourseRepository, courseID uint) (model.Course, error) {
+	course, err := courseRepo.GetByID(fmt.Sprintf("%d", courseID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return course, ErrCourseNotFound
+	}
+	return course, err
+}
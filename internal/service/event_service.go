@@ -0,0 +1,378 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"azh/internal/model"
+	"azh/internal/repository"
+)
+
+// Errors returned when managing events
+var (
+	ErrEventNotFound = errors.New("event not found")
+	ErrInvalidEvent  = errors.New("invalid event")
+	ErrEventFull     = errors.New("event is fully booked")
+)
+
+// EventDateRequest holds a date of an event with its times (HH:MM)
+type EventDateRequest struct {
+	Date      string `json:"date"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
+
+// EventRequest holds the data to create or update an event
+type EventRequest struct {
+	Name            string             `json:"name"`
+	Location        string             `json:"location"`
+	TrainingType    string             `json:"training_type"`
+	Description     string             `json:"description"`
+	TrainerNames    string             `json:"trainer_names"`
+	Fee             float64            `json:"fee"`
+	MaxParticipants int                `json:"max_participants"`
+	Dates           []EventDateRequest `json:"dates"`
+}
+
+// EventRegistrationRequest registers either an existing member by ID or a guest by name and contact
+type EventRegistrationRequest struct {
+	MemberID  *uint  `json:"member_id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+	Phone     string `json:"phone"`
+	Age       int    `json:"age"`
+	Notes     string `json:"notes"`
+}
+
+// EventRegistrationDTO represents a participant registered for an event
+type EventRegistrationDTO struct {
+	MemberID     uint       `json:"member_id"`
+	FirstName    string     `json:"first_name"`
+	LastName     string     `json:"last_name"`
+	Email        string     `json:"email"`
+	Phone        string     `json:"phone"`
+	Guest        bool       `json:"guest"`
+	Fee          float64    `json:"fee"`
+	PaidAt       *time.Time `json:"paid_at"`
+	RegisteredAt string     `json:"registered_at"`
+	Notes        string     `json:"notes"`
+}
+
+// EventService handles one-off events and their registration lists. Events are stored as courses
+// of kind event, so attendance, statistics and exports work the same as for weekly courses.
+type EventService struct {
+	courseRepo            *repository.CourseRepository
+	memberRepo            *repository.MemberRepository
+	memberCourseRepo      *repository.MemberCourseRepository
+	eventRegistrationRepo *repository.EventRegistrationRepository
+	trainerService        *TrainerService
+	locationService       *LocationService
+	auditService          *AuditService
+}
+
+// NewEventService creates a new EventService
+func NewEventService(
+	courseRepo *repository.CourseRepository,
+	memberRepo *repository.MemberRepository,
+	memberCourseRepo *repository.MemberCourseRepository,
+	eventRegistrationRepo *repository.EventRegistrationRepository,
+	trainerService *TrainerService,
+	locationService *LocationService,
+	auditService *AuditService,
+) *EventService {
+	return &EventService{
+		courseRepo:            courseRepo,
+		memberRepo:            memberRepo,
+		memberCourseRepo:      memberCourseRepo,
+		eventRegistrationRepo: eventRegistrationRepo,
+		trainerService:        trainerService,
+		locationService:       locationService,
+		auditService:          auditService,
+	}
+}
+
+// GetEvents retrieves all events, the latest first
+func (s *EventService) GetEvents() ([]model.Course, error) {
+	courses, err := s.courseRepo.GetAllUnfiltered()
+	if err != nil {
+		return nil, err
+	}
+	events := make([]model.Course, 0)
+	for _, course := range courses {
+		if course.IsEvent() {
+			events = append(events, course)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].FirstSchedule.After(events[j].FirstSchedule) })
+	return events, nil
+}
+
+// CreateEvent creates an event with its dates
+func (s *EventService) CreateEvent(actor string, req EventRequest) (model.Course, error) {
+	event := model.Course{Kind: model.CourseKindEvent}
+	if err := applyEventRequest(&event, req); err != nil {
+		return event, err
+	}
+	if err := s.courseRepo.CreateEvent(&event); err != nil {
+		return event, err
+	}
+	if err := s.auditService.Record(actor, model.AuditActionCreate, model.AuditEntityCourse, fmt.Sprint(event.ID), nil, event); err != nil {
+		return event, err
+	}
+	return event, s.normalizeEvent(event)
+}
+
+// UpdateEvent updates an event and replaces its dates
+func (s *EventService) UpdateEvent(actor string, id uint, req EventRequest) (model.Course, error) {
+	event, err := s.getEvent(id)
+	if err != nil {
+		return event, err
+	}
+	before := event
+	before.EventDates = slices.Clone(event.EventDates)
+	if err := applyEventRequest(&event, req); err != nil {
+		return event, err
+	}
+	if err := s.courseRepo.UpdateEvent(&event); err != nil {
+		return event, err
+	}
+	if err := s.auditService.Record(actor, model.AuditActionUpdate, model.AuditEntityCourse, fmt.Sprint(event.ID), before, event); err != nil {
+		return event, err
+	}
+	return event, s.normalizeEvent(event)
+}
+
+// normalizeEvent links an event to its trainers and location
+func (s *EventService) normalizeEvent(event model.Course) error {
+	if err := s.trainerService.NormalizeCourseTrainers(event); err != nil {
+		return err
+	}
+	_, err := s.locationService.NormalizeCourseLocation(event)
+	return err
+}
+
+// GetRegistrations retrieves the registration list of an event
+func (s *EventService) GetRegistrations(id uint) ([]EventRegistrationDTO, error) {
+	if _, err := s.getEvent(id); err != nil {
+		return nil, err
+	}
+	registrations, err := s.eventRegistrationRepo.GetByCourseID(id)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]EventRegistrationDTO, 0, len(registrations))
+	if len(registrations) == 0 {
+		return result, nil
+	}
+	memberIDs := make([]uint, 0, len(registrations))
+	for _, registration := range registrations {
+		memberIDs = append(memberIDs, registration.MemberID)
+	}
+	members, err := s.memberRepo.GetByIDs(memberIDs)
+	if err != nil {
+		return nil, err
+	}
+	membersByID := make(map[uint]model.Member)
+	for _, member := range members {
+		membersByID[member.ID] = member
+	}
+	for _, registration := range registrations {
+		member := membersByID[registration.MemberID]
+		result = append(result, EventRegistrationDTO{
+			MemberID:     registration.MemberID,
+			FirstName:    member.FirstName,
+			LastName:     member.LastName,
+			Email:        member.Email,
+			Phone:        member.Phone,
+			Guest:        member.Guest,
+			Fee:          registration.Fee,
+			PaidAt:       registration.PaidAt,
+			RegisteredAt: registration.CreatedAt.Format("2006-01-02"),
+			Notes:        registration.Notes,
+		})
+	}
+	return result, nil
+}
+
+// Register adds a member, or a guest who is not a club member, to the registration list of an event
+func (s *EventService) Register(actor string, id uint, req EventRegistrationRequest, referenceDate time.Time) (EventRegistrationDTO, error) {
+	event, err := s.getEvent(id)
+	if err != nil {
+		return EventRegistrationDTO{}, err
+	}
+
+	var member model.Member
+	if req.MemberID != nil {
+		members, err := s.memberRepo.GetByIDs([]uint{*req.MemberID})
+		if err != nil {
+			return EventRegistrationDTO{}, err
+		}
+		if len(members) == 0 {
+			return EventRegistrationDTO{}, ErrMemberNotFound
+		}
+		member = members[0]
+		registered, err := s.memberCourseRepo.Exists(member.ID, event.ID)
+		if err != nil {
+			return EventRegistrationDTO{}, err
+		}
+		if registered {
+			return EventRegistrationDTO{}, ErrAlreadyEnrolled
+		}
+	} else if strings.TrimSpace(req.FirstName) == "" || strings.TrimSpace(req.LastName) == "" {
+		return EventRegistrationDTO{}, fmt.Errorf("%w: member ID or guest name required", ErrInvalidEvent)
+	}
+
+	registration := model.EventRegistration{
+		CourseID: event.ID,
+		MemberID: member.ID,
+		Fee:      event.Fee,
+		Notes:    strings.TrimSpace(req.Notes),
+	}
+	var guest *model.Member
+	if req.MemberID == nil {
+		member = model.Member{
+			FirstName:        strings.TrimSpace(req.FirstName),
+			LastName:         strings.TrimSpace(req.LastName),
+			Email:            strings.TrimSpace(req.Email),
+			Phone:            strings.TrimSpace(req.Phone),
+			SignUpDate:       time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC),
+			CancellationDate: time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC),
+			Age:              req.Age,
+			Notes:            "Gast: " + event.Name,
+		}
+		guest = &member
+	}
+
+	// Capacity check, guest and registration are stored in one transaction, see EventRegistrationRepository.Create
+	registered, err := s.eventRegistrationRepo.Create(&registration, guest, referenceDate)
+	if err != nil {
+		return EventRegistrationDTO{}, err
+	}
+	if !registered {
+		return EventRegistrationDTO{}, ErrEventFull
+	}
+	if guest != nil {
+		if err := s.auditService.Record(actor, model.AuditActionCreate, model.AuditEntityMember, fmt.Sprint(member.ID), nil, member); err != nil {
+			return EventRegistrationDTO{}, err
+		}
+	}
+	if err := s.auditService.Record(actor, model.AuditActionCreate, model.AuditEntityEnrollment,
+		enrollmentAuditID(event.ID, member.ID), nil, registration); err != nil {
+		return EventRegistrationDTO{}, err
+	}
+	return EventRegistrationDTO{
+		MemberID:     member.ID,
+		FirstName:    member.FirstName,
+		LastName:     member.LastName,
+		Email:        member.Email,
+		Phone:        member.Phone,
+		Guest:        member.Guest,
+		Fee:          registration.Fee,
+		RegisteredAt: registration.CreatedAt.Format("2006-01-02"),
+		Notes:        registration.Notes,
+	}, nil
+}
+
+// Unregister removes a participant from the registration list of an event; it returns
+// ErrMemberNotFound if the member was not registered
+func (s *EventService) Unregister(actor string, id, memberID uint) error {
+	if _, err := s.getEvent(id); err != nil {
+		return err
+	}
+	deleted, err := s.eventRegistrationRepo.Delete(id, memberID)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrMemberNotFound
+	}
+	return s.auditService.Record(actor, model.AuditActionDelete, model.AuditEntityEnrollment,
+		enrollmentAuditID(id, memberID), model.MemberCourse{MemberID: memberID, CourseID: id}, nil)
+}
+
+// SetPaid records or clears the payment of the event fee by a participant
+func (s *EventService) SetPaid(id, memberID uint, paid bool, referenceDate time.Time) error {
+	if _, err := s.getEvent(id); err != nil {
+		return err
+	}
+	var paidAt *time.Time
+	if paid {
+		paidAt = &referenceDate
+	}
+	updated, err := s.eventRegistrationRepo.SetPaidAt(id, memberID, paidAt)
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// getEvent retrieves a course by ID and ensures it is an event
+func (s *EventService) getEvent(id uint) (model.Course, error) {
+	event, err := getCourse(s.courseRepo, id)
+	if errors.Is(err, ErrCourseNotFound) || (err == nil && !event.IsEvent()) {
+		return event, ErrEventNotFound
+	}
+	return event, err
+}
+
+// applyEventRequest validates the request and copies it into the event. The schedule fields are
+// derived from the dates so events show up in course lists while they take place.
+func applyEventRequest(event *model.Course, req EventRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return fmt.Errorf("%w: name missing", ErrInvalidEvent)
+	}
+	if len(req.Dates) == 0 {
+		return fmt.Errorf("%w: at least one date required", ErrInvalidEvent)
+	}
+	if req.Fee < 0 || req.MaxParticipants < 0 {
+		return fmt.Errorf("%w: negative fee or capacity", ErrInvalidEvent)
+	}
+
+	dates := make([]model.EventDate, 0, len(req.Dates))
+	for _, dateReq := range req.Dates {
+		date, err := time.Parse("2006-01-02", dateReq.Date)
+		if err != nil {
+			return fmt.Errorf("%w: invalid date %s", ErrInvalidEvent, dateReq.Date)
+		}
+		start, startErr := time.Parse("15:04", dateReq.StartTime)
+		end, endErr := time.Parse("15:04", dateReq.EndTime)
+		if startErr != nil || endErr != nil || !end.After(start) {
+			return fmt.Errorf("%w: invalid times on %s", ErrInvalidEvent, dateReq.Date)
+		}
+		dates = append(dates, model.EventDate{Date: date, StartTime: dateReq.StartTime, EndTime: dateReq.EndTime})
+	}
+	sort.Slice(dates, func(i, j int) bool {
+		if !dates[i].Date.Equal(dates[j].Date) {
+			return dates[i].Date.Before(dates[j].Date)
+		}
+		return dates[i].StartTime < dates[j].StartTime
+	})
+	for i := 1; i < len(dates); i++ {
+		if dates[i].Date.Equal(dates[i-1].Date) {
+			return fmt.Errorf("%w: duplicate date %s", ErrInvalidEvent, dates[i].Date.Format("2006-01-02"))
+		}
+	}
+
+	event.Name = strings.TrimSpace(req.Name)
+	event.Location = req.Location
+	event.TrainingType = req.TrainingType
+	event.Description = req.Description
+	event.TrainerNames = req.TrainerNames
+	event.Fee = roundCents(req.Fee)
+	event.MaxParticipants = req.MaxParticipants
+	event.EventDates = dates
+	event.FirstSchedule = dates[0].Date
+	event.LastSchedule = dates[len(dates)-1].Date
+	event.StartTime = dates[0].StartTime
+	event.EndTime = dates[0].EndTime
+	event.Weekday = ""
+	return nil
+}
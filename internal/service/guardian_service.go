@@ -0,0 +1,491 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	netmail "net/mail"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"azh/internal/model"
+	"azh/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Errors returned when maintaining guardians and reporting absences
+var (
+	ErrInvalidGuardian   = errors.New("invalid guardian")
+	ErrGuardianNotFound  = errors.New("guardian not found")
+	ErrInvalidAbsence    = errors.New("invalid absence")
+	ErrAbsenceNotFound   = errors.New("absence not found")
+	ErrAbsenceNotAllowed = errors.New("absence cannot be reported for this session")
+)
+
+// absenceWeeks is how far ahead guardians see the sessions of their children
+const absenceWeeks = 8
+
+// GuardianRequest holds the data of a guardian and the members they may excuse
+type GuardianRequest struct {
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	Phone     string `json:"phone"`
+	MemberIDs []uint `json:"member_ids"`
+}
+
+// GuardianLinkDTO represents the personal link of a guardian
+type GuardianLinkDTO struct {
+	GuardianID uint   `json:"guardian_id"`
+	URL        string `json:"url"`
+}
+
+// AbsenceRequest identifies the session a child is excused from; the reason is required when
+// reporting an absence and ignored when withdrawing it
+type AbsenceRequest struct {
+	MemberID uint   `json:"member_id"`
+	CourseID uint   `json:"course_id"`
+	Date     string `json:"date"` // YYYY-MM-DD
+	Reason   string `json:"reason"`
+}
+
+// GuardianOverviewDTO lists the upcoming sessions of the children of a guardian
+type GuardianOverviewDTO struct {
+	Name     string             `json:"name"`
+	Children []GuardianChildDTO `json:"children"`
+}
+
+// GuardianChildDTO lists the upcoming sessions of one child
+type GuardianChildDTO struct {
+	MemberID  uint                 `json:"member_id"`
+	FirstName string               `json:"first_name"`
+	LastName  string               `json:"last_name"`
+	Sessions  []GuardianSessionDTO `json:"sessions"`
+}
+
+// GuardianSessionDTO represents an upcoming session with the absence reported for it, if any
+type GuardianSessionDTO struct {
+	CourseID   uint   `json:"course_id"`
+	CourseName string `json:"course_name"`
+	Date       string `json:"date"`
+	StartTime  string `json:"start_time"`
+	EndTime    string `json:"end_time"`
+	Excused    bool   `json:"excused"`
+	Reason     string `json:"reason,omitempty"`
+	Cancelled  bool   `json:"cancelled"`
+	Open       bool   `json:"open"` // absence can still be reported or withdrawn
+}
+
+// GuardianService handles guardians and the absences they report for their children through their
+// personal link. A reported absence sets the attendance to excused like a trainer would, so it
+// shows up pre-filled on the participant list and counts as excused in the statistics.
+type GuardianService struct {
+	courseRepo           *repository.CourseRepository
+	memberRepo           *repository.MemberRepository
+	memberCourseRepo     *repository.MemberCourseRepository
+	participationRepo    *repository.ParticipationRepository
+	sessionRepo          *repository.SessionRepository
+	guardianRepo         *repository.GuardianRepository
+	participationService *ParticipationService
+	auditService         *AuditService
+	publicURL            string
+	timezone             *time.Location
+}
+
+// NewGuardianService creates a new GuardianService
+func NewGuardianService(
+	courseRepo *repository.CourseRepository,
+	memberRepo *repository.MemberRepository,
+	memberCourseRepo *repository.MemberCourseRepository,
+	participationRepo *repository.ParticipationRepository,
+	sessionRepo *repository.SessionRepository,
+	guardianRepo *repository.GuardianRepository,
+	participationService *ParticipationService,
+	auditService *AuditService,
+	publicURL string,
+	timezone *time.Location,
+) *GuardianService {
+	return &GuardianService{
+		courseRepo:           courseRepo,
+		memberRepo:           memberRepo,
+		memberCourseRepo:     memberCourseRepo,
+		participationRepo:    participationRepo,
+		sessionRepo:          sessionRepo,
+		guardianRepo:         guardianRepo,
+		participationService: participationService,
+		auditService:         auditService,
+		publicURL:            strings.TrimSuffix(publicURL, "/"),
+		timezone:             timezone,
+	}
+}
+
+// GetGuardians retrieves all guardians with their children
+func (s *GuardianService) GetGuardians() ([]model.Guardian, error) {
+	return s.guardianRepo.GetAll()
+}
+
+// CreateGuardian creates a guardian for the given members
+func (s *GuardianService) CreateGuardian(actor string, req GuardianRequest) (model.Guardian, error) {
+	guardian, err := s.validate(req)
+	if err != nil {
+		return guardian, err
+	}
+	if err := s.guardianRepo.Save(&guardian); err != nil {
+		return guardian, err
+	}
+	return s.saveChildren(actor, model.AuditActionCreate, nil, guardian, req.MemberIDs)
+}
+
+// UpdateGuardian updates a guardian and replaces the members they may excuse
+func (s *GuardianService) UpdateGuardian(actor string, id uint, req GuardianRequest) (model.Guardian, error) {
+	before, err := s.getGuardian(id)
+	if err != nil {
+		return before, err
+	}
+	guardian, err := s.validate(req)
+	if err != nil {
+		return guardian, err
+	}
+	guardian.Model = before.Model
+	guardian.Token = before.Token
+	if err := s.guardianRepo.Save(&guardian); err != nil {
+		return guardian, err
+	}
+	return s.saveChildren(actor, model.AuditActionUpdate, before, guardian, req.MemberIDs)
+}
+
+// GetLink returns the personal link of a guardian and creates it on first use. With rotate, the
+// guardian gets a new link and the old one stops working.
+func (s *GuardianService) GetLink(id uint, rotate bool) (GuardianLinkDTO, error) {
+	guardian, err := s.getGuardian(id)
+	if err != nil {
+		return GuardianLinkDTO{}, err
+	}
+	return s.link(&guardian, rotate)
+}
+
+// LinkChild links a member to the guardian with the given email address, creating the guardian if
+// needed, and returns the guardian's personal link. It is used when an online registration is approved.
+func (s *GuardianService) LinkChild(actor, name, email, phone string, memberID uint) (GuardianLinkDTO, error) {
+	guardian, err := s.guardianRepo.FindByEmail(email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		guardian = model.Guardian{Name: name, Email: email, Phone: phone}
+		if err = s.guardianRepo.Save(&guardian); err == nil {
+			err = s.auditService.Record(actor, model.AuditActionCreate, model.AuditEntityGuardian, fmt.Sprint(guardian.ID), nil, guardian)
+		}
+	}
+	if err != nil {
+		return GuardianLinkDTO{}, err
+	}
+	if err := s.guardianRepo.AddChild(guardian.ID, memberID); err != nil {
+		return GuardianLinkDTO{}, err
+	}
+	return s.link(&guardian, false)
+}
+
+// GetOverview lists the sessions of the next weeks in the courses of the guardian's children
+func (s *GuardianService) GetOverview(token string, now time.Time) (GuardianOverviewDTO, error) {
+	guardian, err := s.getByToken(token)
+	if err != nil {
+		return GuardianOverviewDTO{}, err
+	}
+	overview := GuardianOverviewDTO{Name: guardian.Name, Children: []GuardianChildDTO{}}
+	memberIDs := make([]uint, 0, len(guardian.Children))
+	for _, child := range guardian.Children {
+		memberIDs = append(memberIDs, child.MemberID)
+	}
+	if len(memberIDs) == 0 {
+		return overview, nil
+	}
+
+	local := now.In(s.timezone)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	from, to := today, today.AddDate(0, 0, 7*absenceWeeks)
+	minDate, maxDate := from.Format("2006-01-02"), to.Format("2006-01-02")
+	members, err := s.memberRepo.GetByIDs(memberIDs)
+	if err != nil {
+		return overview, err
+	}
+	enrollments, err := s.memberCourseRepo.GetByMemberIDs(memberIDs)
+	if err != nil {
+		return overview, err
+	}
+	var courseIDs []uint
+	for _, enrollment := range enrollments {
+		courseIDs = append(courseIDs, enrollment.CourseID)
+	}
+	courses, err := s.courseRepo.GetByIDs(courseIDs)
+	if err != nil {
+		return overview, err
+	}
+	coursesByID := make(map[uint]model.Course, len(courses))
+	for _, course := range courses {
+		coursesByID[course.ID] = course
+	}
+	absences, err := s.guardianRepo.GetAbsences(memberIDs, minDate, maxDate)
+	if err != nil {
+		return overview, err
+	}
+	reasons := make(map[string]string, len(absences))
+	for _, absence := range absences {
+		reasons[participationAuditID(absence.CourseID, absence.Date, absence.MemberID)] = absence.Reason
+	}
+	records, err := s.sessionRepo.GetInRange(minDate, maxDate)
+	if err != nil {
+		return overview, err
+	}
+	cancelled := make(map[string]bool)
+	for _, record := range records {
+		if record.CancelledAt != nil {
+			cancelled[sessionKey(record.CourseID, record.Date)] = true
+		}
+	}
+
+	for _, member := range members {
+		child := GuardianChildDTO{MemberID: member.ID, FirstName: member.FirstName, LastName: member.LastName,
+			Sessions: []GuardianSessionDTO{}}
+		for _, enrollment := range enrollments {
+			course, ok := coursesByID[enrollment.CourseID]
+			if enrollment.MemberID != member.ID || !ok {
+				continue
+			}
+			for _, dateStr := range scheduledDates(course, from, to) {
+				date, _ := time.Parse("2006-01-02", dateStr)
+				if !memberActive(member, date) {
+					continue
+				}
+				startTime, endTime := sessionTimes(course, date)
+				reason, excused := reasons[participationAuditID(course.ID, date, member.ID)]
+				session := GuardianSessionDTO{
+					CourseID:   course.ID,
+					CourseName: course.Name,
+					Date:       dateStr,
+					StartTime:  startTime,
+					EndTime:    endTime,
+					Excused:    excused,
+					Reason:     reason,
+					Cancelled:  cancelled[sessionKey(course.ID, date)],
+				}
+				session.Open = !session.Cancelled && now.Before(absenceDeadline(course, date, s.timezone))
+				child.Sessions = append(child.Sessions, session)
+			}
+		}
+		slices.SortFunc(child.Sessions, func(a, b GuardianSessionDTO) int {
+			return strings.Compare(a.Date+a.StartTime, b.Date+b.StartTime)
+		})
+		overview.Children = append(overview.Children, child)
+	}
+	return overview, nil
+}
+
+// ReportAbsence excuses a child from an upcoming session. Reporting again changes the reason.
+func (s *GuardianService) ReportAbsence(token string, req AbsenceRequest, now time.Time) (model.Absence, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return model.Absence{}, fmt.Errorf("%w: reason missing", ErrInvalidAbsence)
+	}
+	guardian, date, err := s.checkAbsence(token, req, now)
+	if err != nil {
+		return model.Absence{}, err
+	}
+
+	absence, err := s.guardianRepo.GetAbsence(req.MemberID, req.CourseID, date)
+	var before any
+	switch {
+	case err == nil:
+		before = absence
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		absence = model.Absence{MemberID: req.MemberID, CourseID: req.CourseID, Date: date}
+	default:
+		return absence, err
+	}
+	actor := guardianActor(guardian)
+	absence.GuardianID = guardian.ID
+	absence.Reason = reason
+	// The absence is stored with the excused attendance, so neither exists without the other
+	if err := s.participationService.SetAttendanceWith(actor, req.CourseID, date, req.MemberID, model.ParticipationStatusExcused,
+		func(changes []model.AttendanceChange, dropIn map[uint]bool, auditEntries []model.AuditEntry) error {
+			return s.guardianRepo.SaveAbsenceWithChanges(&absence, changes, dropIn, auditEntries)
+		}); err != nil {
+		return absence, err
+	}
+	action := model.AuditActionUpdate
+	if before == nil {
+		action = model.AuditActionCreate
+	}
+	err = s.auditService.Record(actor, action, model.AuditEntityAbsence, fmt.Sprint(absence.ID), before, absence)
+	return absence, err
+}
+
+// WithdrawAbsence withdraws an absence reported for an upcoming session. The attendance is reset
+// unless a trainer has changed it in the meantime.
+func (s *GuardianService) WithdrawAbsence(token string, req AbsenceRequest, now time.Time) error {
+	guardian, date, err := s.checkAbsence(token, req, now)
+	if err != nil {
+		return err
+	}
+	absence, err := s.guardianRepo.GetAbsence(req.MemberID, req.CourseID, date)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrAbsenceNotFound
+	}
+	if err != nil {
+		return err
+	}
+	actor := guardianActor(guardian)
+	status, err := s.participationRepo.GetStatus(req.MemberID, req.CourseID, date)
+	if err != nil {
+		return err
+	}
+	if status == model.ParticipationStatusExcused {
+		err = s.participationService.SetAttendanceWith(actor, req.CourseID, date, req.MemberID, model.ParticipationStatusAbsent,
+			func(changes []model.AttendanceChange, dropIn map[uint]bool, auditEntries []model.AuditEntry) error {
+				return s.guardianRepo.DeleteAbsenceWithChanges(absence.ID, changes, dropIn, auditEntries)
+			})
+	} else {
+		err = s.guardianRepo.DeleteAbsence(absence.ID)
+	}
+	if err != nil {
+		return err
+	}
+	return s.auditService.Record(actor, model.AuditActionDelete, model.AuditEntityAbsence, fmt.Sprint(absence.ID), absence, nil)
+}
+
+// checkAbsence checks that the guardian may excuse the member from the session and that the
+// session has not started yet and was not cancelled
+func (s *GuardianService) checkAbsence(token string, req AbsenceRequest, now time.Time) (model.Guardian, time.Time, error) {
+	guardian, err := s.getByToken(token)
+	if err != nil {
+		return guardian, time.Time{}, err
+	}
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return guardian, date, fmt.Errorf("%w: invalid date %q", ErrInvalidAbsence, req.Date)
+	}
+	if !slices.ContainsFunc(guardian.Children, func(child model.GuardianChild) bool { return child.MemberID == req.MemberID }) {
+		return guardian, date, ErrMemberNotFound
+	}
+	course, err := getCourse(s.courseRepo, req.CourseID)
+	if err != nil {
+		return guardian, date, err
+	}
+	enrolled, err := s.memberCourseRepo.Exists(req.MemberID, req.CourseID)
+	if err != nil {
+		return guardian, date, err
+	}
+	if !enrolled || !slices.Contains(scheduledDates(course, date, date), req.Date) {
+		return guardian, date, fmt.Errorf("%w: no session of the child on %s", ErrAbsenceNotAllowed, req.Date)
+	}
+	if !now.Before(absenceDeadline(course, date, s.timezone)) {
+		return guardian, date, fmt.Errorf("%w: session already started", ErrAbsenceNotAllowed)
+	}
+	session, err := s.sessionRepo.Get(req.CourseID, date)
+	switch {
+	case err == nil && session.CancelledAt != nil:
+		return guardian, date, fmt.Errorf("%w: session cancelled", ErrAbsenceNotAllowed)
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		return guardian, date, err
+	}
+	return guardian, date, nil
+}
+
+// validate checks the data of a guardian and converts it into a guardian
+func (s *GuardianService) validate(req GuardianRequest) (model.Guardian, error) {
+	guardian := model.Guardian{
+		Name:  strings.TrimSpace(req.Name),
+		Email: strings.TrimSpace(req.Email),
+		Phone: strings.TrimSpace(req.Phone),
+	}
+	if guardian.Name == "" {
+		return guardian, fmt.Errorf("%w: name missing", ErrInvalidGuardian)
+	}
+	if guardian.Email != "" {
+		if _, err := netmail.ParseAddress(guardian.Email); err != nil {
+			return guardian, fmt.Errorf("%w: invalid email address", ErrInvalidGuardian)
+		}
+	}
+	members, err := s.memberRepo.GetByIDs(req.MemberIDs)
+	if err != nil {
+		return guardian, err
+	}
+	for _, memberID := range req.MemberIDs {
+		if !slices.ContainsFunc(members, func(member model.Member) bool { return member.ID == memberID }) {
+			return guardian, fmt.Errorf("%w: member %d not found", ErrInvalidGuardian, memberID)
+		}
+	}
+	return guardian, nil
+}
+
+// saveChildren replaces the children of a saved guardian and records the change
+func (s *GuardianService) saveChildren(actor, action string, before any, guardian model.Guardian, memberIDs []uint) (model.Guardian, error) {
+	if err := s.guardianRepo.SetChildren(guardian.ID, memberIDs); err != nil {
+		return guardian, err
+	}
+	guardian, err := s.getGuardian(guardian.ID)
+	if err != nil {
+		return guardian, err
+	}
+	err = s.auditService.Record(actor, action, model.AuditEntityGuardian, fmt.Sprint(guardian.ID), before, guardian)
+	return guardian, err
+}
+
+// link returns the personal link of a guardian, creating or rotating its token
+func (s *GuardianService) link(guardian *model.Guardian, rotate bool) (GuardianLinkDTO, error) {
+	if guardian.Token == "" || rotate {
+		var err error
+		if guardian.Token, err = newToken(); err != nil {
+			return GuardianLinkDTO{}, err
+		}
+		if err := s.guardianRepo.Save(guardian); err != nil {
+			return GuardianLinkDTO{}, err
+		}
+	}
+	return GuardianLinkDTO{
+		GuardianID: guardian.ID,
+		URL:        fmt.Sprintf("%s/absence?token=%s", s.publicURL, url.QueryEscape(guardian.Token)),
+	}, nil
+}
+
+// getGuardian retrieves a guardian and maps a missing record to ErrGuardianNotFound
+func (s *GuardianService) getGuardian(id uint) (model.Guardian, error) {
+	guardian, err := s.guardianRepo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return guardian, ErrGuardianNotFound
+	}
+	return guardian, err
+}
+
+// getByToken retrieves the guardian of a personal link
+func (s *GuardianService) getByToken(token string) (model.Guardian, error) {
+	if token == "" {
+		return model.Guardian{}, ErrGuardianNotFound
+	}
+	guardian, err := s.guardianRepo.GetByToken(token)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return guardian, ErrGuardianNotFound
+	}
+	return guardian, err
+}
+
+// absenceDeadline is the start of a session in the time zone of the club; absences can be reported
+// until then, or until the start of the day if the session has no valid times
+func absenceDeadline(course model.Course, date time.Time, timezone *time.Location) time.Time {
+	start, _, ok := sessionClock(course, date)
+	if !ok {
+		return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, timezone)
+	}
+	return atClock(date, start, timezone)
+}
+
+// memberActive reports whether a member has joined and not left the club on the given date,
+// matching the filter of MemberRepository.GetByIDsAndDate
+func memberActive(member model.Member, date time.Time) bool {
+	if !member.SignUpDate.IsZero() && member.SignUpDate.After(date) {
+		return false
+	}
+	return member.CancellationDate.IsZero() || !member.CancellationDate.Before(date)
+}
+
+// guardianActor names a guardian in the audit log and the attendance journal
+func guardianActor(guardian model.Guardian) string {
+	return fmt.Sprintf("guardian:%d", guardian.ID)
+}
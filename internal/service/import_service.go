@@ -20,6 +20,28 @@ type ImportService struct {
 	memberRepo        *repository.MemberRepository
 	memberCourseRepo  *repository.MemberCourseRepository
 	participationRepo *repository.ParticipationRepository
+	trainerService    *TrainerService
+	locationService   *LocationService
+	waitlistService   *WaitlistService
+	auditService      *AuditService
+}
+
+// ImportResult summarizes the changes of an import
+type ImportResult struct {
+	Added    int      `json:"added"`
+	Removed  int      `json:"removed"`
+	Promoted int      `json:"promoted"`
+	Warnings []string `json:"warnings"`
+}
+
+// importSummary is the audited value of an import
+type importSummary struct {
+	Kind     string `json:"kind"`
+	Created  int    `json:"created"`
+	Updated  int    `json:"updated"`
+	Added    int    `json:"added,omitempty"`
+	Removed  int    `json:"removed,omitempty"`
+	Promoted int    `json:"promoted,omitempty"`
 }
 
 // NewImportService creates a new ImportService
@@ -29,6 +51,10 @@ func NewImportService(
 	memberRepo *repository.MemberRepository,
 	memberCourseRepo *repository.MemberCourseRepository,
 	participationRepo *repository.ParticipationRepository,
+	trainerService *TrainerService,
+	locationService *LocationService,
+	waitlistService *WaitlistService,
+	auditService *AuditService,
 ) *ImportService {
 	return &ImportService{
 		db:                db,
@@ -36,14 +62,18 @@ func NewImportService(
 		memberRepo:        memberRepo,
 		memberCourseRepo:  memberCourseRepo,
 		participationRepo: participationRepo,
+		trainerService:    trainerService,
+		locationService:   locationService,
+		waitlistService:   waitlistService,
+		auditService:      auditService,
 	}
 }
 
 // ProcessCSV processes a CSV file based on detected type
-func (s *ImportService) ProcessCSV(filePath, fileName string) error {
+func (s *ImportService) ProcessCSV(actor, filePath, fileName string) (ImportResult, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
-		return fmt.Errorf("unable to open file: %v", err)
+		return ImportResult{}, fmt.Errorf("unable to open file: %v", err)
 	}
 	defer file.Close()
 
@@ -54,7 +84,7 @@ func (s *ImportService) ProcessCSV(filePath, fileName string) error {
 	// Read header to detect file type
 	header, err := reader.Read()
 	if err != nil {
-		return fmt.Errorf("unable to read header: %v", err)
+		return ImportResult{}, fmt.Errorf("unable to read header: %v", err)
 	}
 
 	// Detect file type based on column presence
@@ -70,22 +100,23 @@ func (s *ImportService) ProcessCSV(filePath, fileName string) error {
 	}
 
 	if isParticipants && !isTrainings {
-		return s.importParticipants(filePath, header, reader)
+		return s.importParticipants(actor, fileName, header, reader)
 	} else if isTrainings && !isParticipants {
-		return s.importCourses(filePath, header, reader)
+		return s.importCourses(actor, fileName, header, reader)
 	} else {
 		// Fallback to filename hint
 		if strings.Contains(strings.ToLower(fileName), "trainingsstatistik") {
-			return s.importCourses(filePath, header, reader)
+			return s.importCourses(actor, fileName, header, reader)
 		} else if strings.Contains(strings.ToLower(fileName), "trainingsanmeldungen") {
-			return s.importParticipants(filePath, header, reader)
+			return s.importParticipants(actor, fileName, header, reader)
 		}
-		return fmt.Errorf("unable to determine file type for: %s", fileName)
+		return ImportResult{}, fmt.Errorf("unable to determine file type for: %s", fileName)
 	}
 }
 
-// importCourses imports course data from TrainingsStatistik.csv
-func (s *ImportService) importCourses(filePath string, header []string, reader *csv.Reader) error {
+// importCourses imports course data from TrainingsStatistik.csv and warns about locations that
+// are not mapped to a location yet
+func (s *ImportService) importCourses(actor, fileName string, header []string, reader *csv.Reader) (ImportResult, error) {
 	// Map header to column indices
 	idIdx := 0   // First column assumed as ID
 	nameIdx := 1 // Second column assumed as Name
@@ -116,6 +147,19 @@ func (s *ImportService) importCourses(filePath string, header []string, reader *
 		}
 	}
 
+	courses, err := s.courseRepo.GetAllUnfiltered()
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("error loading courses: %v", err)
+	}
+	existing := make(map[uint]model.Course, len(courses))
+	for _, course := range courses {
+		existing[course.ID] = course
+	}
+	summary := importSummary{Kind: "courses"}
+	var entries []model.AuditEntry
+	result := ImportResult{Warnings: []string{}}
+	unmapped := make(map[string]struct{})
+
 	// Read and process rows
 	for {
 		row, err := reader.Read()
@@ -123,7 +167,7 @@ func (s *ImportService) importCourses(filePath string, header []string, reader *
 			break
 		}
 		if err != nil {
-			return fmt.Errorf("error reading row: %v", err)
+			return ImportResult{}, fmt.Errorf("error reading row: %v", err)
 		}
 
 		// Skip empty or summary rows (e.g., "Gesamt")
@@ -136,6 +180,11 @@ func (s *ImportService) importCourses(filePath string, header []string, reader *
 		if _, err := fmt.Sscanf(row[idIdx], "%d", &courseID); err != nil {
 			continue // Skip rows with invalid course ID
 		}
+		// Numbers from the reserved range belong to events created in the application
+		if courseID >= model.EventIDOffset {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Kursnummer %d übersprungen: reserviert für Veranstaltungen", courseID))
+			continue
+		}
 		name := row[nameIdx]
 		location := safeGet(row, ortIdx)
 		trainerNames := safeGet(row, trainerIdx)
@@ -153,7 +202,7 @@ func (s *ImportService) importCourses(filePath string, header []string, reader *
 		//	}
 		//}
 
-		// Upsert course
+		// Upsert course, keeping the fields maintained in this application
 		course := model.Course{
 			ID:           courseID,
 			Name:         name,
@@ -165,15 +214,49 @@ func (s *ImportService) importCourses(filePath string, header []string, reader *
 			LastSchedule: lastSchedule,
 			TrainerNames: trainerNames,
 		}
-		if err := s.db.Save(&course).Error; err != nil {
-			return fmt.Errorf("error saving course %d: %v", courseID, err)
+		if err := s.db.Omit("MaxParticipants", "Kind", "Description", "Fee", "DropIn", "LocationID", "RoomID").Save(&course).Error; err != nil {
+			return ImportResult{}, fmt.Errorf("error saving course %d: %v", courseID, err)
+		}
+		if err := s.trainerService.NormalizeCourseTrainers(course); err != nil {
+			return ImportResult{}, err
+		}
+		course.LocationID, course.RoomID = existing[courseID].LocationID, existing[courseID].RoomID
+		mapped, err := s.locationService.NormalizeCourseLocation(course)
+		if err != nil {
+			return ImportResult{}, err
+		}
+		if _, warned := unmapped[normalizeAlias(location)]; !mapped && !warned {
+			unmapped[normalizeAlias(location)] = struct{}{}
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Ort \"%s\" ist keinem Standort zugeordnet", strings.TrimSpace(location)))
+		}
+
+		before, found := existing[courseID]
+		after := before
+		after.ID, after.Name, after.Location, after.TrainingType = course.ID, course.Name, course.Location, course.TrainingType
+		after.Weekday, after.StartTime, after.EndTime = course.Weekday, course.StartTime, course.EndTime
+		after.LastSchedule, after.TrainerNames = course.LastSchedule, course.TrainerNames
+		var entry model.AuditEntry
+		switch {
+		case !found:
+			entry, err = newAuditEntry(actor, model.AuditActionCreate, model.AuditEntityCourse, fmt.Sprint(courseID), nil, after)
+			summary.Created++
+		case importedCourseChanged(before, after):
+			entry, err = newAuditEntry(actor, model.AuditActionUpdate, model.AuditEntityCourse, fmt.Sprint(courseID), before, after)
+			summary.Updated++
+		default:
+			continue
+		}
+		if err != nil {
+			return ImportResult{}, err
 		}
+		existing[courseID] = after
+		entries = append(entries, entry)
 	}
-	return nil
+	return result, s.recordImport(actor, fileName, summary, entries)
 }
 
 // importParticipants imports participant and enrollment data from Trainingsanmeldungen.csv
-func (s *ImportService) importParticipants(filePath string, header []string, reader *csv.Reader) error {
+func (s *ImportService) importParticipants(actor, fileName string, header []string, reader *csv.Reader) (ImportResult, error) {
 	// Map header to column indices
 	datumIdx := -1
 	kundigungsdatumIdx := -1
@@ -217,6 +300,7 @@ func (s *ImportService) importParticipants(filePath string, header []string, rea
 	// Collect data for batch processing
 	membersMap := make(map[uint]model.Member)
 	memberCoursesSet := make(map[string]model.MemberCourse)
+	var skipped []uint
 
 	for {
 		row, err := reader.Read()
@@ -224,7 +308,7 @@ func (s *ImportService) importParticipants(filePath string, header []string, rea
 			break
 		}
 		if err != nil {
-			return fmt.Errorf("error reading row: %v", err)
+			return ImportResult{}, fmt.Errorf("error reading row: %v", err)
 		}
 
 		// Skip empty rows
@@ -237,6 +321,11 @@ func (s *ImportService) importParticipants(filePath string, header []string, rea
 		if _, err := fmt.Sscanf(row[mitgliedsnummerIdx], "%d", &memberID); err != nil {
 			continue // Skip rows with invalid member ID
 		}
+		// Numbers from the reserved ranges belong to members created in the application
+		if memberID >= model.RegisteredIDOffset {
+			skipped = append(skipped, memberID)
+			continue
+		}
 
 		// Parse dates (format may vary, try DD.MM.YYYY or full timestamp)
 		signUpDateStr := safeGet(row, datumIdx)
@@ -294,23 +383,186 @@ func (s *ImportService) importParticipants(filePath string, header []string, rea
 
 	// Batch update database
 	// 1. Upsert members
+	memberIDs := make([]uint, 0, len(membersMap))
+	for id := range membersMap {
+		memberIDs = append(memberIDs, id)
+	}
+	members, err := s.memberRepo.GetByIDs(memberIDs)
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("error loading members: %v", err)
+	}
+	existing := make(map[uint]model.Member, len(members))
+	for _, member := range members {
+		existing[member.ID] = member
+	}
+	summary := importSummary{Kind: "participants"}
+	var entries []model.AuditEntry
 	for _, member := range membersMap {
 		if err := s.db.Save(&member).Error; err != nil {
-			return fmt.Errorf("error saving member %d: %v", member.ID, err)
+			return ImportResult{}, fmt.Errorf("error saving member %d: %v", member.ID, err)
+		}
+		var entry model.AuditEntry
+		before, found := existing[member.ID]
+		switch {
+		case !found:
+			entry, err = newAuditEntry(actor, model.AuditActionCreate, model.AuditEntityMember, fmt.Sprint(member.ID), nil, member)
+			summary.Created++
+		case importedMemberChanged(before, member):
+			entry, err = newAuditEntry(actor, model.AuditActionUpdate, model.AuditEntityMember, fmt.Sprint(member.ID), before, member)
+			summary.Updated++
+		default:
+			continue
 		}
+		if err != nil {
+			return ImportResult{}, err
+		}
+		entries = append(entries, entry)
+	}
+
+	// 2. Apply the differences to member_courses
+	today := time.Now()
+	countsBefore, err := s.memberCourseRepo.CountActive(today)
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("error counting enrollments: %v", err)
+	}
+	result, err := s.syncMemberCourses(actor, memberCoursesSet)
+	if err != nil {
+		return ImportResult{}, err
 	}
 
-	// 2. Truncate and reimport member_courses
-	if err := s.db.Exec("TRUNCATE TABLE member_courses").Error; err != nil {
-		return fmt.Errorf("error truncating member_courses: %v", err)
+	// 3. Fill spots freed by unenrollments and cancellations from the waitlists
+	if result.Promoted, err = s.waitlistService.PromoteAllWaitlists(actor, today); err != nil {
+		return ImportResult{}, fmt.Errorf("error promoting waitlists: %v", err)
 	}
-	for _, mc := range memberCoursesSet {
-		if err := s.db.Save(&mc).Error; err != nil {
-			return fmt.Errorf("error saving member_course %d-%s: %v", mc.MemberID, mc.CourseID, err)
+
+	// 4. Warn about courses the file pushed over capacity
+	result.Warnings, err = s.capacityWarnings(countsBefore, today)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	for _, memberID := range skipped {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Mitgliedsnummer %d übersprungen: reserviert für Online-Anmeldungen und Gäste", memberID))
+	}
+
+	summary.Added, summary.Removed, summary.Promoted = result.Added, result.Removed, result.Promoted
+	if err := s.recordImport(actor, fileName, summary, entries); err != nil {
+		return ImportResult{}, err
+	}
+	return result, nil
+}
+
+// syncMemberCourses adds the enrollments listed in the file and removes those missing from it.
+// Manual enrollments are kept and become regular ones once the file lists them. The enrollment
+// changes are audited in the same transaction.
+func (s *ImportService) syncMemberCourses(actor string, memberCoursesSet map[string]model.MemberCourse) (ImportResult, error) {
+	var result ImportResult
+	var entries []model.AuditEntry
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var existing []model.MemberCourse
+		if err := tx.Find(&existing).Error; err != nil {
+			return fmt.Errorf("error loading member_courses: %v", err)
+		}
+		seen := make(map[string]struct{})
+		for _, mc := range existing {
+			key := fmt.Sprintf("%d-%d", mc.MemberID, mc.CourseID)
+			if _, duplicate := seen[key]; duplicate {
+				if err := tx.Unscoped().Delete(&mc).Error; err != nil {
+					return fmt.Errorf("error removing member_course %d-%d: %v", mc.MemberID, mc.CourseID, err)
+				}
+				continue
+			}
+			seen[key] = struct{}{}
+
+			if _, listed := memberCoursesSet[key]; listed {
+				if mc.Manual {
+					if err := tx.Model(&mc).Update("manual", false).Error; err != nil {
+						return fmt.Errorf("error updating member_course %d-%d: %v", mc.MemberID, mc.CourseID, err)
+					}
+				}
+				continue
+			}
+			if mc.Manual {
+				continue
+			}
+			if err := tx.Unscoped().Delete(&mc).Error; err != nil {
+				return fmt.Errorf("error removing member_course %d-%d: %v", mc.MemberID, mc.CourseID, err)
+			}
+			entry, err := newAuditEntry(actor, model.AuditActionDelete, model.AuditEntityEnrollment,
+				enrollmentAuditID(mc.CourseID, mc.MemberID), mc, nil)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+			result.Removed++
 		}
+		for key, mc := range memberCoursesSet {
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			if err := tx.Create(&mc).Error; err != nil {
+				return fmt.Errorf("error saving member_course %d-%d: %v", mc.MemberID, mc.CourseID, err)
+			}
+			entry, err := newAuditEntry(actor, model.AuditActionCreate, model.AuditEntityEnrollment,
+				enrollmentAuditID(mc.CourseID, mc.MemberID), nil, mc)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+			result.Added++
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(entries, 500).Error
+	})
+	if err == nil {
+		s.auditService.Publish(entries)
 	}
+	return result, err
+}
+
+// capacityWarnings lists the courses over capacity whose enrollments grew during the import
+func (s *ImportService) capacityWarnings(countsBefore map[uint]int, referenceDate time.Time) ([]string, error) {
+	courses, err := s.courseRepo.GetAllUnfiltered()
+	if err != nil {
+		return nil, fmt.Errorf("error loading courses: %v", err)
+	}
+	counts, err := s.memberCourseRepo.CountActive(referenceDate)
+	if err != nil {
+		return nil, fmt.Errorf("error counting enrollments: %v", err)
+	}
+	warnings := []string{}
+	for _, course := range courses {
+		count := counts[course.ID]
+		if course.MaxParticipants > 0 && count > course.MaxParticipants && count > countsBefore[course.ID] {
+			warnings = append(warnings, fmt.Sprintf("Kurs %d %s ist überbucht: %d Anmeldungen bei %d Plätzen (%d neu)",
+				course.ID, course.Name, count, course.MaxParticipants, count-countsBefore[course.ID]))
+		}
+	}
+	return warnings, nil
+}
+
+// recordImport audits the entities changed by an import together with a summary of the import
+func (s *ImportService) recordImport(actor, fileName string, summary importSummary, entries []model.AuditEntry) error {
+	entry, err := newAuditEntry(actor, model.AuditActionImport, model.AuditEntityImport, fileName, nil, summary)
+	if err != nil {
+		return err
+	}
+	return s.auditService.RecordAll(append(entries, entry))
+}
+
+// importedCourseChanged reports whether an import changed any of the fields it maintains
+func importedCourseChanged(before, after model.Course) bool {
+	return before.Name != after.Name || before.Location != after.Location || before.TrainingType != after.TrainingType ||
+		before.Weekday != after.Weekday || before.StartTime != after.StartTime || before.EndTime != after.EndTime ||
+		!before.LastSchedule.Equal(after.LastSchedule) || before.TrainerNames != after.TrainerNames
+}
 
-	return nil
+// importedMemberChanged reports whether an import changed any of the fields it maintains
+func importedMemberChanged(before, after model.Member) bool {
+	return before.FirstName != after.FirstName || before.LastName != after.LastName || before.Email != after.Email ||
+		before.Phone != after.Phone || !before.SignUpDate.Equal(after.SignUpDate) ||
+		!before.CancellationDate.Equal(after.CancellationDate) || before.Age != after.Age || before.Notes != after.Notes
 }
 
 // safeGet retrieves a value from a slice safely
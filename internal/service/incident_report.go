@@ -0,0 +1,117 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+
+	"azh/internal/model"
+	"github.com/go-pdf/fpdf"
+)
+
+// Page layout of incident reports (A4 portrait, in mm)
+const (
+	reportMargin      = 15.0
+	reportLabelWidth  = 50.0
+	reportLineHeight  = 6.0
+	reportSectionSkip = 4.0
+)
+
+// incidentStatusLabels are the printed names of the incident statuses
+var incidentStatusLabels = map[string]string{
+	model.IncidentStatusReported:  "gemeldet",
+	model.IncidentStatusForwarded: "an Versicherung weitergeleitet",
+	model.IncidentStatusClosed:    "abgeschlossen",
+}
+
+// IncidentReportPDF renders the report form of an incident with the details the sports insurance
+// asks for, followed by signature lines for the trainer and the board
+func (s *PrintService) IncidentReportPDF(incident model.Incident, member model.Member, course model.Course) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(reportMargin, reportMargin, reportMargin)
+	pdf.SetAutoPageBreak(true, reportMargin)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(0, 7, tr(s.club.Name), "", 1, "L", false, 0, "")
+	if s.club.Address != "" {
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.CellFormat(0, 5, tr(s.club.Address), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+	pdf.SetFont("Helvetica", "B", 13)
+	pdf.CellFormat(0, 7, tr("Unfallmeldung für die Sportversicherung"), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.CellFormat(0, 5, tr(fmt.Sprintf("Vorgang Nr. %d, gemeldet am %s von %s", incident.ID,
+		incident.CreatedAt.Format("02.01.2006"), incident.ReportedBy)), "", 1, "L", false, 0, "")
+
+	age := ""
+	if member.Age > 0 {
+		age = fmt.Sprintf("%d Jahre", member.Age)
+	}
+	reference := incident.InsurerReference
+	if reference == "" {
+		reference = "-"
+	}
+	s.writeReportSection(pdf, tr, "Verletzte Person", [][2]string{
+		{"Name", member.FirstName + " " + member.LastName},
+		{"Mitgliedsnummer", fmt.Sprintf("%d", member.ID)},
+		{"Alter", age},
+		{"Telefon", member.Phone},
+		{"E-Mail", member.Email},
+	})
+	s.writeReportSection(pdf, tr, "Unfall", [][2]string{
+		{"Datum, Uhrzeit", incident.OccurredAt.Format("02.01.2006, 15:04") + " Uhr"},
+		{"Ort", incident.Location},
+		{"Veranstaltung", fmt.Sprintf("%s (Kurs-Nr. %d)", course.Name, incident.CourseID)},
+		{"Sparte", course.TrainingType},
+		{"Art der Verletzung", incident.Injury},
+		{"Unfallhergang", incident.Description},
+		{"Erste Hilfe", incident.FirstAid},
+		{"Zeugen", incident.Witnesses},
+	})
+	s.writeReportSection(pdf, tr, "Bearbeitung", [][2]string{
+		{"Status", incidentStatusLabels[incident.Status]},
+		{"Aktenzeichen Versicherung", reference},
+	})
+
+	pdf.Ln(20)
+	pdf.SetFont("Helvetica", "", 8)
+	const lineWidth = 80.0
+	y := pdf.GetY()
+	for i, label := range []string{"Ort, Datum, Unterschrift Übungsleiter/in", "Ort, Datum, Unterschrift Vorstand"} {
+		x := reportMargin + float64(i)*(lineWidth+20)
+		pdf.Line(x, y, x+lineWidth, y)
+		pdf.SetXY(x, y+1)
+		pdf.CellFormat(lineWidth, 4, tr(label), "", 0, "L", false, 0, "")
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeReportSection prints a titled section of label and value rows; long values wrap
+func (s *PrintService) writeReportSection(pdf *fpdf.Fpdf, tr func(string) string, title string, rows [][2]string) {
+	pdf.Ln(reportSectionSkip)
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetFillColor(230, 230, 230)
+	pdf.CellFormat(0, reportLineHeight+1, tr(title), "1", 1, "L", true, 0, "")
+	pageWidth, _ := pdf.GetPageSize()
+	valueWidth := pageWidth - 2*reportMargin - reportLabelWidth
+	for _, row := range rows {
+		value := row[1]
+		if value == "" {
+			value = "-"
+		}
+		pdf.SetFont("Helvetica", "", 9)
+		lines := pdf.SplitLines([]byte(tr(value)), valueWidth-2)
+		height := float64(max(len(lines), 1)) * reportLineHeight
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.CellFormat(reportLabelWidth, height, tr(row[0]), "1", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.MultiCell(valueWidth, reportLineHeight, tr(value), "1", "L", false)
+	}
+}
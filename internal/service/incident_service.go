@@ -0,0 +1,245 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"azh/internal/model"
+	"azh/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Errors returned when reporting incidents
+var (
+	ErrInvalidIncident  = errors.New("invalid incident")
+	ErrIncidentNotFound = errors.New("incident not found")
+	ErrIncidentState    = errors.New("incident status cannot change this way")
+)
+
+// incidentTransitions lists the statuses an incident can move to from each status
+var incidentTransitions = map[string][]string{
+	model.IncidentStatusReported:  {model.IncidentStatusForwarded, model.IncidentStatusClosed},
+	model.IncidentStatusForwarded: {model.IncidentStatusClosed},
+}
+
+// IncidentRequest holds the data of an incident report; the date is taken from the session
+type IncidentRequest struct {
+	MemberID    uint   `json:"member_id"`
+	Time        string `json:"time"` // HH:MM
+	Location    string `json:"location"`
+	Injury      string `json:"injury"`
+	Description string `json:"description"`
+	FirstAid    string `json:"first_aid"`
+	Witnesses   string `json:"witnesses"`
+}
+
+// IncidentStatusRequest holds a status change of an incident
+type IncidentStatusRequest struct {
+	Status           string `json:"status"`
+	InsurerReference string `json:"insurer_reference"`
+}
+
+// IncidentDTO represents an incident with the names of the member and course involved
+type IncidentDTO struct {
+	model.Incident
+	MemberName string `json:"member_name"`
+	CourseName string `json:"course_name"`
+}
+
+// IncidentService handles incident and injury reports for the sports insurance
+type IncidentService struct {
+	courseRepo   *repository.CourseRepository
+	memberRepo   *repository.MemberRepository
+	incidentRepo *repository.IncidentRepository
+	printService *PrintService
+	auditService *AuditService
+}
+
+// NewIncidentService creates a new IncidentService
+func NewIncidentService(
+	courseRepo *repository.CourseRepository,
+	memberRepo *repository.MemberRepository,
+	incidentRepo *repository.IncidentRepository,
+	printService *PrintService,
+	auditService *AuditService,
+) *IncidentService {
+	return &IncidentService{
+		courseRepo:   courseRepo,
+		memberRepo:   memberRepo,
+		incidentRepo: incidentRepo,
+		printService: printService,
+		auditService: auditService,
+	}
+}
+
+// Report records an incident at a session. Without a location, the course location is assumed.
+func (s *IncidentService) Report(actor string, courseID uint, date time.Time, req IncidentRequest) (IncidentDTO, error) {
+	course, err := getCourse(s.courseRepo, courseID)
+	if err != nil {
+		return IncidentDTO{}, err
+	}
+	members, err := s.memberRepo.GetByIDs([]uint{req.MemberID})
+	if err != nil {
+		return IncidentDTO{}, err
+	}
+	if len(members) == 0 {
+		return IncidentDTO{}, ErrMemberNotFound
+	}
+	clock, err := time.Parse("15:04", req.Time)
+	if err != nil {
+		return IncidentDTO{}, fmt.Errorf("%w: invalid time", ErrInvalidIncident)
+	}
+	if strings.TrimSpace(req.Description) == "" {
+		return IncidentDTO{}, fmt.Errorf("%w: description missing", ErrInvalidIncident)
+	}
+
+	incident := model.Incident{
+		CourseID:    courseID,
+		Date:        date,
+		MemberID:    req.MemberID,
+		OccurredAt:  time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), 0, 0, time.Local),
+		Location:    strings.TrimSpace(req.Location),
+		Injury:      strings.TrimSpace(req.Injury),
+		Description: strings.TrimSpace(req.Description),
+		FirstAid:    strings.TrimSpace(req.FirstAid),
+		Witnesses:   strings.TrimSpace(req.Witnesses),
+		ReportedBy:  actor,
+		Status:      model.IncidentStatusReported,
+	}
+	if incident.Location == "" {
+		incident.Location = course.Location
+	}
+	if err := s.incidentRepo.Create(&incident); err != nil {
+		return IncidentDTO{}, err
+	}
+	if err := s.auditService.Record(actor, model.AuditActionCreate, model.AuditEntityIncident, fmt.Sprint(incident.ID), nil, incident); err != nil {
+		return IncidentDTO{}, err
+	}
+	return toIncidentDTO(incident, members[0], course), nil
+}
+
+// GetIncidents retrieves incidents by status, newest first; an empty status returns all
+func (s *IncidentService) GetIncidents(status string) ([]IncidentDTO, error) {
+	incidents, err := s.incidentRepo.GetByStatus(status)
+	if err != nil {
+		return nil, err
+	}
+	var memberIDs, courseIDs []uint
+	for _, incident := range incidents {
+		if !slices.Contains(memberIDs, incident.MemberID) {
+			memberIDs = append(memberIDs, incident.MemberID)
+		}
+		if !slices.Contains(courseIDs, incident.CourseID) {
+			courseIDs = append(courseIDs, incident.CourseID)
+		}
+	}
+	members := make(map[uint]model.Member)
+	courses := make(map[uint]model.Course)
+	if len(incidents) > 0 {
+		memberList, err := s.memberRepo.GetByIDs(memberIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range memberList {
+			members[member.ID] = member
+		}
+		courseList, err := s.courseRepo.GetByIDs(courseIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, course := range courseList {
+			courses[course.ID] = course
+		}
+	}
+
+	dtos := make([]IncidentDTO, 0, len(incidents))
+	for _, incident := range incidents {
+		dtos = append(dtos, toIncidentDTO(incident, members[incident.MemberID], courses[incident.CourseID]))
+	}
+	return dtos, nil
+}
+
+// GetIncident retrieves an incident by ID
+func (s *IncidentService) GetIncident(id uint) (IncidentDTO, error) {
+	incident, member, course, err := s.load(id)
+	if err != nil {
+		return IncidentDTO{}, err
+	}
+	return toIncidentDTO(incident, member, course), nil
+}
+
+// SetStatus moves an incident on to forwarded or closed. The insurer's reference can be recorded
+// when the incident is forwarded.
+func (s *IncidentService) SetStatus(actor string, id uint, req IncidentStatusRequest, now time.Time) (IncidentDTO, error) {
+	incident, member, course, err := s.load(id)
+	if err != nil {
+		return IncidentDTO{}, err
+	}
+	if !slices.Contains(incidentTransitions[incident.Status], req.Status) {
+		return IncidentDTO{}, ErrIncidentState
+	}
+
+	before := incident
+	incident.Status = req.Status
+	if reference := strings.TrimSpace(req.InsurerReference); reference != "" {
+		incident.InsurerReference = reference
+	}
+	switch req.Status {
+	case model.IncidentStatusForwarded:
+		incident.ForwardedAt = &now
+	case model.IncidentStatusClosed:
+		incident.ClosedAt = &now
+	}
+	if err := s.incidentRepo.Save(&incident); err != nil {
+		return IncidentDTO{}, err
+	}
+	if err := s.auditService.Record(actor, model.AuditActionUpdate, model.AuditEntityIncident, fmt.Sprint(incident.ID), before, incident); err != nil {
+		return IncidentDTO{}, err
+	}
+	return toIncidentDTO(incident, member, course), nil
+}
+
+// ReportPDF renders the report form of an incident for the sports insurance
+func (s *IncidentService) ReportPDF(id uint) ([]byte, error) {
+	incident, member, course, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.printService.IncidentReportPDF(incident, member, course)
+}
+
+// load retrieves an incident with the member and course involved
+func (s *IncidentService) load(id uint) (model.Incident, model.Member, model.Course, error) {
+	incident, err := s.incidentRepo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return incident, model.Member{}, model.Course{}, ErrIncidentNotFound
+	}
+	if err != nil {
+		return incident, model.Member{}, model.Course{}, err
+	}
+	var member model.Member
+	members, err := s.memberRepo.GetByIDs([]uint{incident.MemberID})
+	if err != nil {
+		return incident, member, model.Course{}, err
+	}
+	if len(members) > 0 {
+		member = members[0]
+	}
+	course, err := getCourse(s.courseRepo, incident.CourseID)
+	if err != nil && !errors.Is(err, ErrCourseNotFound) {
+		return incident, member, course, err
+	}
+	return incident, member, course, nil
+}
+
+// toIncidentDTO converts an incident into its DTO
+func toIncidentDTO(incident model.Incident, member model.Member, course model.Course) IncidentDTO {
+	return IncidentDTO{
+		Incident:   incident,
+		MemberName: strings.TrimSpace(member.FirstName + " " + member.LastName),
+		CourseName: course.Name,
+	}
+}
@@ -0,0 +1,563 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"azh/internal/model"
+	"azh/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Errors returned when maintaining locations
+var (
+	ErrInvalidLocation  = errors.New("invalid location")
+	ErrLocationNotFound = errors.New("location not found")
+	ErrLocationConflict = errors.New("location name, room or alias already in use")
+)
+
+// roomCheckWeeks is how far ahead room assignments are checked for conflicts
+const roomCheckWeeks = 12
+
+// LocationRequest holds the data of a location
+type LocationRequest struct {
+	Name         string                `json:"name"`
+	Address      string                `json:"address"`
+	Capacity     int                   `json:"capacity"`
+	OpeningHours []OpeningHoursRequest `json:"opening_hours"`
+}
+
+// OpeningHoursRequest holds the opening hours of a location on a weekday
+type OpeningHoursRequest struct {
+	Weekday string `json:"weekday"`
+	Opens   string `json:"opens"`  // HH:MM
+	Closes  string `json:"closes"` // HH:MM
+}
+
+// RoomRequest holds the data of a room
+type RoomRequest struct {
+	Name     string `json:"name"`
+	Capacity int    `json:"capacity"`
+}
+
+// AliasRequest maps a free-text location to a location and, optionally, one of its rooms
+type AliasRequest struct {
+	Alias  string `json:"alias"`
+	RoomID *uint  `json:"room_id"`
+}
+
+// CourseRoomDTO represents the location and room of a course with warnings about the assignment
+type CourseRoomDTO struct {
+	CourseID   uint     `json:"course_id"`
+	LocationID *uint    `json:"location_id"`
+	RoomID     *uint    `json:"room_id"`
+	Warnings   []string `json:"warnings"`
+}
+
+// RoomConflictDTO represents two courses scheduled in the same room at overlapping times
+type RoomConflictDTO struct {
+	RoomID          uint     `json:"room_id"`
+	Room            string   `json:"room"`
+	Location        string   `json:"location"`
+	CourseID        uint     `json:"course_id"`
+	CourseName      string   `json:"course_name"`
+	OtherCourseID   uint     `json:"other_course_id"`
+	OtherCourseName string   `json:"other_course_name"`
+	Dates           []string `json:"dates"`
+}
+
+// LocationService handles business logic for locations, rooms and the mapping of courses to them
+type LocationService struct {
+	courseRepo   *repository.CourseRepository
+	locationRepo *repository.LocationRepository
+	auditService *AuditService
+}
+
+// NewLocationService creates a new LocationService
+func NewLocationService(
+	courseRepo *repository.CourseRepository,
+	locationRepo *repository.LocationRepository,
+	auditService *AuditService,
+) *LocationService {
+	return &LocationService{
+		courseRepo:   courseRepo,
+		locationRepo: locationRepo,
+		auditService: auditService,
+	}
+}
+
+// GetLocations retrieves all locations with their rooms, opening hours and aliases
+func (s *LocationService) GetLocations() ([]model.Location, error) {
+	return s.locationRepo.GetAll()
+}
+
+// CreateLocation creates a location. Courses whose free-text location equals its name are linked
+// to it right away.
+func (s *LocationService) CreateLocation(actor string, req LocationRequest) (model.Location, error) {
+	var location model.Location
+	if err := s.applyLocationRequest(&location, req); err != nil {
+		return location, err
+	}
+	if err := s.locationRepo.Create(&location); err != nil {
+		return location, err
+	}
+	if err := s.auditService.Record(actor, model.AuditActionCreate, model.AuditEntityLocation, fmt.Sprint(location.ID), nil, location); err != nil {
+		return location, err
+	}
+	return location, s.NormalizeCourseLocations()
+}
+
+// UpdateLocation updates a location and replaces its opening hours
+func (s *LocationService) UpdateLocation(actor string, id uint, req LocationRequest) (model.Location, error) {
+	location, err := s.getLocation(id)
+	if err != nil {
+		return location, err
+	}
+	before := location
+	if err := s.applyLocationRequest(&location, req); err != nil {
+		return location, err
+	}
+	if err := s.locationRepo.Update(&location); err != nil {
+		return location, err
+	}
+	if err := s.auditService.Record(actor, model.AuditActionUpdate, model.AuditEntityLocation, fmt.Sprint(location.ID), before, location); err != nil {
+		return location, err
+	}
+	return location, s.NormalizeCourseLocations()
+}
+
+// AddRoom adds a room or area to a location
+func (s *LocationService) AddRoom(actor string, locationID uint, req RoomRequest) (model.Room, error) {
+	location, err := s.getLocation(locationID)
+	if err != nil {
+		return model.Room{}, err
+	}
+	room := model.Room{LocationID: locationID, Name: strings.TrimSpace(req.Name), Capacity: req.Capacity}
+	if room.Name == "" || room.Capacity < 0 {
+		return room, fmt.Errorf("%w: room name missing or negative capacity", ErrInvalidLocation)
+	}
+	for _, existing := range location.Rooms {
+		if strings.EqualFold(existing.Name, room.Name) {
+			return room, ErrLocationConflict
+		}
+	}
+	if err := s.locationRepo.CreateRoom(&room); err != nil {
+		return room, err
+	}
+	before := location
+	location.Rooms = append(location.Rooms, room)
+	return room, s.auditService.Record(actor, model.AuditActionUpdate, model.AuditEntityLocation, fmt.Sprint(locationID), before, location)
+}
+
+// AddAlias maps a free-text location to a location and relinks the courses using that text
+func (s *LocationService) AddAlias(actor string, locationID uint, req AliasRequest) (model.LocationAlias, error) {
+	location, err := s.getLocation(locationID)
+	if err != nil {
+		return model.LocationAlias{}, err
+	}
+	alias := model.LocationAlias{LocationID: locationID, RoomID: req.RoomID, Alias: normalizeAlias(req.Alias)}
+	if alias.Alias == "" {
+		return alias, fmt.Errorf("%w: alias missing", ErrInvalidLocation)
+	}
+	if alias.RoomID != nil && !hasRoom(location, *alias.RoomID) {
+		return alias, fmt.Errorf("%w: room %d does not belong to the location", ErrInvalidLocation, *alias.RoomID)
+	}
+	if _, err := s.locationRepo.FindAlias(alias.Alias); err == nil {
+		return alias, ErrLocationConflict
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return alias, err
+	}
+	if err := s.locationRepo.CreateAlias(&alias); err != nil {
+		return alias, err
+	}
+	before := location
+	location.Aliases = append(location.Aliases, alias)
+	if err := s.auditService.Record(actor, model.AuditActionUpdate, model.AuditEntityLocation, fmt.Sprint(locationID), before, location); err != nil {
+		return alias, err
+	}
+	return alias, s.NormalizeCourseLocations()
+}
+
+// RemoveAlias removes an alias; courses using its text lose their location unless it matches
+// another alias or location name
+func (s *LocationService) RemoveAlias(actor string, locationID, aliasID uint) error {
+	location, err := s.getLocation(locationID)
+	if err != nil {
+		return err
+	}
+	alias, err := s.locationRepo.DeleteAlias(locationID, aliasID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrLocationNotFound
+	}
+	if err != nil {
+		return err
+	}
+	after := location
+	after.Aliases = nil
+	for _, existing := range location.Aliases {
+		if existing.ID != alias.ID {
+			after.Aliases = append(after.Aliases, existing)
+		}
+	}
+	if err := s.auditService.Record(actor, model.AuditActionUpdate, model.AuditEntityLocation, fmt.Sprint(locationID), location, after); err != nil {
+		return err
+	}
+	return s.NormalizeCourseLocations()
+}
+
+// NormalizeCourseLocation links a course to the location its free-text location maps to, through
+// an alias or the location's name. The room named by the alias wins; otherwise a room chosen for
+// the course is kept while it belongs to the location. Reports whether the text could be mapped.
+func (s *LocationService) NormalizeCourseLocation(course model.Course) (bool, error) {
+	locationID, roomID, found, err := s.resolve(course.Location)
+	if err != nil {
+		return false, err
+	}
+	if roomID == nil && locationID != nil && sameID(course.LocationID, locationID) {
+		roomID = course.RoomID
+	}
+	if sameID(course.LocationID, locationID) && sameID(course.RoomID, roomID) {
+		return found, nil
+	}
+	return found, s.courseRepo.SetLocation(course.ID, locationID, roomID)
+}
+
+// NormalizeCourseLocations links all courses to their locations, e.g. after aliases changed
+func (s *LocationService) NormalizeCourseLocations() error {
+	courses, err := s.courseRepo.GetAllUnfiltered()
+	if err != nil {
+		return err
+	}
+	for _, course := range courses {
+		if _, err := s.NormalizeCourseLocation(course); err != nil {
+			return fmt.Errorf("error normalizing location of course %d: %v", course.ID, err)
+		}
+	}
+	return nil
+}
+
+// GetUnmappedLocations lists the free-text locations of courses that no alias or location name
+// matches, so they can be mapped
+func (s *LocationService) GetUnmappedLocations() ([]string, error) {
+	courses, err := s.courseRepo.GetAllUnfiltered()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{})
+	unmapped := []string{}
+	for _, course := range courses {
+		text := strings.TrimSpace(course.Location)
+		if course.LocationID != nil || text == "" {
+			continue
+		}
+		if _, ok := seen[normalizeAlias(text)]; ok {
+			continue
+		}
+		seen[normalizeAlias(text)] = struct{}{}
+		unmapped = append(unmapped, text)
+	}
+	sort.Strings(unmapped)
+	return unmapped, nil
+}
+
+// SetCourseRoom assigns a room of the course's location to a course, or clears it with a nil room.
+// The warnings cover room conflicts, capacity and opening hours over the next weeks.
+func (s *LocationService) SetCourseRoom(actor string, courseID uint, roomID *uint, today time.Time) (CourseRoomDTO, error) {
+	course, err := getCourse(s.courseRepo, courseID)
+	if err != nil {
+		return CourseRoomDTO{}, err
+	}
+	if course.LocationID == nil {
+		return CourseRoomDTO{}, fmt.Errorf("%w: course has no location", ErrInvalidLocation)
+	}
+	location, err := s.getLocation(*course.LocationID)
+	if err != nil {
+		return CourseRoomDTO{}, err
+	}
+	if roomID != nil && !hasRoom(location, *roomID) {
+		return CourseRoomDTO{}, fmt.Errorf("%w: room %d does not belong to the location", ErrInvalidLocation, *roomID)
+	}
+
+	before := course
+	course.RoomID = roomID
+	if err := s.courseRepo.SetLocation(course.ID, course.LocationID, course.RoomID); err != nil {
+		return CourseRoomDTO{}, err
+	}
+	if err := s.auditService.Record(actor, model.AuditActionUpdate, model.AuditEntityCourse, fmt.Sprint(course.ID), before, course); err != nil {
+		return CourseRoomDTO{}, err
+	}
+
+	dto := CourseRoomDTO{CourseID: course.ID, LocationID: course.LocationID, RoomID: course.RoomID, Warnings: []string{}}
+	from := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
+	to := from.AddDate(0, 0, 7*roomCheckWeeks)
+	dto.Warnings = append(dto.Warnings, openingHoursWarnings(course, location, from, to)...)
+	if roomID == nil {
+		return dto, nil
+	}
+	for _, room := range location.Rooms {
+		if room.ID == *roomID && room.Capacity > 0 && course.MaxParticipants > room.Capacity {
+			dto.Warnings = append(dto.Warnings, fmt.Sprintf("Raum %s hat nur %d Plätze, der Kurs %d", room.Name,
+				room.Capacity, course.MaxParticipants))
+		}
+	}
+	conflicts, err := s.GetRoomConflicts(from, to)
+	if err != nil {
+		return dto, err
+	}
+	for _, conflict := range conflicts {
+		other, otherName := conflict.OtherCourseID, conflict.OtherCourseName
+		if other == course.ID {
+			other, otherName = conflict.CourseID, conflict.CourseName
+		} else if conflict.CourseID != course.ID {
+			continue
+		}
+		dto.Warnings = append(dto.Warnings, fmt.Sprintf("Raum %s ist an %d Terminen ab %s auch durch Kurs %d %s belegt",
+			conflict.Room, len(conflict.Dates), formatDate(conflict.Dates[0]), other, otherName))
+	}
+	return dto, nil
+}
+
+// GetRoomConflicts finds courses scheduled in the same room at overlapping times between from and
+// to (inclusive). Courses without a room are not checked.
+func (s *LocationService) GetRoomConflicts(from, to time.Time) ([]RoomConflictDTO, error) {
+	courses, err := s.courseRepo.GetActiveBetween(from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+
+	type slot struct {
+		course     model.Course
+		start, end time.Time
+	}
+	slots := make(map[string][]slot)
+	conflicts := make(map[string]*RoomConflictDTO)
+	var keys []string
+	var roomIDs []uint
+	for _, course := range courses {
+		if course.RoomID == nil {
+			continue
+		}
+		roomIDs = append(roomIDs, *course.RoomID)
+		for _, dateStr := range scheduledDates(course, from, to) {
+			date, _ := time.Parse("2006-01-02", dateStr)
+			start, end, ok := sessionClock(course, date)
+			if !ok {
+				continue
+			}
+			slotKey := fmt.Sprintf("%d-%s", *course.RoomID, dateStr)
+			for _, other := range slots[slotKey] {
+				if !start.Before(other.end) || !other.start.Before(end) {
+					continue
+				}
+				key := fmt.Sprintf("%d-%d-%d", *course.RoomID, other.course.ID, course.ID)
+				if conflicts[key] == nil {
+					conflicts[key] = &RoomConflictDTO{
+						RoomID:          *course.RoomID,
+						CourseID:        other.course.ID,
+						CourseName:      other.course.Name,
+						OtherCourseID:   course.ID,
+						OtherCourseName: course.Name,
+					}
+					keys = append(keys, key)
+				}
+				conflicts[key].Dates = append(conflicts[key].Dates, dateStr)
+			}
+			slots[slotKey] = append(slots[slotKey], slot{course: course, start: start, end: end})
+		}
+	}
+	if len(keys) == 0 {
+		return []RoomConflictDTO{}, nil
+	}
+
+	rooms, err := s.locationRepo.GetRoomsByIDs(roomIDs)
+	if err != nil {
+		return nil, err
+	}
+	locations, err := s.locationRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	roomNames := make(map[uint]string, len(rooms))
+	roomLocations := make(map[uint]string, len(rooms))
+	for _, room := range rooms {
+		roomNames[room.ID] = room.Name
+		for _, location := range locations {
+			if location.ID == room.LocationID {
+				roomLocations[room.ID] = location.Name
+			}
+		}
+	}
+	result := make([]RoomConflictDTO, 0, len(keys))
+	for _, key := range keys {
+		conflict := conflicts[key]
+		conflict.Room, conflict.Location = roomNames[conflict.RoomID], roomLocations[conflict.RoomID]
+		result = append(result, *conflict)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].RoomID != result[j].RoomID {
+			return result[i].RoomID < result[j].RoomID
+		}
+		return result[i].Dates[0] < result[j].Dates[0]
+	})
+	return result, nil
+}
+
+// resolve maps a free-text location to a location and room through the aliases and, failing
+// that, the location names. An empty text maps to no location.
+func (s *LocationService) resolve(text string) (*uint, *uint, bool, error) {
+	normalized := normalizeAlias(text)
+	if normalized == "" {
+		return nil, nil, true, nil
+	}
+	alias, err := s.locationRepo.FindAlias(normalized)
+	if err == nil {
+		return &alias.LocationID, alias.RoomID, true, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, false, err
+	}
+	location, err := s.locationRepo.FindByName(normalized)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, false, nil
+	}
+	if err != nil {
+		return nil, nil, false, err
+	}
+	return &location.ID, nil, true, nil
+}
+
+// applyLocationRequest validates a location request and applies it to a location
+func (s *LocationService) applyLocationRequest(location *model.Location, req LocationRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return fmt.Errorf("%w: name missing", ErrInvalidLocation)
+	}
+	if req.Capacity < 0 {
+		return fmt.Errorf("%w: negative capacity", ErrInvalidLocation)
+	}
+	if existing, err := s.locationRepo.FindByName(name); err == nil && existing.ID != location.ID {
+		return ErrLocationConflict
+	} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	hours := make([]model.OpeningHours, 0, len(req.OpeningHours))
+	for _, hoursReq := range req.OpeningHours {
+		if _, ok := weekdayMap[hoursReq.Weekday]; !ok {
+			return fmt.Errorf("%w: unknown weekday %s", ErrInvalidLocation, hoursReq.Weekday)
+		}
+		opens, err := time.Parse("15:04", hoursReq.Opens)
+		if err != nil {
+			return fmt.Errorf("%w: invalid opening hours on %s", ErrInvalidLocation, hoursReq.Weekday)
+		}
+		closes, err := time.Parse("15:04", hoursReq.Closes)
+		if err != nil || !closes.After(opens) {
+			return fmt.Errorf("%w: invalid opening hours on %s", ErrInvalidLocation, hoursReq.Weekday)
+		}
+		hours = append(hours, model.OpeningHours{Weekday: hoursReq.Weekday, Opens: hoursReq.Opens, Closes: hoursReq.Closes})
+	}
+
+	location.Name = name
+	location.Address = strings.TrimSpace(req.Address)
+	location.Capacity = req.Capacity
+	location.OpeningHours = hours
+	return nil
+}
+
+// getLocation retrieves a location by ID and maps a missing record to ErrLocationNotFound
+func (s *LocationService) getLocation(id uint) (model.Location, error) {
+	location, err := s.locationRepo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return location, ErrLocationNotFound
+	}
+	return location, err
+}
+
+// openingHoursWarnings lists the weekdays on which sessions of a course between from and to fall
+// outside the opening hours of its location
+func openingHoursWarnings(course model.Course, location model.Location, from, to time.Time) []string {
+	if len(location.OpeningHours) == 0 {
+		return nil
+	}
+	var warnings []string
+	warned := make(map[time.Weekday]struct{})
+	for _, dateStr := range scheduledDates(course, from, to) {
+		date, _ := time.Parse("2006-01-02", dateStr)
+		if _, ok := warned[date.Weekday()]; ok {
+			continue
+		}
+		start, end, ok := sessionClock(course, date)
+		if !ok || isOpen(location.OpeningHours, date.Weekday(), start, end) {
+			continue
+		}
+		warned[date.Weekday()] = struct{}{}
+		warnings = append(warnings, fmt.Sprintf("%s ist am %s um %s Uhr geschlossen", location.Name,
+			germanWeekday(date.Weekday()), start.Format("15:04")))
+	}
+	return warnings
+}
+
+// isOpen reports whether opening hours on a weekday cover the time from start to end
+func isOpen(hours []model.OpeningHours, weekday time.Weekday, start, end time.Time) bool {
+	for _, h := range hours {
+		if weekdayMap[h.Weekday] != weekday {
+			continue
+		}
+		opens, err := time.Parse("15:04", h.Opens)
+		if err != nil {
+			continue
+		}
+		closes, err := time.Parse("15:04", h.Closes)
+		if err != nil {
+			continue
+		}
+		if !start.Before(opens) && !end.After(closes) {
+			return true
+		}
+	}
+	return false
+}
+
+// germanWeekday returns the German name of a weekday as used in course data
+func germanWeekday(weekday time.Weekday) string {
+	for name, day := range weekdayMap {
+		if day == weekday {
+			return name
+		}
+	}
+	return ""
+}
+
+// hasRoom reports whether a room belongs to a location
+func hasRoom(location model.Location, roomID uint) bool {
+	for _, room := range location.Rooms {
+		if room.ID == roomID {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeAlias trims a free-text location, collapses its spaces and converts it to lower case
+func normalizeAlias(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// sameID reports whether two optional IDs are equal
+func sameID(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// formatDate formats a YYYY-MM-DD date the German way
+func formatDate(date string) string {
+	parsed, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	return parsed.Format("02.01.2006")
+}
@@ -0,0 +1,365 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"strings"
+	"time"
+
+	"azh/internal/model"
+	"azh/internal/notify"
+	"azh/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Errors returned by the notification service
+var (
+	ErrInvalidNotification  = errors.New("invalid notification")
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrNotificationState    = errors.New("only failed notifications can be retried")
+)
+
+// outboxBatchSize is the number of due notifications sent per outbox run
+const outboxBatchSize = 100
+
+// OutboxSettings configures the delivery of queued notifications
+type OutboxSettings struct {
+	MaxAttempts int
+	RetryDelay  time.Duration // delay before the first retry, doubled for every further attempt
+	Interval    time.Duration // how often the outbox is checked for due notifications
+}
+
+// NotificationSettingRequest holds how a member wants to be notified
+type NotificationSettingRequest struct {
+	Language string `json:"language"`
+	Channel  string `json:"channel"`
+	OptOut   bool   `json:"opt_out"`
+}
+
+// TestNotificationRequest addresses a test message to a recipient over a channel
+type TestNotificationRequest struct {
+	Channel   string `json:"channel"`
+	Recipient string `json:"recipient"`
+	Language  string `json:"language"`
+}
+
+// NotificationService renders templated messages and queues them in the outbox, from which they
+// are delivered over the configured channels in the background
+type NotificationService struct {
+	notificationRepo *repository.NotificationRepository
+	memberRepo       *repository.MemberRepository
+	templates        *notify.Templates
+	channels         map[string]notify.Channel
+	club             ClubInfo
+	auditService     *AuditService
+	outbox           OutboxSettings
+	wake             chan struct{}
+}
+
+// NewNotificationService creates a new NotificationService delivering over the given channels
+func NewNotificationService(
+	notificationRepo *repository.NotificationRepository,
+	memberRepo *repository.MemberRepository,
+	templates *notify.Templates,
+	channels []notify.Channel,
+	club ClubInfo,
+	auditService *AuditService,
+	outbox OutboxSettings,
+) *NotificationService {
+	byName := make(map[string]notify.Channel, len(channels))
+	for _, channel := range channels {
+		byName[channel.Name()] = channel
+	}
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+		memberRepo:       memberRepo,
+		templates:        templates,
+		channels:         byName,
+		club:             club,
+		auditService:     auditService,
+		outbox:           outbox,
+		wake:             make(chan struct{}, 1),
+	}
+}
+
+// Channels lists the names of the configured channels
+func (s *NotificationService) Channels() []string {
+	names := make([]string, 0, len(s.channels))
+	for _, name := range []string{notify.ChannelEmail, notify.ChannelWebhook, notify.ChannelFile} {
+		if _, ok := s.channels[name]; ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// NotifyMembers queues a templated message to each of the given members, in their language and
+// over their channel. Members who opted out or cannot be reached get a notification record
+// without delivery, so the outcome can be tracked per member through the reference.
+func (s *NotificationService) NotifyMembers(memberIDs []uint, template, reference string, data map[string]interface{}, now time.Time) ([]model.Notification, error) {
+	if !s.templates.Has(template) {
+		return nil, fmt.Errorf("%w: unknown template %s", ErrInvalidNotification, template)
+	}
+	if len(memberIDs) == 0 {
+		return []model.Notification{}, nil
+	}
+	members, err := s.memberRepo.GetByIDs(memberIDs)
+	if err != nil {
+		return nil, err
+	}
+	settingList, err := s.notificationRepo.GetSettings(memberIDs)
+	if err != nil {
+		return nil, err
+	}
+	settings := make(map[uint]model.NotificationSetting, len(settingList))
+	for _, setting := range settingList {
+		settings[setting.MemberID] = setting
+	}
+
+	notifications := make([]model.Notification, 0, len(members))
+	for _, member := range members {
+		setting, ok := settings[member.ID]
+		if !ok {
+			setting = model.NotificationSetting{MemberID: member.ID, Language: notify.LanguageGerman}
+		}
+		channel, recipient := s.route(member, setting)
+		memberID := member.ID
+		notification := model.Notification{
+			Channel:       channel,
+			Recipient:     recipient,
+			MemberID:      &memberID,
+			Template:      template,
+			Language:      setting.Language,
+			Reference:     reference,
+			Status:        model.NotificationPending,
+			NextAttemptAt: now,
+		}
+		switch {
+		case setting.OptOut:
+			notification.Status = model.NotificationOptedOut
+		case channel == "" || recipient == "":
+			notification.Status = model.NotificationFailed
+			notification.LastError = "no channel or contact data to reach the member"
+		}
+		if notification.Status != model.NotificationOptedOut {
+			if err := s.render(&notification, member.FirstName, data); err != nil {
+				return nil, err
+			}
+		}
+		notifications = append(notifications, notification)
+	}
+	if err := s.notificationRepo.CreateAll(notifications); err != nil {
+		return nil, err
+	}
+	s.Wake()
+	return notifications, nil
+}
+
+// SendTest queues a test message to any recipient, e.g. to check the configuration of a channel
+func (s *NotificationService) SendTest(req TestNotificationRequest, now time.Time) (model.Notification, error) {
+	if _, ok := s.channels[req.Channel]; !ok {
+		return model.Notification{}, fmt.Errorf("%w: channel %s not configured", ErrInvalidNotification, req.Channel)
+	}
+	if strings.TrimSpace(req.Recipient) == "" {
+		return model.Notification{}, fmt.Errorf("%w: recipient missing", ErrInvalidNotification)
+	}
+	language := req.Language
+	if language == "" {
+		language = notify.LanguageGerman
+	}
+	notification := model.Notification{
+		Channel:       req.Channel,
+		Recipient:     strings.TrimSpace(req.Recipient),
+		Template:      "test",
+		Language:      language,
+		Status:        model.NotificationPending,
+		NextAttemptAt: now,
+	}
+	if err := s.render(&notification, notification.Recipient, nil); err != nil {
+		return notification, err
+	}
+	if err := s.notificationRepo.CreateAll([]model.Notification{notification}); err != nil {
+		return notification, err
+	}
+	s.Wake()
+	return notification, nil
+}
+
+// GetNotifications retrieves the latest notifications by reference and status; empty filters match all
+func (s *NotificationService) GetNotifications(reference, status string) ([]model.Notification, error) {
+	return s.notificationRepo.Find(reference, status, 500)
+}
+
+// Retry queues a failed notification again with a fresh set of attempts
+func (s *NotificationService) Retry(id uint, now time.Time) (model.Notification, error) {
+	notification, err := s.notificationRepo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return notification, ErrNotificationNotFound
+	}
+	if err != nil {
+		return notification, err
+	}
+	if notification.Status != model.NotificationFailed || notification.Body == "" {
+		return notification, ErrNotificationState
+	}
+	notification.Status = model.NotificationPending
+	notification.Attempts = 0
+	notification.NextAttemptAt = now
+	if err := s.notificationRepo.Save(&notification); err != nil {
+		return notification, err
+	}
+	s.Wake()
+	return notification, nil
+}
+
+// GetSetting retrieves the notification settings of a member, or the defaults if there are none
+func (s *NotificationService) GetSetting(memberID uint) (model.NotificationSetting, error) {
+	members, err := s.memberRepo.GetByIDs([]uint{memberID})
+	if err != nil {
+		return model.NotificationSetting{}, err
+	}
+	if len(members) == 0 {
+		return model.NotificationSetting{}, ErrMemberNotFound
+	}
+	settings, err := s.notificationRepo.GetSettings([]uint{memberID})
+	if err != nil {
+		return model.NotificationSetting{}, err
+	}
+	if len(settings) == 0 {
+		return model.NotificationSetting{MemberID: memberID, Language: notify.LanguageGerman}, nil
+	}
+	return settings[0], nil
+}
+
+// SetSetting updates the notification settings of a member
+func (s *NotificationService) SetSetting(actor string, memberID uint, req NotificationSettingRequest) (model.NotificationSetting, error) {
+	before, err := s.GetSetting(memberID)
+	if err != nil {
+		return before, err
+	}
+	if req.Language != notify.LanguageGerman && req.Language != notify.LanguageEnglish {
+		return before, fmt.Errorf("%w: unknown language %s", ErrInvalidNotification, req.Language)
+	}
+	if _, ok := s.channels[req.Channel]; req.Channel != "" && !ok {
+		return before, fmt.Errorf("%w: channel %s not configured", ErrInvalidNotification, req.Channel)
+	}
+	setting := model.NotificationSetting{MemberID: memberID, Language: req.Language, Channel: req.Channel, OptOut: req.OptOut}
+	if err := s.notificationRepo.SaveSetting(&setting); err != nil {
+		return setting, err
+	}
+	return setting, s.auditService.Record(actor, model.AuditActionUpdate, model.AuditEntityNotificationSetting,
+		fmt.Sprint(memberID), before, setting)
+}
+
+// StartOutbox delivers due notifications in the background, at the configured interval and
+// whenever new notifications are queued
+func (s *NotificationService) StartOutbox() {
+	go func() {
+		ticker := time.NewTicker(s.outbox.Interval)
+		defer ticker.Stop()
+		for {
+			if err := s.ProcessOutbox(time.Now()); err != nil {
+				log.Printf("Failed to process notification outbox: %v", err)
+			}
+			select {
+			case <-ticker.C:
+			case <-s.wake:
+			}
+		}
+	}()
+}
+
+// Wake makes the outbox deliver queued notifications right away
+func (s *NotificationService) Wake() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// ProcessOutbox delivers the due notifications. Failed deliveries are retried with doubling delay
+// until the maximum number of attempts is reached.
+func (s *NotificationService) ProcessOutbox(now time.Time) error {
+	for {
+		due, err := s.notificationRepo.GetDue(now, outboxBatchSize)
+		if err != nil {
+			return err
+		}
+		for i := range due {
+			if err := s.deliver(&due[i], now); err != nil {
+				return err
+			}
+		}
+		if len(due) < outboxBatchSize {
+			return nil
+		}
+	}
+}
+
+// deliver sends a notification once and records the outcome
+func (s *NotificationService) deliver(notification *model.Notification, now time.Time) error {
+	notification.Attempts++
+	var err error
+	if channel, ok := s.channels[notification.Channel]; ok {
+		err = channel.Send(notify.Message{To: notification.Recipient, Subject: notification.Subject, Body: notification.Body})
+	} else {
+		err = fmt.Errorf("channel %s not configured", notification.Channel)
+	}
+	switch {
+	case err == nil:
+		notification.Status = model.NotificationSent
+		notification.SentAt = &now
+		notification.LastError = ""
+	case notification.Attempts >= s.outbox.MaxAttempts:
+		notification.Status = model.NotificationFailed
+		notification.LastError = err.Error()
+	default:
+		notification.NextAttemptAt = now.Add(s.outbox.RetryDelay << (notification.Attempts - 1))
+		notification.LastError = err.Error()
+	}
+	return s.notificationRepo.Save(notification)
+}
+
+// route picks the channel and recipient address of a member: the channel chosen in the settings,
+// otherwise email if the member has an address, the webhook if there is a phone number, and the
+// file channel as last resort
+func (s *NotificationService) route(member model.Member, setting model.NotificationSetting) (string, string) {
+	addresses := map[string]string{
+		notify.ChannelEmail:   member.Email,
+		notify.ChannelWebhook: member.Phone,
+		notify.ChannelFile:    member.Email,
+	}
+	if addresses[notify.ChannelFile] == "" {
+		addresses[notify.ChannelFile] = member.Phone
+	}
+	if setting.Channel != "" {
+		if _, ok := s.channels[setting.Channel]; !ok {
+			return setting.Channel, ""
+		}
+		return setting.Channel, strings.TrimSpace(addresses[setting.Channel])
+	}
+	for _, name := range s.Channels() {
+		if address := strings.TrimSpace(addresses[name]); address != "" {
+			return name, address
+		}
+	}
+	return "", ""
+}
+
+// render fills in subject and body of a notification from its template
+func (s *NotificationService) render(notification *model.Notification, recipientName string, data map[string]interface{}) error {
+	values := maps.Clone(data)
+	if values == nil {
+		values = make(map[string]interface{})
+	}
+	values["Club"] = s.club.Name
+	values["Recipient"] = recipientName
+	subject, body, err := s.templates.Render(notification.Template, notification.Language, values)
+	if err != nil {
+		return fmt.Errorf("error rendering notification %s: %v", notification.Template, err)
+	}
+	notification.Subject, notification.Body = subject, body
+	return nil
+}
@@ -1,31 +1,62 @@
 package service
 
 import (
+	"cmp"
 	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"azh/internal/model"
+	"azh/internal/pubsub"
 	"azh/internal/repository"
+	"gorm.io/gorm"
 )
 
+// ErrNoPreviousSession is returned when attendance is copied from a course without earlier sessions
+var ErrNoPreviousSession = errors.New("no previous session")
+
 // ParticipantDTO represents the data transfer object for participants
 type ParticipantDTO struct {
-	ID        uint   `json:"id"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
-	Phone     string `json:"phone"`
-	Notes     string `json:"notes"`
-	Present   bool   `json:"present"`
+	ID            uint   `json:"id"`
+	FirstName     string `json:"first_name"`
+	LastName      string `json:"last_name"`
+	Phone         string `json:"phone"`
+	Notes         string `json:"notes"`
+	Present       bool   `json:"present"`
+	Status        string `json:"status"`
+	Credits       *int   `json:"credits,omitempty"`        // punch card balance of drop-in participants
+	AbsenceReason string `json:"absence_reason,omitempty"` // reported in advance by a guardian
+}
+
+// AttendanceEvent represents an attendance change published to the open participant lists of a session
+type AttendanceEvent struct {
+	CourseID uint   `json:"course_id"`
+	Date     string `json:"date"`
+	MemberID uint   `json:"member_id"`
+	Present  bool   `json:"present"`
+	Status   string `json:"status"`
+	Credits  *int   `json:"credits,omitempty"`
 }
 
 // ParticipationService handles business logic for participations
 type ParticipationService struct {
-	courseRepo        *repository.CourseRepository
-	memberCourseRepo  *repository.MemberCourseRepository
-	participationRepo *repository.ParticipationRepository
-	memberRepo        *repository.MemberRepository
+	courseRepo           *repository.CourseRepository
+	memberCourseRepo     *repository.MemberCourseRepository
+	participationRepo    *repository.ParticipationRepository
+	memberRepo           *repository.MemberRepository
+	punchCardRepo        *repository.PunchCardRepository
+	attendanceChangeRepo *repository.AttendanceChangeRepository
+	guardianRepo         *repository.GuardianRepository
+	sessionLockService   *SessionLockService
+	sessionService       *SessionService
+	auditService         *AuditService
+	broker               pubsub.Broker
 }
 
 // NewParticipationService creates a new ParticipationService
@@ -34,12 +65,26 @@ func NewParticipationService(
 	memberCourseRepo *repository.MemberCourseRepository,
 	participationRepo *repository.ParticipationRepository,
 	memberRepo *repository.MemberRepository,
+	punchCardRepo *repository.PunchCardRepository,
+	attendanceChangeRepo *repository.AttendanceChangeRepository,
+	guardianRepo *repository.GuardianRepository,
+	sessionLockService *SessionLockService,
+	sessionService *SessionService,
+	auditService *AuditService,
+	broker pubsub.Broker,
 ) *ParticipationService {
 	return &ParticipationService{
-		courseRepo:        courseRepo,
-		memberCourseRepo:  memberCourseRepo,
-		participationRepo: participationRepo,
-		memberRepo:        memberRepo,
+		courseRepo:           courseRepo,
+		memberCourseRepo:     memberCourseRepo,
+		participationRepo:    participationRepo,
+		memberRepo:           memberRepo,
+		punchCardRepo:        punchCardRepo,
+		attendanceChangeRepo: attendanceChangeRepo,
+		guardianRepo:         guardianRepo,
+		sessionLockService:   sessionLockService,
+		sessionService:       sessionService,
+		auditService:         auditService,
+		broker:               broker,
 	}
 }
 
@@ -57,82 +102,478 @@ func (s *ParticipationService) GetParticipants(courseID, date string) ([]Partici
 		return nil, err
 	}
 
-	// Fetch member details, filtered by sign_up_date and cancellation_date
-	members, err := s.memberRepo.GetByIDsAndDate(memberIDs, selectedDate)
+	// Get participation records for the course and date
+	participations, err := s.participationRepo.GetByCourseAndDate(courseID, date)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get participation records for the course and date
-	participations, err := s.participationRepo.GetByCourseAndDate(courseID, date)
+	// Drop-in sessions are also open to punch card holders who are not enrolled
+	course, err := s.courseRepo.GetByID(courseID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	enrolled := make(map[uint]struct{}, len(memberIDs))
+	for _, id := range memberIDs {
+		enrolled[id] = struct{}{}
+	}
+	var balances map[uint]int
+	if course.DropIn {
+		holderIDs, err := s.punchCardRepo.GetHolderIDs(selectedDate)
+		if err != nil {
+			return nil, err
+		}
+		memberIDs = append(memberIDs, holderIDs...)
+		for _, p := range participations {
+			memberIDs = append(memberIDs, p.MemberID)
+		}
+		if balances, err = s.punchCardRepo.GetBalances(memberIDs, selectedDate); err != nil {
+			return nil, err
+		}
+	}
+
+	// Fetch member details, filtered by sign_up_date and cancellation_date
+	members, err := s.memberRepo.GetByIDsAndDate(memberIDs, selectedDate)
 	if err != nil {
 		return nil, err
 	}
 
-	// Map participations by member ID for quick lookup
-	participationMap := make(map[uint]struct{})
+	// Map participation statuses by member ID for quick lookup
+	participationMap := make(map[uint]string)
 	for _, p := range participations {
-		participationMap[p.MemberID] = struct{}{}
+		participationMap[p.MemberID] = p.Status
+	}
+	absences, err := s.guardianRepo.GetAbsencesBySession(courseID, date)
+	if err != nil {
+		return nil, err
+	}
+	absenceReasons := make(map[uint]string, len(absences))
+	for _, absence := range absences {
+		absenceReasons[absence.MemberID] = absence.Reason
 	}
 
 	// Build participant DTOs
 	participants := make([]ParticipantDTO, 0, len(members))
 	for _, member := range members {
-		_, present := participationMap[member.ID]
-		participants = append(participants, ParticipantDTO{
+		status, ok := participationMap[member.ID]
+		if !ok {
+			status = model.ParticipationStatusAbsent
+		}
+		participant := ParticipantDTO{
 			ID:        member.ID,
 			FirstName: member.FirstName,
 			LastName:  member.LastName,
 			Phone:     member.Phone,
 			Notes:     member.Notes,
-			Present:   present,
-		})
+			Present:   status == model.ParticipationStatusPresent,
+			Status:    status,
+		}
+		if status == model.ParticipationStatusExcused {
+			participant.AbsenceReason = absenceReasons[member.ID]
+		}
+		if _, isEnrolled := enrolled[member.ID]; course.DropIn && !isEnrolled {
+			credits := balances[member.ID]
+			participant.Credits = &credits
+		}
+		participants = append(participants, participant)
 	}
 	return participants, nil
 }
 
-// SetAttendance updates the attendance status for a participant
-func (s *ParticipationService) SetAttendance(courseID uint, date time.Time, memberID uint, present bool) error {
-	if present {
-		participation := &model.Participation{
-			MemberID: memberID,
-			CourseID: courseID,
-			Date:     date,
+// AttendanceStore stores attendance changes together with their journal and audit entries in one
+// transaction, like ParticipationRepository.ApplyChanges, possibly along with records depending on them
+type AttendanceStore func(changes []model.AttendanceChange, dropIn map[uint]bool, auditEntries []model.AuditEntry) error
+
+// SetAttendance updates the attendance status for a participant and publishes the change to the
+// open participant lists of the session. In drop-in courses, members who are not enrolled pay with
+// a punch card credit when marked present and get it refunded when the mark is undone.
+func (s *ParticipationService) SetAttendance(actor string, courseID uint, date time.Time, memberID uint, status string) error {
+	return s.SetAttendanceWith(actor, courseID, date, memberID, status, s.participationRepo.ApplyChanges)
+}
+
+// SetAttendanceWith updates the attendance status for a participant like SetAttendance, but stores
+// the change through the given store, so records depending on it are written in the same transaction
+func (s *ParticipationService) SetAttendanceWith(actor string, courseID uint, date time.Time, memberID uint, status string, store AttendanceStore) error {
+	return s.applyAttendance(&model.AttendanceChange{
+		Actor:     actor,
+		MemberID:  memberID,
+		CourseID:  courseID,
+		Date:      date,
+		Status:    status,
+		ChangedAt: time.Now(),
+	}, store)
+}
+
+// applyAttendance stores a single attendance change through the given store
+func (s *ParticipationService) applyAttendance(change *model.AttendanceChange, store AttendanceStore) error {
+	course, err := getCourse(s.courseRepo, change.CourseID)
+	if err != nil {
+		return err
+	}
+	previous, err := s.participationRepo.GetStatus(change.MemberID, change.CourseID, change.Date)
+	if err != nil {
+		return err
+	}
+	dropIn := false
+	if course.DropIn {
+		enrolled, err := s.memberCourseRepo.Exists(change.MemberID, change.CourseID)
+		if err != nil {
+			return err
+		}
+		dropIn = !enrolled
+	}
+	if change.ChangeID == "" {
+		if change.ChangeID, err = newToken(); err != nil {
+			return err
+		}
+	}
+	change.Outcome = model.AttendanceChangeApplied
+
+	changes := []model.AttendanceChange{*change}
+	if err := s.storeChanges(changes, map[uint]string{change.MemberID: previous}, map[uint]bool{change.MemberID: dropIn}, store); err != nil {
+		return err
+	}
+	*change = changes[0]
+	return nil
+}
+
+// AttendanceUpdate represents the new status of a member in a bulk attendance change
+type AttendanceUpdate struct {
+	MemberID uint   `json:"member_id"`
+	Status   string `json:"status"`
+}
+
+// SetAttendanceBulk updates the attendance status of several members of a session in one transaction
+func (s *ParticipationService) SetAttendanceBulk(actor string, courseID uint, date time.Time, updates []AttendanceUpdate) error {
+	statuses := make(map[uint]string, len(updates))
+	for _, update := range updates {
+		statuses[update.MemberID] = update.Status
+	}
+	return s.applyStatuses(actor, courseID, date, statuses)
+}
+
+// MarkAllPresent marks all members enrolled in a course present, except those already excused.
+// Drop-in participants who are not enrolled are left out, as each visit costs them a credit.
+func (s *ParticipationService) MarkAllPresent(actor string, courseID uint, date time.Time) error {
+	memberIDs, err := s.activeEnrolledIDs(courseID, date)
+	if err != nil {
+		return err
+	}
+	current, err := s.sessionStatuses(courseID, date)
+	if err != nil {
+		return err
+	}
+	statuses := make(map[uint]string, len(memberIDs))
+	for _, memberID := range memberIDs {
+		if current[memberID] != model.ParticipationStatusExcused {
+			statuses[memberID] = model.ParticipationStatusPresent
+		}
+	}
+	return s.applyStatuses(actor, courseID, date, statuses)
+}
+
+// CopyPreviousAttendance copies the statuses of the enrolled members from the latest earlier session
+// of a course and returns the date of that session
+func (s *ParticipationService) CopyPreviousAttendance(actor string, courseID uint, date time.Time) (time.Time, error) {
+	previous, err := s.participationRepo.GetPreviousSessionDate(courseID, date)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return previous, ErrNoPreviousSession
+	}
+	if err != nil {
+		return previous, err
+	}
+	memberIDs, err := s.activeEnrolledIDs(courseID, date)
+	if err != nil {
+		return previous, err
+	}
+	previousStatuses, err := s.sessionStatuses(courseID, previous)
+	if err != nil {
+		return previous, err
+	}
+	statuses := make(map[uint]string, len(memberIDs))
+	for _, memberID := range memberIDs {
+		status, ok := previousStatuses[memberID]
+		if !ok {
+			status = model.ParticipationStatusAbsent
+		}
+		statuses[memberID] = status
+	}
+	return previous, s.applyStatuses(actor, courseID, date, statuses)
+}
+
+// ClearAttendance resets all participants of a session to absent, refunding drop-in credits
+func (s *ParticipationService) ClearAttendance(actor string, courseID uint, date time.Time) error {
+	current, err := s.sessionStatuses(courseID, date)
+	if err != nil {
+		return err
+	}
+	statuses := make(map[uint]string, len(current))
+	for memberID := range current {
+		statuses[memberID] = model.ParticipationStatusAbsent
+	}
+	return s.applyStatuses(actor, courseID, date, statuses)
+}
+
+// applyStatuses stores the changed statuses of a session in one transaction
+func (s *ParticipationService) applyStatuses(actor string, courseID uint, date time.Time, statuses map[uint]string) error {
+	course, err := getCourse(s.courseRepo, courseID)
+	if err != nil {
+		return err
+	}
+	current, err := s.sessionStatuses(courseID, date)
+	if err != nil {
+		return err
+	}
+	enrolledIDs, err := s.memberCourseRepo.GetMembersByCourseID(strconv.FormatUint(uint64(courseID), 10))
+	if err != nil {
+		return err
+	}
+	enrolled := make(map[uint]bool, len(enrolledIDs))
+	for _, memberID := range enrolledIDs {
+		enrolled[memberID] = true
+	}
+
+	now := time.Now()
+	changes := make([]model.AttendanceChange, 0, len(statuses))
+	previous := make(map[uint]string, len(statuses))
+	dropIn := make(map[uint]bool)
+	for memberID, status := range statuses {
+		previous[memberID] = model.ParticipationStatusAbsent
+		if currentStatus, ok := current[memberID]; ok {
+			previous[memberID] = currentStatus
+		}
+		if previous[memberID] == status {
+			continue
+		}
+		changeID, err := newToken()
+		if err != nil {
+			return err
+		}
+		changes = append(changes, model.AttendanceChange{
+			Actor:     actor,
+			ChangeID:  changeID,
+			MemberID:  memberID,
+			CourseID:  courseID,
+			Date:      date,
+			Status:    status,
+			ChangedAt: now,
+			Outcome:   model.AttendanceChangeApplied,
+		})
+		if course.DropIn && !enrolled[memberID] {
+			dropIn[memberID] = true
+		}
+	}
+	// A fixed order keeps concurrent bulk changes from locking punch cards in opposite order
+	slices.SortFunc(changes, func(a, b model.AttendanceChange) int {
+		return cmp.Compare(a.MemberID, b.MemberID)
+	})
+	return s.storeChanges(changes, previous, dropIn, s.participationRepo.ApplyChanges)
+}
+
+// storeChanges stores attendance changes together with their journal and audit entries through the
+// store and publishes them to the open participant lists and the audit listeners. Changes of locked sessions are
+// refused with ErrSessionLocked, changes of cancelled sessions with ErrSessionCancelled.
+func (s *ParticipationService) storeChanges(changes []model.AttendanceChange, previous map[uint]string, dropIn map[uint]bool, store AttendanceStore) error {
+	now := time.Now()
+	checked := make(map[string]bool)
+	entries := make([]model.AuditEntry, 0, len(changes))
+	for _, change := range changes {
+		switch change.Status {
+		case model.ParticipationStatusPresent, model.ParticipationStatusExcused, model.ParticipationStatusAbsent:
+		default:
+			return fmt.Errorf("invalid attendance status: %s", change.Status)
+		}
+		if session := sessionAuditID(change.CourseID, change.Date); !checked[session] {
+			if err := s.sessionLockService.CheckUnlocked(change.CourseID, change.Date, now); err != nil {
+				return err
+			}
+			if err := s.sessionService.CheckNotCancelled(change.CourseID, change.Date); err != nil {
+				return err
+			}
+			checked[session] = true
+		}
+		entry, err := newAuditEntry(change.Actor, model.AuditActionUpdate, model.AuditEntityParticipation,
+			participationAuditID(change.CourseID, change.Date, change.MemberID),
+			attendanceAuditValue(previous[change.MemberID]), attendanceAuditValue(change.Status))
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+	}
+
+	err := store(changes, dropIn, entries)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNoCredits
+	}
+	if err != nil {
+		return err
+	}
+	s.auditService.Publish(entries)
+	for _, change := range changes {
+		s.publishAttendance(change.CourseID, change.Date, change.MemberID, change.Status, dropIn[change.MemberID])
+	}
+	return nil
+}
+
+// activeEnrolledIDs retrieves the IDs of the members enrolled in a course and active on the given date
+func (s *ParticipationService) activeEnrolledIDs(courseID uint, date time.Time) ([]uint, error) {
+	memberIDs, err := s.memberCourseRepo.GetMembersByCourseID(strconv.FormatUint(uint64(courseID), 10))
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.memberRepo.GetByIDsAndDate(memberIDs, date)
+	if err != nil {
+		return nil, err
+	}
+	activeIDs := make([]uint, 0, len(members))
+	for _, member := range members {
+		activeIDs = append(activeIDs, member.ID)
+	}
+	return activeIDs, nil
+}
+
+// sessionStatuses maps the members with a participation record in a session to their status
+func (s *ParticipationService) sessionStatuses(courseID uint, date time.Time) (map[uint]string, error) {
+	participations, err := s.participationRepo.GetByCourseAndDate(strconv.FormatUint(uint64(courseID), 10), date.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	statuses := make(map[uint]string, len(participations))
+	for _, participation := range participations {
+		statuses[participation.MemberID] = participation.Status
+	}
+	return statuses, nil
+}
+
+// SubscribeAttendance returns a channel receiving the attendance changes of a session as JSON
+// encoded AttendanceEvents and a function ending the subscription
+func (s *ParticipationService) SubscribeAttendance(courseID uint, date time.Time) (<-chan []byte, func()) {
+	return s.broker.Subscribe(attendanceTopic(courseID, date))
+}
+
+// publishAttendance notifies the subscribers of a session about an attendance change. The
+// change is already stored, so failures are only logged.
+func (s *ParticipationService) publishAttendance(courseID uint, date time.Time, memberID uint, status string, dropIn bool) {
+	event := AttendanceEvent{
+		CourseID: courseID,
+		Date:     date.Format("2006-01-02"),
+		MemberID: memberID,
+		Present:  status == model.ParticipationStatusPresent,
+		Status:   status,
+	}
+	if dropIn {
+		balances, err := s.punchCardRepo.GetBalances([]uint{memberID}, date)
+		if err != nil {
+			log.Printf("Failed to retrieve punch card balance of member %d: %v", memberID, err)
+			return
+		}
+		credits := balances[memberID]
+		event.Credits = &credits
+	}
+	message, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Failed to encode attendance change: %v", err)
+		return
+	}
+	if err := s.broker.Publish(attendanceTopic(courseID, date), message); err != nil {
+		log.Printf("Failed to publish attendance change of course %d: %v", courseID, err)
+	}
+}
+
+// attendanceTopic returns the pub/sub topic of the attendance changes of a session
+func attendanceTopic(courseID uint, date time.Time) string {
+	return fmt.Sprintf("attendance:%d:%s", courseID, date.Format("2006-01-02"))
+}
+
+// Export formats and layouts supported by Export
+const (
+	ExportFormatCSV      = "csv"
+	ExportFormatXLSX     = "xlsx"
+	ExportLayoutRows     = "rows"
+	ExportLayoutMatrix   = "matrix"
+	ExportLayoutSessions = "sessions"
+)
+
+// Export exports participation data within a date range in the given format and layout.
+// The rows layout lists one attendance per row, the matrix layout one attendance sheet per course
+// with the status of every member and the sessions layout the times, notes and training content of
+// each session. Excel files of the rows layout include the sessions as a second worksheet.
+func (s *ParticipationService) Export(minDate, maxDate, format, layout string) ([]byte, error) {
+	switch {
+	case layout == ExportLayoutRows && format == ExportFormatCSV:
+		csvData, err := s.ExportData(minDate, maxDate)
+		return []byte(csvData), err
+	case layout == ExportLayoutRows && format == ExportFormatXLSX:
+		rows, err := s.exportRows(minDate, maxDate)
+		if err != nil {
+			return nil, err
+		}
+		sessionRows, err := s.sessionService.exportRows(minDate, maxDate)
+		if err != nil {
+			return nil, err
+		}
+		return writeTablesXLSX([]string{"Teilnahmen", "Termine"}, [][][]string{rows, sessionRows})
+	case layout == ExportLayoutMatrix:
+		sheets, err := s.BuildAttendanceSheets(minDate, maxDate, 0, false)
+		if err != nil {
+			return nil, err
 		}
-		return s.participationRepo.Upsert(participation)
-	} else {
-		return s.participationRepo.Delete(memberID, courseID, date)
+		if format == ExportFormatXLSX {
+			return writeSheetsXLSX(sheets)
+		}
+		return writeSheetsCSV(sheets)
+	case layout == ExportLayoutSessions:
+		rows, err := s.sessionService.exportRows(minDate, maxDate)
+		if err != nil {
+			return nil, err
+		}
+		if format == ExportFormatXLSX {
+			return writeTablesXLSX([]string{"Termine"}, [][][]string{rows})
+		}
+		var builder strings.Builder
+		writer := csv.NewWriter(&builder)
+		if err := writer.WriteAll(rows); err != nil {
+			return nil, err
+		}
+		return []byte(builder.String()), nil
+	default:
+		return nil, fmt.Errorf("unsupported export format %s with layout %s", format, layout)
 	}
 }
 
 // ExportData exports participation data within a date range as CSV
 func (s *ParticipationService) ExportData(minDate, maxDate string) (string, error) {
-	participations, err := s.participationRepo.GetExportData(minDate, maxDate)
+	rows, err := s.exportRows(minDate, maxDate)
 	if err != nil {
 		return "", err
 	}
 
 	var builder strings.Builder
 	writer := csv.NewWriter(&builder)
-
-	// Write CSV header
-	header := []string{"Date", "CourseID", "MemberID"}
-	if err := writer.Write(header); err != nil {
+	if err := writer.WriteAll(rows); err != nil {
 		return "", err
 	}
+	return builder.String(), nil
+}
 
-	// Write data rows
+// exportRows builds the rows layout including its header. It keeps the format existing consumers
+// of the export rely on: one row per member present, without a status column.
+func (s *ParticipationService) exportRows(minDate, maxDate string) ([][]string, error) {
+	participations, err := s.participationRepo.GetExportData(minDate, maxDate)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := [][]string{{"Date", "CourseID", "MemberID"}}
 	for _, p := range participations {
-		row := []string{
+		if p.Status != model.ParticipationStatusPresent {
+			continue
+		}
+		rows = append(rows, []string{
 			p.Date.Format("2006-01-02"),
 			fmt.Sprintf("%d", p.CourseID),
 			fmt.Sprintf("%d", p.MemberID),
-		}
-		if err := writer.Write(row); err != nil {
-			return "", err
-		}
+		})
 	}
-
-	writer.Flush()
-	return builder.String(), nil
+	return rows, nil
 }
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Page layout of printed attendance sheets (A4 landscape, in mm)
+const (
+	sheetMargin       = 10.0
+	sheetNumberWidth  = 8.0
+	sheetNameWidth    = 55.0
+	sheetAgeWidth     = 12.0
+	sheetRowHeight    = 7.0
+	sheetMaxDateWidth = 16.0
+	sheetMaxDates     = 20
+
+	sheetLegendHeight     = 5.0
+	sheetSignatureWidth   = 80.0
+	sheetSignatureSpacing = 14.0
+	sheetSignaturesPerRow = 3
+)
+
+// ClubInfo holds the club details printed in document headers
+type ClubInfo struct {
+	Name    string
+	Address string
+}
+
+// PrintService renders printable PDF documents
+type PrintService struct {
+	participationService *ParticipationService
+	club                 ClubInfo
+}
+
+// NewPrintService creates a new PrintService
+func NewPrintService(participationService *ParticipationService, club ClubInfo) *PrintService {
+	return &PrintService{
+		participationService: participationService,
+		club:                 club,
+	}
+}
+
+// AttendanceSheetPDF renders the paper attendance list of a course for the given date range.
+// Every scheduled date gets a column; recorded attendance is filled in, the rest is left blank
+// for the trainers. Dates beyond sheetMaxDates continue on additional pages.
+func (s *PrintService) AttendanceSheetPDF(courseID uint, minDate, maxDate string) ([]byte, error) {
+	sheets, err := s.participationService.BuildAttendanceSheets(minDate, maxDate, courseID, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(sheets) == 0 {
+		return nil, ErrCourseNotFound
+	}
+	sheet := sheets[0]
+	trainers := splitTrainerNames(sheet.Course.TrainerNames)
+
+	pdf := fpdf.New("L", "mm", "A4", "")
+	pdf.SetMargins(sheetMargin, sheetMargin, sheetMargin)
+	pdf.SetAutoPageBreak(false, sheetMargin)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pageWidth, pageHeight := pdf.GetPageSize()
+
+	chunks := chunkDates(sheet.Dates, sheetMaxDates)
+	for chunkIndex, dates := range chunks {
+		offset := chunkIndex * sheetMaxDates
+		dateWidth := sheetMaxDateWidth
+		if len(dates) > 0 {
+			available := pageWidth - 2*sheetMargin - sheetNumberWidth - sheetNameWidth - sheetAgeWidth
+			dateWidth = min(sheetMaxDateWidth, available/float64(len(dates)))
+		}
+
+		newPage := func() {
+			pdf.AddPage()
+			s.writeSheetHeader(pdf, tr, sheet, minDate, maxDate)
+			pdf.SetFont("Helvetica", "B", 8)
+			pdf.SetFillColor(230, 230, 230)
+			pdf.CellFormat(sheetNumberWidth, sheetRowHeight, "Nr.", "1", 0, "C", true, 0, "")
+			pdf.CellFormat(sheetNameWidth, sheetRowHeight, "Name", "1", 0, "L", true, 0, "")
+			pdf.CellFormat(sheetAgeWidth, sheetRowHeight, "Alter", "1", 0, "C", true, 0, "")
+			for _, date := range dates {
+				pdf.CellFormat(dateWidth, sheetRowHeight, shortDate(date), "1", 0, "C", true, 0, "")
+			}
+			pdf.Ln(-1)
+			pdf.SetFont("Helvetica", "", 9)
+		}
+		newPage()
+
+		// Keep room for the totals, trainer rows and signature lines at the bottom of the last page.
+		// A page always gets at least one member row, so very long trainer lists cannot push every
+		// row onto a page of its own; the signature lines then continue on another page.
+		totalsHeight := 2*sheetRowHeight + sheetLegendHeight
+		footerHeight := totalsHeight + signatureLinesHeight(len(trainers))
+		rowsOnPage := 0
+		for i, row := range sheet.Rows {
+			if rowsOnPage > 0 && pdf.GetY()+sheetRowHeight > pageHeight-sheetMargin-footerHeight {
+				newPage()
+				rowsOnPage = 0
+			}
+			rowsOnPage++
+			age := ""
+			if row.Member.Age > 0 {
+				age = fmt.Sprintf("%d", row.Member.Age)
+			}
+			pdf.CellFormat(sheetNumberWidth, sheetRowHeight, fmt.Sprintf("%d", i+1), "1", 0, "C", false, 0, "")
+			pdf.CellFormat(sheetNameWidth, sheetRowHeight, tr(row.Member.FirstName+" "+row.Member.LastName), "1", 0, "L", false, 0, "")
+			pdf.CellFormat(sheetAgeWidth, sheetRowHeight, age, "1", 0, "C", false, 0, "")
+			for j := range dates {
+				pdf.CellFormat(dateWidth, sheetRowHeight, attendanceMarks[row.Statuses[offset+j]], "1", 0, "C", false, 0, "")
+			}
+			pdf.Ln(-1)
+		}
+
+		// Column totals and trainer initials per session
+		if pdf.GetY()+totalsHeight > pageHeight-sheetMargin {
+			newPage()
+		}
+		labelWidth := sheetNumberWidth + sheetNameWidth + sheetAgeWidth
+		pdf.SetFont("Helvetica", "B", 8)
+		pdf.CellFormat(labelWidth, sheetRowHeight, "Anwesend gesamt", "1", 0, "R", false, 0, "")
+		for j := range dates {
+			total := ""
+			if sheet.ColumnTotals[offset+j] > 0 {
+				total = fmt.Sprintf("%d", sheet.ColumnTotals[offset+j])
+			}
+			pdf.CellFormat(dateWidth, sheetRowHeight, total, "1", 0, "C", false, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.CellFormat(labelWidth, sheetRowHeight, tr("Kürzel Trainer/in"), "1", 0, "R", false, 0, "")
+		for range dates {
+			pdf.CellFormat(dateWidth, sheetRowHeight, "", "1", 0, "C", false, 0, "")
+		}
+		pdf.Ln(-1)
+
+		pdf.SetFont("Helvetica", "", 7)
+		pdf.CellFormat(0, sheetLegendHeight, tr(attendanceLegend), "", 1, "L", false, 0, "")
+		s.writeSignatureLines(pdf, tr, sheet, minDate, maxDate, trainers)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeSheetHeader prints the club and course details at the top of a page
+func (s *PrintService) writeSheetHeader(pdf *fpdf.Fpdf, tr func(string) string, sheet AttendanceSheet, minDate, maxDate string) {
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(0, 7, tr(s.club.Name), "", 1, "L", false, 0, "")
+	if s.club.Address != "" {
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.CellFormat(0, 5, tr(s.club.Address), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(2)
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(0, 6, tr(fmt.Sprintf("Anwesenheitsliste %s", sheet.Course.Name)), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	details := []string{
+		fmt.Sprintf("Kurs-Nr. %d", sheet.Course.ID),
+		fmt.Sprintf("Zeitraum %s - %s", longDate(minDate), longDate(maxDate)),
+	}
+	if sheet.Course.Location != "" {
+		details = append(details, "Ort: "+sheet.Course.Location)
+	}
+	if sheet.Course.Weekday != "" {
+		details = append(details, fmt.Sprintf("%s %s-%s Uhr", sheet.Course.Weekday, sheet.Course.StartTime, sheet.Course.EndTime))
+	}
+	if sheet.Course.TrainingType != "" {
+		details = append(details, "Sparte: "+sheet.Course.TrainingType)
+	}
+	pdf.CellFormat(0, 5, tr(strings.Join(details, "   |   ")), "", 1, "L", false, 0, "")
+	if sheet.Course.TrainerNames != "" {
+		pdf.CellFormat(0, 5, tr("Trainer/innen: "+sheet.Course.TrainerNames), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(3)
+}
+
+// writeSignatureLines prints a signature line per trainer below the table. Rows of signature lines
+// that do not fit on the page continue on a new page with the sheet header.
+func (s *PrintService) writeSignatureLines(pdf *fpdf.Fpdf, tr func(string) string, sheet AttendanceSheet, minDate, maxDate string, trainers []string) {
+	if len(trainers) == 0 {
+		trainers = []string{""}
+	}
+	_, pageHeight := pdf.GetPageSize()
+	pdf.Ln(8)
+	for i, trainer := range trainers {
+		if i > 0 && i%sheetSignaturesPerRow == 0 {
+			pdf.Ln(sheetSignatureSpacing)
+		}
+		if i%sheetSignaturesPerRow == 0 && pdf.GetY()+6 > pageHeight-sheetMargin {
+			pdf.AddPage()
+			s.writeSheetHeader(pdf, tr, sheet, minDate, maxDate)
+			pdf.Ln(8)
+		}
+		pdf.SetFont("Helvetica", "", 8)
+		x := sheetMargin + float64(i%sheetSignaturesPerRow)*(sheetSignatureWidth+10)
+		y := pdf.GetY()
+		pdf.Line(x, y, x+sheetSignatureWidth, y)
+		pdf.SetXY(x, y+1)
+		label := fitText(pdf, tr("Datum, Unterschrift "+trainer), sheetSignatureWidth)
+		pdf.CellFormat(sheetSignatureWidth, 4, label, "", 0, "L", false, 0, "")
+		pdf.SetXY(x, y)
+	}
+	pdf.Ln(6)
+}
+
+// signatureLinesHeight computes the height writeSignatureLines needs for the given number of trainers
+func signatureLinesHeight(trainers int) float64 {
+	rows := max(1, (trainers+sheetSignaturesPerRow-1)/sheetSignaturesPerRow)
+	return 8 + float64(rows-1)*sheetSignatureSpacing + 6
+}
+
+// fitText shortens a text with the current font to the given width, so long trainer names do not
+// run into the neighbouring signature line
+func fitText(pdf *fpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	for len(text) > 0 && pdf.GetStringWidth(text+"...") > width {
+		text = text[:len(text)-1]
+	}
+	return text + "..."
+}
+
+// chunkDates splits the dates into pages of at most size columns; an empty list yields one empty page
+func chunkDates(dates []string, size int) [][]string {
+	if len(dates) == 0 {
+		return [][]string{nil}
+	}
+	var chunks [][]string
+	for start := 0; start < len(dates); start += size {
+		end := min(start+size, len(dates))
+		chunks = append(chunks, dates[start:end])
+	}
+	return chunks
+}
+
+// shortDate formats a YYYY-MM-DD date as DD.MM.
+func shortDate(date string) string {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	return day.Format("02.01.")
+}
+
+// longDate formats a YYYY-MM-DD date as DD.MM.YYYY
+func longDate(date string) string {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	return day.Format("02.01.2006")
+}
+
+// splitTrainerNames splits the comma-separated trainer list of a course
+func splitTrainerNames(trainerNames string) []string {
+	var names []string
+	for _, name := range strings.Split(trainerNames, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"azh/internal/model"
+	"azh/internal/repository"
+)
+
+// Errors returned when handling punch cards
+var (
+	ErrNoCredits        = errors.New("no punch card credits left")
+	ErrInvalidPunchCard = errors.New("invalid punch card")
+)
+
+// PunchCardDefaults holds the default size and price of a punch card
+type PunchCardDefaults struct {
+	Credits int
+	Price   float64
+}
+
+// PunchCardSaleRequest sells a card to an existing member by ID or to a guest by name and contact
+type PunchCardSaleRequest struct {
+	MemberID  *uint    `json:"member_id"`
+	FirstName string   `json:"first_name"`
+	LastName  string   `json:"last_name"`
+	Email     string   `json:"email"`
+	Phone     string   `json:"phone"`
+	Credits   int      `json:"credits"`
+	Price     *float64 `json:"price"`
+	SoldAt    string   `json:"sold_at"`
+	ExpiresAt string   `json:"expires_at"`
+	Notes     string   `json:"notes"`
+}
+
+// PunchCardsDTO represents the punch cards and current balance of a member
+type PunchCardsDTO struct {
+	MemberID uint              `json:"member_id"`
+	Balance  int               `json:"balance"`
+	Cards    []model.PunchCard `json:"cards"`
+}
+
+// PunchCardReport summarizes punch card sales and usage within a date range
+type PunchCardReport struct {
+	MinDate            string                       `json:"min_date"`
+	MaxDate            string                       `json:"max_date"`
+	Sales              repository.PunchCardSales    `json:"sales"`
+	Visits             []repository.PunchCardVisits `json:"visits"`
+	CreditsUsed        int                          `json:"credits_used"`
+	OutstandingCredits int                          `json:"outstanding_credits"`
+}
+
+// PunchCardService handles punch cards for drop-in sessions
+type PunchCardService struct {
+	courseRepo    *repository.CourseRepository
+	memberRepo    *repository.MemberRepository
+	punchCardRepo *repository.PunchCardRepository
+	auditService  *AuditService
+	defaults      PunchCardDefaults
+}
+
+// NewPunchCardService creates a new PunchCardService
+func NewPunchCardService(
+	courseRepo *repository.CourseRepository,
+	memberRepo *repository.MemberRepository,
+	punchCardRepo *repository.PunchCardRepository,
+	auditService *AuditService,
+	defaults PunchCardDefaults,
+) *PunchCardService {
+	return &PunchCardService{
+		courseRepo:    courseRepo,
+		memberRepo:    memberRepo,
+		punchCardRepo: punchCardRepo,
+		auditService:  auditService,
+		defaults:      defaults,
+	}
+}
+
+// SetDropIn flags a course as drop-in session paid with punch cards
+func (s *PunchCardService) SetDropIn(actor string, courseID uint, dropIn bool) error {
+	course, err := getCourse(s.courseRepo, courseID)
+	if err != nil {
+		return err
+	}
+	if err := s.courseRepo.SetDropIn(courseID, dropIn); err != nil {
+		return err
+	}
+	return s.auditService.Record(actor, model.AuditActionUpdate, model.AuditEntityCourse, fmt.Sprint(courseID),
+		map[string]bool{"drop_in": course.DropIn}, map[string]bool{"drop_in": dropIn})
+}
+
+// SellCard records the sale of a punch card; buyers who are not members are registered as guests
+func (s *PunchCardService) SellCard(actor string, req PunchCardSaleRequest, referenceDate time.Time) (model.PunchCard, error) {
+	card := model.PunchCard{
+		Credits: s.defaults.Credits,
+		Price:   s.defaults.Price,
+		SoldAt:  time.Date(referenceDate.Year(), referenceDate.Month(), referenceDate.Day(), 0, 0, 0, 0, time.UTC),
+		Notes:   strings.TrimSpace(req.Notes),
+	}
+	if req.Credits != 0 {
+		card.Credits = req.Credits
+	}
+	if req.Price != nil {
+		card.Price = roundCents(*req.Price)
+	}
+	if card.Credits <= 0 || card.Price < 0 {
+		return card, fmt.Errorf("%w: credits must be positive and price must not be negative", ErrInvalidPunchCard)
+	}
+	card.Remaining = card.Credits
+	if req.SoldAt != "" {
+		soldAt, err := time.Parse("2006-01-02", req.SoldAt)
+		if err != nil {
+			return card, fmt.Errorf("%w: invalid sale date", ErrInvalidPunchCard)
+		}
+		card.SoldAt = soldAt
+	}
+	if req.ExpiresAt != "" {
+		expiresAt, err := time.Parse("2006-01-02", req.ExpiresAt)
+		if err != nil || expiresAt.Before(card.SoldAt) {
+			return card, fmt.Errorf("%w: invalid expiry date", ErrInvalidPunchCard)
+		}
+		card.ExpiresAt = &expiresAt
+	}
+
+	if req.MemberID != nil {
+		members, err := s.memberRepo.GetByIDs([]uint{*req.MemberID})
+		if err != nil {
+			return card, err
+		}
+		if len(members) == 0 {
+			return card, ErrMemberNotFound
+		}
+		card.MemberID = members[0].ID
+	} else {
+		if strings.TrimSpace(req.FirstName) == "" || strings.TrimSpace(req.LastName) == "" {
+			return card, fmt.Errorf("%w: member ID or buyer name required", ErrInvalidPunchCard)
+		}
+		guest := model.Member{
+			FirstName:        strings.TrimSpace(req.FirstName),
+			LastName:         strings.TrimSpace(req.LastName),
+			Email:            strings.TrimSpace(req.Email),
+			Phone:            strings.TrimSpace(req.Phone),
+			SignUpDate:       time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC),
+			CancellationDate: time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC),
+			Notes:            "Gast: 10er-Karte",
+		}
+		if err := s.memberRepo.CreateGuest(&guest); err != nil {
+			return card, fmt.Errorf("error creating guest: %v", err)
+		}
+		if err := s.auditService.Record(actor, model.AuditActionCreate, model.AuditEntityMember, fmt.Sprint(guest.ID), nil, guest); err != nil {
+			return card, err
+		}
+		card.MemberID = guest.ID
+	}
+
+	err := s.punchCardRepo.Create(&card)
+	return card, err
+}
+
+// GetCards retrieves the punch cards and current balance of a member
+func (s *PunchCardService) GetCards(memberID uint, referenceDate time.Time) (PunchCardsDTO, error) {
+	cards, err := s.punchCardRepo.GetByMemberID(memberID)
+	if err != nil {
+		return PunchCardsDTO{}, err
+	}
+	balances, err := s.punchCardRepo.GetBalances([]uint{memberID}, referenceDate)
+	if err != nil {
+		return PunchCardsDTO{}, err
+	}
+	return PunchCardsDTO{MemberID: memberID, Balance: balances[memberID], Cards: cards}, nil
+}
+
+// GetReport summarizes the cards sold and the visits paid with cards within a date range, and the
+// credits still outstanding at its end
+func (s *PunchCardService) GetReport(minDate, maxDate string) (PunchCardReport, error) {
+	to, err := time.Parse("2006-01-02", maxDate)
+	if err != nil {
+		return PunchCardReport{}, fmt.Errorf("invalid maxDate: %v", err)
+	}
+	report := PunchCardReport{MinDate: minDate, MaxDate: maxDate}
+	if report.Sales, err = s.punchCardRepo.GetSales(minDate, maxDate); err != nil {
+		return report, err
+	}
+	if report.Visits, err = s.punchCardRepo.GetVisits(minDate, maxDate); err != nil {
+		return report, err
+	}
+	for _, visits := range report.Visits {
+		report.CreditsUsed += visits.Visits
+	}
+	report.OutstandingCredits, err = s.punchCardRepo.GetOutstandingCredits(to)
+	return report, err
+}
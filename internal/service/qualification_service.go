@@ -0,0 +1,230 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"azh/internal/model"
+	"azh/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidQualification is returned for qualifications with unknown type or inconsistent dates
+var ErrInvalidQualification = errors.New("invalid qualification")
+
+// qualificationLabels holds the German names of the qualification types
+var qualificationLabels = map[string]string{
+	model.QualificationLicense:        "Übungsleiter-Lizenz",
+	model.QualificationFirstAid:       "Erste-Hilfe-Kurs",
+	model.QualificationCriminalRecord: "erweitertes Führungszeugnis",
+}
+
+// QualificationExpiryDTO represents a trainer's qualification that expired or expires soon
+type QualificationExpiryDTO struct {
+	TrainerID     uint   `json:"trainer_id"`
+	TrainerName   string `json:"trainer_name"`
+	TrainerEmail  string `json:"trainer_email"`
+	Type          string `json:"type"`
+	Label         string `json:"label"`
+	ExpiresAt     string `json:"expires_at"`
+	DaysRemaining int    `json:"days_remaining"`
+	Expired       bool   `json:"expired"`
+}
+
+// QualificationService handles business logic for trainer qualifications
+type QualificationService struct {
+	qualificationRepo *repository.QualificationRepository
+	trainerRepo       *repository.TrainerRepository
+}
+
+// NewQualificationService creates a new QualificationService
+func NewQualificationService(
+	qualificationRepo *repository.QualificationRepository,
+	trainerRepo *repository.TrainerRepository,
+) *QualificationService {
+	return &QualificationService{
+		qualificationRepo: qualificationRepo,
+		trainerRepo:       trainerRepo,
+	}
+}
+
+// GetQualifications retrieves the qualifications of a trainer
+func (s *QualificationService) GetQualifications(trainerID uint) ([]model.Qualification, error) {
+	return s.qualificationRepo.GetByTrainerIDs([]uint{trainerID})
+}
+
+// AddQualification records a new qualification of a trainer
+func (s *QualificationService) AddQualification(qualification model.Qualification) (model.Qualification, error) {
+	if _, err := s.trainerRepo.GetByID(qualification.TrainerID); errors.Is(err, gorm.ErrRecordNotFound) {
+		return qualification, ErrTrainerNotFound
+	} else if err != nil {
+		return qualification, err
+	}
+	if _, ok := qualificationLabels[qualification.Type]; !ok {
+		return qualification, fmt.Errorf("%w: unknown type %s", ErrInvalidQualification, qualification.Type)
+	}
+	if !qualification.ExpiresAt.IsZero() && qualification.ExpiresAt.Before(qualification.IssuedAt) {
+		return qualification, fmt.Errorf("%w: expires before it was issued", ErrInvalidQualification)
+	}
+	err := s.qualificationRepo.Create(&qualification)
+	return qualification, err
+}
+
+// RemoveQualification removes a qualification of a trainer
+func (s *QualificationService) RemoveQualification(trainerID, qualificationID uint) error {
+	return s.qualificationRepo.Delete(trainerID, qualificationID)
+}
+
+// GetRequirements retrieves the required qualification types per training type
+func (s *QualificationService) GetRequirements() (map[string][]string, error) {
+	requirements, err := s.qualificationRepo.GetRequirements()
+	if err != nil {
+		return nil, err
+	}
+	byType := make(map[string][]string)
+	for _, requirement := range requirements {
+		byType[requirement.TrainingType] = append(byType[requirement.TrainingType], requirement.QualificationType)
+	}
+	return byType, nil
+}
+
+// SetRequirements replaces the qualification types required for a training type; an empty list
+// removes all requirements
+func (s *QualificationService) SetRequirements(trainingType string, qualificationTypes []string) error {
+	for _, qualificationType := range qualificationTypes {
+		if _, ok := qualificationLabels[qualificationType]; !ok {
+			return fmt.Errorf("%w: unknown type %s", ErrInvalidQualification, qualificationType)
+		}
+	}
+	return s.qualificationRepo.ReplaceRequirements(trainingType, qualificationTypes)
+}
+
+// CheckTrainers warns about trainers lacking a valid qualification required for the training type
+// on the given date
+func (s *QualificationService) CheckTrainers(trainers []model.Trainer, trainingType string, date time.Time) ([]string, error) {
+	if len(trainers) == 0 {
+		return nil, nil
+	}
+	requirements, err := s.GetRequirements()
+	if err != nil {
+		return nil, err
+	}
+	// Training types without requirements need no qualifications
+	required := requirements[trainingType]
+	if len(required) == 0 {
+		return nil, nil
+	}
+
+	trainerIDs := make([]uint, 0, len(trainers))
+	for _, trainer := range trainers {
+		trainerIDs = append(trainerIDs, trainer.ID)
+	}
+	qualifications, err := s.qualificationRepo.GetByTrainerIDs(trainerIDs)
+	if err != nil {
+		return nil, err
+	}
+	latest := latestQualifications(qualifications, date)
+
+	var warnings []string
+	for _, trainer := range trainers {
+		for _, qualificationType := range required {
+			qualification, ok := latest[qualificationKey(trainer.ID, qualificationType)]
+			switch {
+			case !ok:
+				warnings = append(warnings, fmt.Sprintf("%s: %s fehlt", trainer.Name, qualificationLabels[qualificationType]))
+			case !qualification.ExpiresAt.IsZero() && qualification.ExpiresAt.Before(date):
+				warnings = append(warnings, fmt.Sprintf("%s: %s ist am %s abgelaufen", trainer.Name,
+					qualificationLabels[qualificationType], qualification.ExpiresAt.Format("02.01.2006")))
+			}
+		}
+	}
+	return warnings, nil
+}
+
+// GetExpiries lists the latest qualification per trainer and type that expired already or expires
+// within the given number of days
+func (s *QualificationService) GetExpiries(days int, referenceDate time.Time) ([]QualificationExpiryDTO, error) {
+	qualifications, err := s.qualificationRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	today := time.Date(referenceDate.Year(), referenceDate.Month(), referenceDate.Day(), 0, 0, 0, 0, time.UTC)
+	latest := latestQualifications(qualifications, today)
+	limit := today.AddDate(0, 0, days)
+
+	var trainerIDs []uint
+	var expiring []model.Qualification
+	for _, qualification := range latest {
+		if qualification.ExpiresAt.IsZero() || qualification.ExpiresAt.After(limit) {
+			continue
+		}
+		expiring = append(expiring, qualification)
+		trainerIDs = append(trainerIDs, qualification.TrainerID)
+	}
+	expiries := make([]QualificationExpiryDTO, 0, len(expiring))
+	if len(expiring) == 0 {
+		return expiries, nil
+	}
+	trainers, err := s.trainerRepo.GetByIDs(trainerIDs)
+	if err != nil {
+		return nil, err
+	}
+	trainersByID := make(map[uint]model.Trainer)
+	for _, trainer := range trainers {
+		trainersByID[trainer.ID] = trainer
+	}
+
+	for _, qualification := range expiring {
+		trainer := trainersByID[qualification.TrainerID]
+		daysRemaining := int(qualification.ExpiresAt.Sub(today).Hours() / 24)
+		expiries = append(expiries, QualificationExpiryDTO{
+			TrainerID:     trainer.ID,
+			TrainerName:   trainer.Name,
+			TrainerEmail:  trainer.Email,
+			Type:          qualification.Type,
+			Label:         qualificationLabels[qualification.Type],
+			ExpiresAt:     qualification.ExpiresAt.Format("2006-01-02"),
+			DaysRemaining: daysRemaining,
+			Expired:       daysRemaining < 0,
+		})
+	}
+	sort.Slice(expiries, func(i, j int) bool {
+		if expiries[i].ExpiresAt != expiries[j].ExpiresAt {
+			return expiries[i].ExpiresAt < expiries[j].ExpiresAt
+		}
+		return strings.Compare(expiries[i].TrainerName, expiries[j].TrainerName) < 0
+	})
+	return expiries, nil
+}
+
+// latestQualifications picks per trainer and type the qualification issued until the given date
+// that is valid the longest
+func latestQualifications(qualifications []model.Qualification, date time.Time) map[string]model.Qualification {
+	latest := make(map[string]model.Qualification)
+	for _, qualification := range qualifications {
+		if qualification.IssuedAt.After(date) {
+			continue
+		}
+		key := qualificationKey(qualification.TrainerID, qualification.Type)
+		if current, ok := latest[key]; !ok || validLonger(qualification, current) {
+			latest[key] = qualification
+		}
+	}
+	return latest
+}
+
+// validLonger reports whether qualification a is valid longer than qualification b
+func validLonger(a, b model.Qualification) bool {
+	if b.ExpiresAt.IsZero() {
+		return false
+	}
+	return a.ExpiresAt.IsZero() || a.ExpiresAt.After(b.ExpiresAt)
+}
+
+// qualificationKey identifies a qualification type of a trainer
+func qualificationKey(trainerID uint, qualificationType string) string {
+	return fmt.Sprintf("%d-%s", trainerID, qualificationType)
+}
@@ -0,0 +1,404 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	netmail "net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"azh/internal/mail"
+	"azh/internal/model"
+	"azh/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Errors returned when handling online registrations
+var (
+	ErrInvalidRegistration  = errors.New("invalid registration")
+	ErrRegistrationNotFound = errors.New("registration not found")
+	ErrRegistrationExpired  = errors.New("registration confirmation expired")
+	ErrRegistrationState    = errors.New("registration is not in the required state")
+	ErrCourseUnavailable    = errors.New("course not open for registration")
+)
+
+// registrationConfirmationPeriod is how long a confirmation link stays valid
+const registrationConfirmationPeriod = 7 * 24 * time.Hour
+
+// OpenCourseDTO represents a course open for online registration
+type OpenCourseDTO struct {
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	Location     string `json:"location"`
+	TrainingType string `json:"training_type"`
+	Weekday      string `json:"weekday"`
+	StartTime    string `json:"start_time"`
+	EndTime      string `json:"end_time"`
+	FreeSpots    *int   `json:"free_spots"` // nil for courses without capacity limit
+}
+
+// RegistrationRequest holds the data entered in the public registration form
+type RegistrationRequest struct {
+	CourseID       uint   `json:"course_id"`
+	FirstName      string `json:"first_name"`
+	LastName       string `json:"last_name"`
+	BirthDate      string `json:"birth_date"`
+	GuardianName   string `json:"guardian_name"`
+	Email          string `json:"email"`
+	Phone          string `json:"phone"`
+	Message        string `json:"message"`
+	ConsentPrivacy bool   `json:"consent_privacy"`
+	ConsentPhotos  bool   `json:"consent_photos"`
+}
+
+// RegistrationService handles the public registration form, its double-opt-in confirmation and
+// the approval by the office
+type RegistrationService struct {
+	courseRepo       *repository.CourseRepository
+	memberRepo       *repository.MemberRepository
+	memberCourseRepo *repository.MemberCourseRepository
+	registrationRepo *repository.RegistrationRepository
+	waitlistService  *WaitlistService
+	guardianService  *GuardianService
+	auditService     *AuditService
+	mailer           *mail.Mailer
+	officeEmail      string
+	publicURL        string
+}
+
+// NewRegistrationService creates a new RegistrationService
+func NewRegistrationService(
+	courseRepo *repository.CourseRepository,
+	memberRepo *repository.MemberRepository,
+	memberCourseRepo *repository.MemberCourseRepository,
+	registrationRepo *repository.RegistrationRepository,
+	waitlistService *WaitlistService,
+	guardianService *GuardianService,
+	auditService *AuditService,
+	mailer *mail.Mailer,
+	officeEmail string,
+	publicURL string,
+) *RegistrationService {
+	return &RegistrationService{
+		courseRepo:       courseRepo,
+		memberRepo:       memberRepo,
+		memberCourseRepo: memberCourseRepo,
+		registrationRepo: registrationRepo,
+		waitlistService:  waitlistService,
+		guardianService:  guardianService,
+		auditService:     auditService,
+		mailer:           mailer,
+		officeEmail:      officeEmail,
+		publicURL:        strings.TrimSuffix(publicURL, "/"),
+	}
+}
+
+// GetOpenCourses retrieves the active weekly courses that still have free spots
+func (s *RegistrationService) GetOpenCourses(referenceDate time.Time) ([]OpenCourseDTO, error) {
+	courses, err := s.courseRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.memberCourseRepo.CountActive(referenceDate)
+	if err != nil {
+		return nil, err
+	}
+	open := make([]OpenCourseDTO, 0, len(courses))
+	for _, course := range courses {
+		if course.IsEvent() {
+			continue
+		}
+		dto := OpenCourseDTO{
+			ID:           course.ID,
+			Name:         course.Name,
+			Location:     course.Location,
+			TrainingType: course.TrainingType,
+			Weekday:      course.Weekday,
+			StartTime:    course.StartTime,
+			EndTime:      course.EndTime,
+		}
+		if course.MaxParticipants > 0 {
+			free := course.MaxParticipants - counts[course.ID]
+			if free <= 0 {
+				continue
+			}
+			dto.FreeSpots = &free
+		}
+		open = append(open, dto)
+	}
+	return open, nil
+}
+
+// Register stores a registration and sends the double-opt-in email with the confirmation link
+func (s *RegistrationService) Register(req RegistrationRequest, referenceDate time.Time) (model.Registration, error) {
+	registration, err := s.validate(req, referenceDate)
+	if err != nil {
+		return registration, err
+	}
+	if registration.ConfirmationToken, err = newToken(); err != nil {
+		return registration, err
+	}
+	registration.Status = model.RegistrationStatusUnconfirmed
+	if err := s.registrationRepo.Create(&registration); err != nil {
+		return registration, err
+	}
+
+	link := fmt.Sprintf("%s/register?token=%s", s.publicURL, url.QueryEscape(registration.ConfirmationToken))
+	body := fmt.Sprintf("Hallo %s,\n\nvielen Dank für die Anmeldung von %s %s. Bitte bestätige die Anmeldung innerhalb von 7 Tagen über folgenden Link:\n\n%s\n\n"+
+		"Danach prüfen wir die Anmeldung und melden uns mit einer Zusage oder einem Platz auf der Warteliste.\n\n"+
+		"Falls du dich nicht angemeldet hast, kannst du diese E-Mail ignorieren.\n",
+		registration.GuardianName, registration.FirstName, registration.LastName, link)
+	if err := s.mailer.Send([]string{registration.Email}, "Bitte bestätige deine Anmeldung", body); err != nil {
+		// Without the email the registration can never be confirmed
+		if deleteErr := s.registrationRepo.Delete(registration.ID); deleteErr != nil {
+			log.Printf("Failed to remove unconfirmable registration %d: %v", registration.ID, deleteErr)
+		}
+		return registration, fmt.Errorf("error sending confirmation email: %v", err)
+	}
+	return registration, nil
+}
+
+// Confirm confirms a registration via the token from the double-opt-in email; the registration
+// then waits for approval by the office
+func (s *RegistrationService) Confirm(token string, referenceDate time.Time) (model.Registration, error) {
+	registration, err := s.registrationRepo.GetByToken(token)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return registration, ErrRegistrationNotFound
+	}
+	if err != nil {
+		return registration, err
+	}
+	if registration.Status != model.RegistrationStatusUnconfirmed {
+		// Clicking the link twice is fine
+		return registration, nil
+	}
+	if referenceDate.Sub(registration.CreatedAt) > registrationConfirmationPeriod {
+		return registration, ErrRegistrationExpired
+	}
+	registration.Status = model.RegistrationStatusPending
+	registration.ConfirmedAt = &referenceDate
+	if err := s.registrationRepo.Save(&registration); err != nil {
+		return registration, err
+	}
+
+	if s.officeEmail != "" {
+		body := fmt.Sprintf("Neue bestätigte Anmeldung von %s %s für Kurs %d. Bitte in der Anwesenheitsliste prüfen und freigeben.\n",
+			registration.FirstName, registration.LastName, registration.CourseID)
+		if err := s.mailer.Send([]string{s.officeEmail}, "Neue Anmeldung", body); err != nil {
+			log.Printf("Failed to notify office about registration %d: %v", registration.ID, err)
+		}
+	}
+	return registration, nil
+}
+
+// GetRegistrations retrieves registrations by status; an empty status returns all
+func (s *RegistrationService) GetRegistrations(status string) ([]model.Registration, error) {
+	return s.registrationRepo.GetByStatus(status)
+}
+
+// Approve creates the member and enrollment of a confirmed registration, the same records the
+// Trainingsanmeldungen import creates. If the course is full, the member is put on its waitlist.
+// The member is linked to the guardian who registered them, who gets the link to report absences.
+// If a step fails, approving again continues with the member created before.
+func (s *RegistrationService) Approve(actor string, id uint, referenceDate time.Time) (model.Registration, error) {
+	registration, err := s.getPending(id)
+	if err != nil {
+		return registration, err
+	}
+
+	member, err := s.registeredMember(actor, &registration, referenceDate)
+	if err != nil {
+		return registration, err
+	}
+	enrollment, err := s.waitlistService.Enroll(actor, registration.CourseID, member.ID, referenceDate)
+	switch {
+	case errors.Is(err, ErrAlreadyEnrolled):
+		// Enrolled by an approval that failed afterwards
+		enrollment = EnrollmentDTO{CourseID: registration.CourseID, MemberID: member.ID, Enrolled: true}
+	case errors.Is(err, ErrAlreadyWaiting):
+		if enrollment, err = s.waitlistPosition(registration.CourseID, member.ID, referenceDate); err != nil {
+			return registration, err
+		}
+	case err != nil:
+		return registration, fmt.Errorf("error enrolling member %d: %v", member.ID, err)
+	}
+
+	registration.Status = model.RegistrationStatusApproved
+	registration.DecidedAt = &referenceDate
+	registration.Waitlisted = !enrollment.Enrolled
+	if err := s.registrationRepo.Save(&registration); err != nil {
+		return registration, err
+	}
+
+	// The member exists already, so a missing link must not fail the approval
+	link, err := s.guardianService.LinkChild(actor, registration.GuardianName, registration.Email, registration.Phone, member.ID)
+	if err != nil {
+		log.Printf("Failed to link member %d to guardian of registration %d: %v", member.ID, registration.ID, err)
+	}
+
+	subject := "Anmeldung bestätigt"
+	body := fmt.Sprintf("Hallo %s,\n\n%s %s ist jetzt für den Kurs angemeldet. Die Mitgliedsnummer lautet %d.\n\nWir freuen uns auf das erste Training!\n",
+		registration.GuardianName, registration.FirstName, registration.LastName, member.ID)
+	if link.URL != "" {
+		body += fmt.Sprintf("\nFalls %s einmal nicht zum Training kommen kann, melde die Abwesenheit bitte vorher über diesen persönlichen Link:\n\n%s\n",
+			registration.FirstName, link.URL)
+	}
+	if registration.Waitlisted {
+		subject = "Platz auf der Warteliste"
+		body = fmt.Sprintf("Hallo %s,\n\nder Kurs ist leider gerade voll. %s %s steht auf Platz %d der Warteliste; wir melden uns, sobald ein Platz frei wird.\n",
+			registration.GuardianName, registration.FirstName, registration.LastName, enrollment.WaitlistPosition)
+	}
+	if err := s.mailer.Send([]string{registration.Email}, subject, body); err != nil {
+		log.Printf("Failed to notify registrant of registration %d: %v", registration.ID, err)
+	}
+	return registration, nil
+}
+
+// registeredMember creates the member of a registration. The member is recorded on the
+// registration right away, so approving again after a failure reuses it.
+func (s *RegistrationService) registeredMember(actor string, registration *model.Registration, referenceDate time.Time) (model.Member, error) {
+	if registration.MemberID != nil {
+		members, err := s.memberRepo.GetByIDs([]uint{*registration.MemberID})
+		if err != nil {
+			return model.Member{}, err
+		}
+		if len(members) == 0 {
+			return model.Member{}, fmt.Errorf("%w: member %d of registration %d", ErrMemberNotFound, *registration.MemberID, registration.ID)
+		}
+		return members[0], nil
+	}
+
+	notes := []string{"Online-Anmeldung", "Erziehungsberechtigte/r: " + registration.GuardianName}
+	if registration.ConsentPhotos {
+		notes = append(notes, "Fotoeinwilligung erteilt")
+	} else {
+		notes = append(notes, "keine Fotoeinwilligung")
+	}
+	if registration.Message != "" {
+		notes = append(notes, registration.Message)
+	}
+	member := model.Member{
+		FirstName:        registration.FirstName,
+		LastName:         registration.LastName,
+		Email:            registration.Email,
+		Phone:            registration.Phone,
+		SignUpDate:       time.Date(referenceDate.Year(), referenceDate.Month(), referenceDate.Day(), 0, 0, 0, 0, time.UTC),
+		CancellationDate: time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC),
+		Age:              ageOn(registration.BirthDate, referenceDate),
+		Notes:            strings.Join(notes, "; "),
+	}
+	err := s.registrationRepo.CreateMember(registration, &member)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Another approval of the same registration created the member meanwhile
+		return member, ErrRegistrationState
+	}
+	if err != nil {
+		return member, fmt.Errorf("error creating member: %v", err)
+	}
+	return member, s.auditService.Record(actor, model.AuditActionCreate, model.AuditEntityMember, fmt.Sprint(member.ID), nil, member)
+}
+
+// waitlistPosition returns the enrollment of a member already waiting for a course
+func (s *RegistrationService) waitlistPosition(courseID, memberID uint, referenceDate time.Time) (EnrollmentDTO, error) {
+	waitlist, err := s.waitlistService.GetWaitlist(courseID, referenceDate)
+	if err != nil {
+		return EnrollmentDTO{}, err
+	}
+	enrollment := EnrollmentDTO{CourseID: courseID, MemberID: memberID}
+	for _, entry := range waitlist.Waiting {
+		if entry.MemberID == memberID {
+			enrollment.WaitlistPosition = entry.Position
+		}
+	}
+	return enrollment, nil
+}
+
+// Reject declines a confirmed registration
+func (s *RegistrationService) Reject(id uint, reason string, referenceDate time.Time) (model.Registration, error) {
+	registration, err := s.getPending(id)
+	if err != nil {
+		return registration, err
+	}
+	registration.Status = model.RegistrationStatusRejected
+	registration.DecidedAt = &referenceDate
+	registration.Notes = reason
+	err = s.registrationRepo.Save(&registration)
+	return registration, err
+}
+
+// getPending retrieves a registration that waits for approval
+func (s *RegistrationService) getPending(id uint) (model.Registration, error) {
+	registration, err := s.registrationRepo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return registration, ErrRegistrationNotFound
+	}
+	if err != nil {
+		return registration, err
+	}
+	if registration.Status != model.RegistrationStatusPending {
+		return registration, ErrRegistrationState
+	}
+	return registration, nil
+}
+
+// validate checks the form data and converts it into a registration
+func (s *RegistrationService) validate(req RegistrationRequest, referenceDate time.Time) (model.Registration, error) {
+	registration := model.Registration{
+		CourseID:       req.CourseID,
+		FirstName:      strings.TrimSpace(req.FirstName),
+		LastName:       strings.TrimSpace(req.LastName),
+		GuardianName:   strings.TrimSpace(req.GuardianName),
+		Email:          strings.TrimSpace(req.Email),
+		Phone:          strings.TrimSpace(req.Phone),
+		Message:        strings.TrimSpace(req.Message),
+		ConsentPrivacy: req.ConsentPrivacy,
+		ConsentPhotos:  req.ConsentPhotos,
+	}
+	if registration.FirstName == "" || registration.LastName == "" || registration.GuardianName == "" {
+		return registration, fmt.Errorf("%w: name missing", ErrInvalidRegistration)
+	}
+	if _, err := netmail.ParseAddress(registration.Email); err != nil {
+		return registration, fmt.Errorf("%w: invalid email address", ErrInvalidRegistration)
+	}
+	birthDate, err := time.Parse("2006-01-02", req.BirthDate)
+	if err != nil || birthDate.After(referenceDate) {
+		return registration, fmt.Errorf("%w: invalid birth date", ErrInvalidRegistration)
+	}
+	registration.BirthDate = birthDate
+	if !registration.ConsentPrivacy {
+		return registration, fmt.Errorf("%w: privacy consent required", ErrInvalidRegistration)
+	}
+
+	openCourses, err := s.GetOpenCourses(referenceDate)
+	if err != nil {
+		return registration, err
+	}
+	for _, course := range openCourses {
+		if course.ID == registration.CourseID {
+			return registration, nil
+		}
+	}
+	return registration, ErrCourseUnavailable
+}
+
+// ageOn computes the age in full years on the given date
+func ageOn(birthDate, date time.Time) int {
+	age := date.Year() - birthDate.Year()
+	if date.Month() < birthDate.Month() || (date.Month() == birthDate.Month() && date.Day() < birthDate.Day()) {
+		age--
+	}
+	return age
+}
+
+// newToken generates a random token for links sent by email
+func newToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"azh/internal/model"
+	"azh/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Errors returned when locking sessions and handling unlock requests
+var (
+	ErrSessionLocked           = errors.New("session locked")
+	ErrSessionNotLocked        = errors.New("session not locked")
+	ErrInvalidUnlockRequest    = errors.New("invalid unlock request")
+	ErrUnlockRequestNotFound   = errors.New("unlock request not found")
+	ErrUnlockRequestState      = errors.New("unlock request is not pending")
+	ErrUnlockRequestDuplicated = errors.New("unlock request already pending")
+)
+
+// LockSettings configures when sessions are locked automatically and how long an approved unlock lasts
+type LockSettings struct {
+	AutoLockDays int           // sessions lock this many days after they took place; 0 disables automatic locking
+	UnlockPeriod time.Duration // time to make a correction after an unlock request was approved
+}
+
+// SessionLockDTO represents the lock state of a session
+type SessionLockDTO struct {
+	CourseID       uint                 `json:"course_id"`
+	Date           string               `json:"date"`
+	Locked         bool                 `json:"locked"`
+	AutoLocked     bool                 `json:"auto_locked"`
+	LockedBy       string               `json:"locked_by,omitempty"`
+	LockedAt       *time.Time           `json:"locked_at,omitempty"`
+	UnlockedUntil  *time.Time           `json:"unlocked_until,omitempty"` // end of an approved correction
+	PendingRequest *model.UnlockRequest `json:"pending_request,omitempty"`
+}
+
+// SessionLockService locks the attendance of sessions once it was submitted, and reopens them for
+// corrections approved by an administrator
+type SessionLockService struct {
+	courseRepo   *repository.CourseRepository
+	lockRepo     *repository.SessionLockRepository
+	auditService *AuditService
+	settings     LockSettings
+	timezone     *time.Location
+}
+
+// NewSessionLockService creates a new SessionLockService
+func NewSessionLockService(
+	courseRepo *repository.CourseRepository,
+	lockRepo *repository.SessionLockRepository,
+	auditService *AuditService,
+	settings LockSettings,
+	timezone *time.Location,
+) *SessionLockService {
+	return &SessionLockService{
+		courseRepo:   courseRepo,
+		lockRepo:     lockRepo,
+		auditService: auditService,
+		settings:     settings,
+		timezone:     timezone,
+	}
+}
+
+// GetLockStatus reports whether a session is locked, either manually or because it is older than
+// the automatic lock period, and whether a correction was approved
+func (s *SessionLockService) GetLockStatus(courseID uint, date, now time.Time) (SessionLockDTO, error) {
+	status := SessionLockDTO{CourseID: courseID, Date: date.Format("2006-01-02")}
+	lock, err := s.lockRepo.GetLock(courseID, date)
+	local := now.In(s.timezone)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	switch {
+	case err == nil:
+		status.Locked = true
+		status.LockedBy = lock.LockedBy
+		status.LockedAt = &lock.CreatedAt
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return status, err
+	case s.settings.AutoLockDays > 0 && !date.AddDate(0, 0, s.settings.AutoLockDays).After(today):
+		status.Locked = true
+		status.AutoLocked = true
+	}
+
+	requests, err := s.lockRepo.GetSessionRequests(courseID, date)
+	if err != nil {
+		return status, err
+	}
+	for _, request := range requests {
+		switch {
+		case request.Status == model.UnlockRequestPending && status.PendingRequest == nil:
+			status.PendingRequest = &request
+		case request.Status == model.UnlockRequestApproved && request.UnlockedUntil != nil && request.UnlockedUntil.After(now):
+			if status.UnlockedUntil == nil || request.UnlockedUntil.After(*status.UnlockedUntil) {
+				status.UnlockedUntil = request.UnlockedUntil
+			}
+		}
+	}
+	if status.UnlockedUntil != nil {
+		status.Locked = false
+	}
+	return status, nil
+}
+
+// CheckUnlocked returns ErrSessionLocked if the attendance of a session must not change
+func (s *SessionLockService) CheckUnlocked(courseID uint, date, now time.Time) error {
+	status, err := s.GetLockStatus(courseID, date, now)
+	if err != nil {
+		return err
+	}
+	if status.Locked {
+		return ErrSessionLocked
+	}
+	return nil
+}
+
+// Lock locks a session manually. Locking a session reopened for a correction ends the correction.
+func (s *SessionLockService) Lock(actor string, courseID uint, date, now time.Time) (SessionLockDTO, error) {
+	if _, err := getCourse(s.courseRepo, courseID); err != nil {
+		return SessionLockDTO{}, err
+	}
+	_, err := s.lockRepo.GetLock(courseID, date)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		lock := model.SessionLock{CourseID: courseID, Date: date, LockedBy: actor}
+		if err := s.lockRepo.CreateLock(&lock); err != nil {
+			return SessionLockDTO{}, err
+		}
+		if err := s.auditService.Record(actor, model.AuditActionCreate, model.AuditEntitySessionLock,
+			sessionAuditID(courseID, date), nil, lock); err != nil {
+			return SessionLockDTO{}, err
+		}
+	} else if err != nil {
+		return SessionLockDTO{}, err
+	}
+
+	requests, err := s.lockRepo.GetSessionRequests(courseID, date)
+	if err != nil {
+		return SessionLockDTO{}, err
+	}
+	for _, request := range requests {
+		if request.Status != model.UnlockRequestApproved || request.UnlockedUntil == nil || !request.UnlockedUntil.After(now) {
+			continue
+		}
+		before := request
+		request.UnlockedUntil = &now
+		if err := s.lockRepo.SaveRequest(&request); err != nil {
+			return SessionLockDTO{}, err
+		}
+		if err := s.auditService.Record(actor, model.AuditActionUpdate, model.AuditEntityUnlockRequest,
+			fmt.Sprint(request.ID), before, request); err != nil {
+			return SessionLockDTO{}, err
+		}
+	}
+	return s.GetLockStatus(courseID, date, now)
+}
+
+// RequestUnlock asks an administrator to reopen a locked session for a correction
+func (s *SessionLockService) RequestUnlock(actor string, courseID uint, date time.Time, reason string, now time.Time) (model.UnlockRequest, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return model.UnlockRequest{}, fmt.Errorf("%w: reason missing", ErrInvalidUnlockRequest)
+	}
+	if _, err := getCourse(s.courseRepo, courseID); err != nil {
+		return model.UnlockRequest{}, err
+	}
+	status, err := s.GetLockStatus(courseID, date, now)
+	if err != nil {
+		return model.UnlockRequest{}, err
+	}
+	if status.PendingRequest != nil {
+		return *status.PendingRequest, ErrUnlockRequestDuplicated
+	}
+	if !status.Locked {
+		return model.UnlockRequest{}, ErrSessionNotLocked
+	}
+
+	request := model.UnlockRequest{
+		CourseID:    courseID,
+		Date:        date,
+		RequestedBy: actor,
+		Reason:      reason,
+		Status:      model.UnlockRequestPending,
+	}
+	if err := s.lockRepo.CreateRequest(&request); err != nil {
+		return request, err
+	}
+	err = s.auditService.Record(actor, model.AuditActionCreate, model.AuditEntityUnlockRequest, fmt.Sprint(request.ID), nil, request)
+	return request, err
+}
+
+// GetUnlockRequests retrieves unlock requests by status; an empty status returns all
+func (s *SessionLockService) GetUnlockRequests(status string) ([]model.UnlockRequest, error) {
+	return s.lockRepo.GetRequestsByStatus(status)
+}
+
+// ApproveUnlock reopens the session of a pending unlock request for the configured unlock period
+func (s *SessionLockService) ApproveUnlock(actor string, id uint, now time.Time) (model.UnlockRequest, error) {
+	unlockedUntil := now.Add(s.settings.UnlockPeriod)
+	return s.decide(actor, id, model.UnlockRequestApproved, &unlockedUntil, now)
+}
+
+// RejectUnlock declines a pending unlock request, leaving the session locked
+func (s *SessionLockService) RejectUnlock(actor string, id uint, now time.Time) (model.UnlockRequest, error) {
+	return s.decide(actor, id, model.UnlockRequestRejected, nil, now)
+}
+
+// decide records the decision on a pending unlock request
+func (s *SessionLockService) decide(actor string, id uint, status string, unlockedUntil *time.Time, now time.Time) (model.UnlockRequest, error) {
+	request, err := s.lockRepo.GetRequestByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return request, ErrUnlockRequestNotFound
+	}
+	if err != nil {
+		return request, err
+	}
+	if request.Status != model.UnlockRequestPending {
+		return request, ErrUnlockRequestState
+	}
+
+	before := request
+	request.Status = status
+	request.DecidedBy = actor
+	request.DecidedAt = &now
+	request.UnlockedUntil = unlockedUntil
+	if err := s.lockRepo.SaveRequest(&request); err != nil {
+		return request, err
+	}
+	err = s.auditService.Record(actor, model.AuditActionUpdate, model.AuditEntityUnlockRequest, fmt.Sprint(request.ID), before, request)
+	return request, err
+}
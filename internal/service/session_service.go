@@ -0,0 +1,407 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"azh/internal/model"
+	"azh/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Errors returned when recording or cancelling sessions
+var (
+	ErrInvalidSession   = errors.New("invalid session")
+	ErrSessionCancelled = errors.New("session cancelled")
+)
+
+// maxWeatherLength is the maximum length of the weather note of a session
+const maxWeatherLength = 50
+
+// SessionDTO represents the record of a session. Start and end are the actual times if they were
+// recorded, the scheduled times otherwise.
+type SessionDTO struct {
+	CourseID        uint       `json:"course_id"`
+	Date            string     `json:"date"`
+	Recorded        bool       `json:"recorded"` // false until attendance or notes were recorded
+	ScheduledStart  string     `json:"scheduled_start"`
+	ScheduledEnd    string     `json:"scheduled_end"`
+	StartTime       string     `json:"start_time"`
+	EndTime         string     `json:"end_time"`
+	Notes           string     `json:"notes"`
+	ContentTags     []string   `json:"content_tags"`
+	Weather         string     `json:"weather"`
+	TrainersPresent int        `json:"trainers_present"`
+	Cancelled       bool       `json:"cancelled"`
+	CancelReason    string     `json:"cancel_reason,omitempty"`
+	UpdatedAt       *time.Time `json:"updated_at,omitempty"`
+}
+
+// SessionRequest holds the editable values of a session; empty times mean as scheduled
+type SessionRequest struct {
+	StartTime       string   `json:"start_time"`
+	EndTime         string   `json:"end_time"`
+	Notes           string   `json:"notes"`
+	ContentTags     []string `json:"content_tags"`
+	Weather         string   `json:"weather"`
+	TrainersPresent int      `json:"trainers_present"`
+}
+
+// CancelRequest holds the reason for cancelling a session, passed on to the members
+type CancelRequest struct {
+	Reason string `json:"reason"`
+}
+
+// SessionNotificationsDTO lists the notifications sent about a session with their count per status
+type SessionNotificationsDTO struct {
+	CourseID      uint                 `json:"course_id"`
+	Date          string               `json:"date"`
+	Counts        map[string]int       `json:"counts"`
+	Notifications []model.Notification `json:"notifications"`
+}
+
+// SessionService handles the notes and training content recorded for sessions and the
+// cancellation of sessions
+type SessionService struct {
+	courseRepo          *repository.CourseRepository
+	sessionRepo         *repository.SessionRepository
+	memberRepo          *repository.MemberRepository
+	memberCourseRepo    *repository.MemberCourseRepository
+	sessionLockService  *SessionLockService
+	notificationService *NotificationService
+	auditService        *AuditService
+}
+
+// NewSessionService creates a new SessionService
+func NewSessionService(
+	courseRepo *repository.CourseRepository,
+	sessionRepo *repository.SessionRepository,
+	memberRepo *repository.MemberRepository,
+	memberCourseRepo *repository.MemberCourseRepository,
+	sessionLockService *SessionLockService,
+	notificationService *NotificationService,
+	auditService *AuditService,
+) *SessionService {
+	return &SessionService{
+		courseRepo:          courseRepo,
+		sessionRepo:         sessionRepo,
+		memberRepo:          memberRepo,
+		memberCourseRepo:    memberCourseRepo,
+		sessionLockService:  sessionLockService,
+		notificationService: notificationService,
+		auditService:        auditService,
+	}
+}
+
+// GetSession retrieves the record of a session, or an empty record if nothing was recorded yet
+func (s *SessionService) GetSession(courseID uint, date time.Time) (SessionDTO, error) {
+	course, err := getCourse(s.courseRepo, courseID)
+	if err != nil {
+		return SessionDTO{}, err
+	}
+	session, err := s.sessionRepo.Get(courseID, date)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return toSessionDTO(course, model.Session{CourseID: courseID, Date: date}), nil
+	}
+	if err != nil {
+		return SessionDTO{}, err
+	}
+	return toSessionDTO(course, session), nil
+}
+
+// GetSessions retrieves the recorded sessions of a course within a date range, the course's
+// training content log
+func (s *SessionService) GetSessions(courseID uint, minDate, maxDate string) ([]SessionDTO, error) {
+	course, err := getCourse(s.courseRepo, courseID)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := s.sessionRepo.GetByCourse(courseID, minDate, maxDate)
+	if err != nil {
+		return nil, err
+	}
+	dtos := make([]SessionDTO, 0, len(sessions))
+	for _, session := range sessions {
+		dtos = append(dtos, toSessionDTO(course, session))
+	}
+	return dtos, nil
+}
+
+// UpdateSession records the notes and training content of a session. Sessions locked after their
+// attendance was submitted cannot be changed.
+func (s *SessionService) UpdateSession(actor string, courseID uint, date time.Time, req SessionRequest, now time.Time) (SessionDTO, error) {
+	course, err := getCourse(s.courseRepo, courseID)
+	if err != nil {
+		return SessionDTO{}, err
+	}
+	tags, err := validateSession(req)
+	if err != nil {
+		return SessionDTO{}, err
+	}
+	if err := s.sessionLockService.CheckUnlocked(courseID, date, now); err != nil {
+		return SessionDTO{}, err
+	}
+
+	session, before, action, err := s.load(courseID, date)
+	if err != nil {
+		return SessionDTO{}, err
+	}
+	session.StartTime = req.StartTime
+	session.EndTime = req.EndTime
+	session.Notes = strings.TrimSpace(req.Notes)
+	session.ContentTags = strings.Join(tags, ",")
+	session.Weather = strings.TrimSpace(req.Weather)
+	session.TrainersPresent = req.TrainersPresent
+	if err := s.sessionRepo.Save(&session); err != nil {
+		return SessionDTO{}, err
+	}
+	if err := s.auditService.Record(actor, action, model.AuditEntitySession, sessionAuditID(courseID, date), before, session); err != nil {
+		return SessionDTO{}, err
+	}
+	return toSessionDTO(course, session), nil
+}
+
+// CancelSession marks a session cancelled and notifies the members enrolled in the course who
+// are members on that date. Cancelled sessions take no attendance and do not count in the
+// statistics. The delivery of the notifications can be followed through GetNotifications.
+// Cancelling a cancelled session returns ErrSessionCancelled, unless no notifications were
+// queued for it yet; then they are queued with the original reason.
+func (s *SessionService) CancelSession(actor string, courseID uint, date time.Time, req CancelRequest, now time.Time) (SessionNotificationsDTO, error) {
+	course, err := getCourse(s.courseRepo, courseID)
+	if err != nil {
+		return SessionNotificationsDTO{}, err
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return SessionNotificationsDTO{}, fmt.Errorf("%w: reason missing", ErrInvalidSession)
+	}
+	if err := s.sessionLockService.CheckUnlocked(courseID, date, now); err != nil {
+		return SessionNotificationsDTO{}, err
+	}
+	session, before, action, err := s.load(courseID, date)
+	if err != nil {
+		return SessionNotificationsDTO{}, err
+	}
+	if session.CancelledAt != nil {
+		// A cancellation whose notifications could not be queued may be repeated to queue them
+		queued, err := s.notificationService.GetNotifications(sessionReference(courseID, date), "")
+		if err != nil {
+			return SessionNotificationsDTO{}, err
+		}
+		if len(queued) > 0 {
+			return SessionNotificationsDTO{}, ErrSessionCancelled
+		}
+		reason = session.CancelReason
+	} else {
+		session.CancelledAt = &now
+		session.CancelledBy = actor
+		session.CancelReason = reason
+		if err := s.sessionRepo.Save(&session); err != nil {
+			return SessionNotificationsDTO{}, err
+		}
+		if err := s.auditService.Record(actor, action, model.AuditEntitySession, sessionAuditID(courseID, date), before, session); err != nil {
+			return SessionNotificationsDTO{}, err
+		}
+	}
+
+	enrolled, err := s.memberCourseRepo.GetMembersByCourseID(fmt.Sprint(courseID))
+	if err != nil {
+		return SessionNotificationsDTO{}, err
+	}
+	members, err := s.memberRepo.GetByIDsAndDate(enrolled, date)
+	if err != nil {
+		return SessionNotificationsDTO{}, err
+	}
+	memberIDs := make([]uint, 0, len(members))
+	for _, member := range members {
+		memberIDs = append(memberIDs, member.ID)
+	}
+	startTime, endTime := sessionTimes(course, date)
+	notifications, err := s.notificationService.NotifyMembers(memberIDs, "session_cancelled", sessionReference(courseID, date),
+		map[string]interface{}{
+			"Course":    course.Name,
+			"Date":      date.Format("02.01.2006"),
+			"StartTime": startTime,
+			"EndTime":   endTime,
+			"Reason":    reason,
+		}, now)
+	if err != nil {
+		return SessionNotificationsDTO{}, err
+	}
+	return toSessionNotificationsDTO(courseID, date, notifications), nil
+}
+
+// GetNotifications retrieves the notifications sent about a session, e.g. its cancellation
+func (s *SessionService) GetNotifications(courseID uint, date time.Time) (SessionNotificationsDTO, error) {
+	if _, err := getCourse(s.courseRepo, courseID); err != nil {
+		return SessionNotificationsDTO{}, err
+	}
+	notifications, err := s.notificationService.GetNotifications(sessionReference(courseID, date), "")
+	if err != nil {
+		return SessionNotificationsDTO{}, err
+	}
+	return toSessionNotificationsDTO(courseID, date, notifications), nil
+}
+
+// CheckNotCancelled returns ErrSessionCancelled if the session was cancelled
+func (s *SessionService) CheckNotCancelled(courseID uint, date time.Time) error {
+	session, err := s.sessionRepo.Get(courseID, date)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if session.CancelledAt != nil {
+		return ErrSessionCancelled
+	}
+	return nil
+}
+
+// load retrieves a session for changing it, or a new one if nothing was recorded yet, together
+// with the audit value and action of the change
+func (s *SessionService) load(courseID uint, date time.Time) (model.Session, any, string, error) {
+	session, err := s.sessionRepo.Get(courseID, date)
+	switch {
+	case err == nil:
+		return session, session, model.AuditActionUpdate, nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return model.Session{CourseID: courseID, Date: date}, nil, model.AuditActionCreate, nil
+	default:
+		return session, nil, "", err
+	}
+}
+
+// sessionReference identifies a session in the references of notifications
+func sessionReference(courseID uint, date time.Time) string {
+	return "session:" + sessionAuditID(courseID, date)
+}
+
+// toSessionNotificationsDTO counts the notifications of a session per status
+func toSessionNotificationsDTO(courseID uint, date time.Time, notifications []model.Notification) SessionNotificationsDTO {
+	dto := SessionNotificationsDTO{
+		CourseID:      courseID,
+		Date:          date.Format("2006-01-02"),
+		Counts:        make(map[string]int),
+		Notifications: notifications,
+	}
+	for _, notification := range notifications {
+		dto.Counts[notification.Status]++
+	}
+	return dto
+}
+
+// exportRows builds the sessions export layout including its header
+func (s *SessionService) exportRows(minDate, maxDate string) ([][]string, error) {
+	sessions, err := s.sessionRepo.GetInRange(minDate, maxDate)
+	if err != nil {
+		return nil, err
+	}
+	var courseIDs []uint
+	for _, session := range sessions {
+		if !slices.Contains(courseIDs, session.CourseID) {
+			courseIDs = append(courseIDs, session.CourseID)
+		}
+	}
+	courses := make(map[uint]model.Course)
+	if len(courseIDs) > 0 {
+		list, err := s.courseRepo.GetByIDs(courseIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, course := range list {
+			courses[course.ID] = course
+		}
+	}
+
+	rows := [][]string{{"Date", "CourseID", "StartTime", "EndTime", "TrainersPresent", "Weather", "ContentTags", "Notes"}}
+	for _, session := range sessions {
+		dto := toSessionDTO(courses[session.CourseID], session)
+		rows = append(rows, []string{
+			dto.Date,
+			fmt.Sprintf("%d", session.CourseID),
+			dto.StartTime,
+			dto.EndTime,
+			fmt.Sprintf("%d", dto.TrainersPresent),
+			dto.Weather,
+			strings.Join(dto.ContentTags, ", "),
+			dto.Notes,
+		})
+	}
+	return rows, nil
+}
+
+// validateSession checks the values of a session update and returns its normalized content tags
+func validateSession(req SessionRequest) ([]string, error) {
+	times := make([]time.Time, 0, 2)
+	for _, value := range []string{req.StartTime, req.EndTime} {
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse("15:04", value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid time %s", ErrInvalidSession, value)
+		}
+		times = append(times, parsed)
+	}
+	if req.StartTime != "" && req.EndTime != "" && !times[1].After(times[0]) {
+		return nil, fmt.Errorf("%w: end time must be after start time", ErrInvalidSession)
+	}
+	if req.TrainersPresent < 0 {
+		return nil, fmt.Errorf("%w: negative number of trainers", ErrInvalidSession)
+	}
+	if utf8.RuneCountInString(strings.TrimSpace(req.Weather)) > maxWeatherLength {
+		return nil, fmt.Errorf("%w: weather note too long", ErrInvalidSession)
+	}
+	tags := []string{}
+	for _, tag := range req.ContentTags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || slices.Contains(tags, tag) {
+			continue
+		}
+		if strings.Contains(tag, ",") {
+			return nil, fmt.Errorf("%w: content tags must not contain commas", ErrInvalidSession)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// toSessionDTO converts a session of a course into its DTO
+func toSessionDTO(course model.Course, session model.Session) SessionDTO {
+	scheduledStart, scheduledEnd := sessionTimes(course, session.Date)
+	dto := SessionDTO{
+		CourseID:        session.CourseID,
+		Date:            session.Date.Format("2006-01-02"),
+		Recorded:        session.ID != 0,
+		ScheduledStart:  scheduledStart,
+		ScheduledEnd:    scheduledEnd,
+		StartTime:       scheduledStart,
+		EndTime:         scheduledEnd,
+		Notes:           session.Notes,
+		ContentTags:     []string{},
+		Weather:         session.Weather,
+		TrainersPresent: session.TrainersPresent,
+	}
+	if session.StartTime != "" {
+		dto.StartTime = session.StartTime
+	}
+	if session.EndTime != "" {
+		dto.EndTime = session.EndTime
+	}
+	if session.ContentTags != "" {
+		dto.ContentTags = strings.Split(session.ContentTags, ",")
+	}
+	if session.CancelledAt != nil {
+		dto.Cancelled = true
+		dto.CancelReason = session.CancelReason
+	}
+	if session.ID != 0 {
+		dto.UpdatedAt = &session.UpdatedAt
+	}
+	return dto
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"azh/internal/repository"
+)
+
+// StatsService handles business logic for attendance statistics
+type StatsService struct {
+	statsRepo *repository.StatsRepository
+}
+
+// NewStatsService creates a new StatsService
+func NewStatsService(statsRepo *repository.StatsRepository) *StatsService {
+	return &StatsService{statsRepo: statsRepo}
+}
+
+// GetMemberStats retrieves the attendance rate per member and course
+func (s *StatsService) GetMemberStats(filter repository.StatsFilter) ([]repository.MemberAttendanceStat, error) {
+	return s.statsRepo.GetMemberStats(filter)
+}
+
+// GetCourseStats retrieves average attendance figures per course
+func (s *StatsService) GetCourseStats(filter repository.StatsFilter) ([]repository.CourseAttendanceStat, error) {
+	return s.statsRepo.GetCourseStats(filter)
+}
+
+// GetSessionHeadCounts retrieves the head count per held session
+func (s *StatsService) GetSessionHeadCounts(filter repository.StatsFilter) ([]repository.SessionHeadCount, error) {
+	return s.statsRepo.GetSessionHeadCounts(filter)
+}
+
+// GetStreaks retrieves attendance streaks per member and course
+func (s *StatsService) GetStreaks(filter repository.StatsFilter) ([]repository.AttendanceStreak, error) {
+	return s.statsRepo.GetStreaks(filter)
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"azh/internal/model"
+	"azh/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidSync is returned for sync requests without client or change IDs
+var ErrInvalidSync = errors.New("invalid sync request")
+
+// maxSyncIDLength is the maximum length of client and change IDs
+const maxSyncIDLength = 64
+
+// Conflict resolutions reported by a sync
+const (
+	SyncResolutionDevice = "device"
+	SyncResolutionServer = "server"
+)
+
+// SyncChange represents an attendance change recorded on a device
+type SyncChange struct {
+	ChangeID       string    `json:"change_id"`
+	CourseID       uint      `json:"course_id"`
+	MemberID       uint      `json:"member_id"`
+	Date           string    `json:"date"`
+	Status         string    `json:"status"`
+	PreviousStatus string    `json:"previous_status"` // status shown on the device before the change
+	ChangedAt      time.Time `json:"changed_at"`
+}
+
+// SyncChangeResult represents the outcome of a synced change
+type SyncChangeResult struct {
+	ChangeID  string `json:"change_id"`
+	Outcome   string `json:"outcome"`
+	Duplicate bool   `json:"duplicate,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// SyncConflict reports a synced change that met a different change of the same attendance
+type SyncConflict struct {
+	ChangeID        string     `json:"change_id"`
+	CourseID        uint       `json:"course_id"`
+	MemberID        uint       `json:"member_id"`
+	Date            string     `json:"date"`
+	DeviceStatus    string     `json:"device_status"`
+	ServerStatus    string     `json:"server_status"`
+	ServerChangedAt *time.Time `json:"server_changed_at,omitempty"`
+	Resolution      string     `json:"resolution"`
+}
+
+// SyncResult represents the outcome of a sync
+type SyncResult struct {
+	Results   []SyncChangeResult `json:"results"`
+	Conflicts []SyncConflict     `json:"conflicts"`
+}
+
+// SyncService applies attendance changes recorded on offline devices
+type SyncService struct {
+	participationService *ParticipationService
+	participationRepo    *repository.ParticipationRepository
+	attendanceChangeRepo *repository.AttendanceChangeRepository
+}
+
+// NewSyncService creates a new SyncService
+func NewSyncService(
+	participationService *ParticipationService,
+	participationRepo *repository.ParticipationRepository,
+	attendanceChangeRepo *repository.AttendanceChangeRepository,
+) *SyncService {
+	return &SyncService{
+		participationService: participationService,
+		participationRepo:    participationRepo,
+		attendanceChangeRepo: attendanceChangeRepo,
+	}
+}
+
+// Sync applies the changes of a device in the order they were made. Changes already synced are
+// skipped, so a device can resend its queue after a lost response. Conflicts are resolved by last
+// writer wins: a change older than the latest change of the same attendance is not applied, and
+// a change overwriting a status the device did not know about is applied but reported.
+func (s *SyncService) Sync(actor, clientID string, changes []SyncChange, now time.Time) (SyncResult, error) {
+	if clientID == "" || len(clientID) > maxSyncIDLength {
+		return SyncResult{}, ErrInvalidSync
+	}
+	changeIDs := make([]string, 0, len(changes))
+	for _, change := range changes {
+		if change.ChangeID == "" || len(change.ChangeID) > maxSyncIDLength {
+			return SyncResult{}, ErrInvalidSync
+		}
+		changeIDs = append(changeIDs, change.ChangeID)
+	}
+
+	synced := make(map[string]model.AttendanceChange)
+	if len(changeIDs) > 0 {
+		journal, err := s.attendanceChangeRepo.GetByClientChange(clientID, changeIDs)
+		if err != nil {
+			return SyncResult{}, err
+		}
+		for _, entry := range journal {
+			synced[entry.ChangeID] = entry
+		}
+	}
+
+	ordered := slices.Clone(changes)
+	slices.SortStableFunc(ordered, func(a, b SyncChange) int {
+		return a.ChangedAt.Compare(b.ChangedAt)
+	})
+
+	result := SyncResult{Results: make([]SyncChangeResult, 0, len(ordered)), Conflicts: []SyncConflict{}}
+	for _, change := range ordered {
+		if entry, ok := synced[change.ChangeID]; ok {
+			result.Results = append(result.Results, SyncChangeResult{
+				ChangeID:  change.ChangeID,
+				Outcome:   entry.Outcome,
+				Duplicate: true,
+				Reason:    entry.Reason,
+			})
+			continue
+		}
+		entry, conflict, err := s.syncChange(actor, clientID, change, now)
+		if err != nil {
+			return result, err
+		}
+		synced[change.ChangeID] = entry
+		result.Results = append(result.Results, SyncChangeResult{
+			ChangeID: change.ChangeID,
+			Outcome:  entry.Outcome,
+			Reason:   entry.Reason,
+		})
+		if conflict != nil {
+			result.Conflicts = append(result.Conflicts, *conflict)
+		}
+	}
+	return result, nil
+}
+
+// syncChange applies a single change and returns its journal entry and a conflict, if any
+func (s *SyncService) syncChange(actor, clientID string, change SyncChange, now time.Time) (model.AttendanceChange, *SyncConflict, error) {
+	entry := model.AttendanceChange{
+		ClientID:  clientID,
+		ChangeID:  change.ChangeID,
+		Actor:     actor,
+		MemberID:  change.MemberID,
+		CourseID:  change.CourseID,
+		Status:    change.Status,
+		ChangedAt: syncChangedAt(change.ChangedAt, now),
+	}
+
+	date, err := time.Parse("2006-01-02", change.Date)
+	if err != nil {
+		return s.reject(entry, "invalid date format")
+	}
+	entry.Date = date
+	switch change.Status {
+	case model.ParticipationStatusPresent, model.ParticipationStatusExcused, model.ParticipationStatusAbsent:
+	default:
+		return s.reject(entry, fmt.Sprintf("invalid attendance status: %s", change.Status))
+	}
+
+	latest, err := s.attendanceChangeRepo.GetLatestApplied(change.MemberID, change.CourseID, date)
+	var lastApplied *model.AttendanceChange
+	switch {
+	case err == nil:
+		lastApplied = &latest
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return entry, nil, err
+	}
+	current, err := s.participationRepo.GetStatus(change.MemberID, change.CourseID, date)
+	if err != nil {
+		return entry, nil, err
+	}
+
+	apply, conflict := resolveSync(change, entry.ChangedAt, lastApplied, current)
+	if !apply {
+		entry.Outcome = model.AttendanceChangeSuperseded
+		if err := s.attendanceChangeRepo.Create(&entry); err != nil {
+			return entry, nil, err
+		}
+		return entry, conflict, nil
+	}
+	err = s.participationService.applyAttendance(&entry, s.participationRepo.ApplyChanges)
+	if errors.Is(err, ErrCourseNotFound) {
+		return s.reject(entry, "course not found")
+	}
+	if errors.Is(err, ErrNoCredits) {
+		return s.reject(entry, "no punch card credits left")
+	}
+	if errors.Is(err, ErrSessionLocked) {
+		return s.reject(entry, "session locked")
+	}
+	if errors.Is(err, ErrSessionCancelled) {
+		return s.reject(entry, "session cancelled")
+	}
+	if err != nil {
+		return entry, nil, err
+	}
+	return entry, conflict, nil
+}
+
+// syncChangedAt returns the time a device change counts as made; device clocks running ahead must
+// not win every later conflict
+func syncChangedAt(changedAt, now time.Time) time.Time {
+	if changedAt.IsZero() || changedAt.After(now) {
+		return now
+	}
+	return changedAt
+}
+
+// resolveSync decides by last writer wins whether a change made at changedAt is applied, given the
+// latest change applied to the same attendance, if any, and its current status. A conflict is
+// reported when an older change is not applied although its status differs, and when an applied
+// change overwrites a status the device did not know about.
+func resolveSync(change SyncChange, changedAt time.Time, latest *model.AttendanceChange, current string) (bool, *SyncConflict) {
+	conflict := &SyncConflict{
+		ChangeID:     change.ChangeID,
+		CourseID:     change.CourseID,
+		MemberID:     change.MemberID,
+		Date:         change.Date,
+		DeviceStatus: change.Status,
+	}
+	if latest != nil && latest.ChangedAt.After(changedAt) {
+		if latest.Status == change.Status {
+			return false, nil
+		}
+		conflict.ServerStatus = latest.Status
+		conflict.ServerChangedAt = &latest.ChangedAt
+		conflict.Resolution = SyncResolutionServer
+		return false, conflict
+	}
+
+	if change.PreviousStatus == "" || current == change.PreviousStatus || current == change.Status {
+		return true, nil
+	}
+	conflict.ServerStatus = current
+	if latest != nil {
+		conflict.ServerChangedAt = &latest.ChangedAt
+	}
+	conflict.Resolution = SyncResolutionDevice
+	return true, conflict
+}
+
+// reject records a change that cannot be applied, so resending it yields the same result
+func (s *SyncService) reject(entry model.AttendanceChange, reason string) (model.AttendanceChange, *SyncConflict, error) {
+	entry.Outcome = model.AttendanceChangeRejected
+	entry.Reason = reason
+	if err := s.attendanceChangeRepo.Create(&entry); err != nil {
+		return entry, nil, err
+	}
+	return entry, nil, nil
+}
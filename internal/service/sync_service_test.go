@@ -0,0 +1,83 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"azh/internal/model"
+)
+
+func TestSyncChangedAt(t *testing.T) {
+	now := time.Date(2026, 10, 19, 18, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		changedAt time.Time
+		want      time.Time
+	}{
+		{"device time kept", now.Add(-time.Hour), now.Add(-time.Hour)},
+		{"missing time", time.Time{}, now},
+		{"device clock ahead", now.Add(time.Minute), now},
+		{"same time", now, now},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := syncChangedAt(tt.changedAt, now); !got.Equal(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestResolveSync(t *testing.T) {
+	changedAt := time.Date(2026, 10, 19, 18, 0, 0, 0, time.UTC)
+	applied := func(status string, at time.Time) *model.AttendanceChange {
+		return &model.AttendanceChange{Status: status, ChangedAt: at}
+	}
+	present, excused, absent := model.ParticipationStatusPresent, model.ParticipationStatusExcused, model.ParticipationStatusAbsent
+
+	tests := []struct {
+		name           string
+		status         string
+		previousStatus string
+		latest         *model.AttendanceChange
+		current        string
+		wantApply      bool
+		wantResolution string // empty for no conflict
+		wantServer     string
+	}{
+		{"first change", present, absent, nil, absent, true, "", ""},
+		{"device knew the current status", present, absent, applied(absent, changedAt.Add(-time.Hour)), absent, true, "", ""},
+		{"status already set", present, absent, applied(present, changedAt.Add(-time.Hour)), present, true, "", ""},
+		{"device without previous status", present, "", applied(excused, changedAt.Add(-time.Hour)), excused, true, "", ""},
+		{"overwrites an unknown status", present, absent, applied(excused, changedAt.Add(-time.Hour)), excused, true, SyncResolutionDevice, excused},
+		{"overwrites a status set without journal", present, absent, nil, excused, true, SyncResolutionDevice, excused},
+		{"newer change on the server", present, absent, applied(excused, changedAt.Add(time.Minute)), excused, false, SyncResolutionServer, excused},
+		{"newer change with the same status", present, absent, applied(present, changedAt.Add(time.Minute)), present, false, "", ""},
+		{"change at the same time wins", present, absent, applied(excused, changedAt), excused, true, SyncResolutionDevice, excused},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change := SyncChange{ChangeID: "c1", CourseID: 1, MemberID: 2, Date: "2026-10-19", Status: tt.status, PreviousStatus: tt.previousStatus}
+			apply, conflict := resolveSync(change, changedAt, tt.latest, tt.current)
+			if apply != tt.wantApply {
+				t.Fatalf("expected apply %v, got %v", tt.wantApply, apply)
+			}
+			if tt.wantResolution == "" {
+				if conflict != nil {
+					t.Fatalf("expected no conflict, got %+v", conflict)
+				}
+				return
+			}
+			if conflict == nil {
+				t.Fatal("expected a conflict")
+			}
+			if conflict.Resolution != tt.wantResolution || conflict.ServerStatus != tt.wantServer || conflict.DeviceStatus != tt.status {
+				t.Fatalf("unexpected conflict %+v", conflict)
+			}
+			if (tt.latest != nil) != (conflict.ServerChangedAt != nil) {
+				t.Fatalf("expected server change time only with a journal entry, got %+v", conflict)
+			}
+		})
+	}
+}
@@ -0,0 +1,647 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	netmail "net/mail"
+	"sort"
+	"strings"
+	"time"
+
+	"azh/internal/model"
+	"azh/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Errors returned by the trainer service
+var (
+	ErrTrainerNotFound = errors.New("trainer not found")
+	ErrInvalidTrainer  = errors.New("invalid trainer")
+)
+
+// TrainerRequest holds the data of a trainer maintained by administrators
+type TrainerRequest struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// SessionTrainerDTO represents a trainer assigned to a session
+type SessionTrainerDTO struct {
+	TrainerID       uint   `json:"trainer_id"`
+	Name            string `json:"name"`
+	SubstituteForID *uint  `json:"substitute_for_id,omitempty"`
+	Unavailable     bool   `json:"unavailable"`
+}
+
+// SessionTrainersDTO lists the trainers of a session and whether they differ from the course's trainers
+type SessionTrainersDTO struct {
+	CourseID   uint                `json:"course_id"`
+	Date       string              `json:"date"`
+	Trainers   []SessionTrainerDTO `json:"trainers"`
+	Overridden bool                `json:"overridden"`
+	Warnings   []string            `json:"warnings"`
+}
+
+// TrainerAssignment assigns a trainer to a session, optionally substituting a regular trainer
+type TrainerAssignment struct {
+	TrainerID       uint  `json:"trainer_id"`
+	SubstituteForID *uint `json:"substitute_for_id"`
+}
+
+// UncoveredSessionDTO represents a scheduled session for which assigned trainers are unavailable
+type UncoveredSessionDTO struct {
+	CourseID    uint            `json:"course_id"`
+	CourseName  string          `json:"course_name"`
+	Date        string          `json:"date"`
+	StartTime   string          `json:"start_time"`
+	EndTime     string          `json:"end_time"`
+	Unavailable []model.Trainer `json:"unavailable"`
+	Available   []model.Trainer `json:"available"`
+	Covered     bool            `json:"covered"`
+}
+
+// TrainerMonth holds the sessions a trainer led within a month
+type TrainerMonth struct {
+	Month    int     `json:"month"`
+	Sessions int     `json:"sessions"`
+	Hours    float64 `json:"hours"`
+	Amount   float64 `json:"amount"`
+}
+
+// TrainerHoursReport holds the yearly Übungsleiter payroll figures of a trainer
+type TrainerHoursReport struct {
+	TrainerID    uint           `json:"trainer_id"`
+	Trainer      string         `json:"trainer"`
+	Email        string         `json:"email"`
+	Year         int            `json:"year"`
+	Months       []TrainerMonth `json:"months"`
+	Sessions     int            `json:"sessions"`
+	Hours        float64        `json:"hours"`
+	Amount       float64        `json:"amount"`
+	AllowanceCap float64        `json:"allowance_cap"`
+	ExceedsCap   bool           `json:"exceeds_cap"`
+}
+
+// PayrollSettings configures how trainer hours are paid
+type PayrollSettings struct {
+	HourlyRate   float64
+	AllowanceCap float64
+}
+
+// TrainerService handles business logic for trainers and the sessions they lead
+type TrainerService struct {
+	courseRepo           *repository.CourseRepository
+	participationRepo    *repository.ParticipationRepository
+	sessionTrainerRepo   *repository.SessionTrainerRepository
+	trainerRepo          *repository.TrainerRepository
+	qualificationService *QualificationService
+	auditService         *AuditService
+	payroll              PayrollSettings
+}
+
+// NewTrainerService creates a new TrainerService
+func NewTrainerService(
+	courseRepo *repository.CourseRepository,
+	participationRepo *repository.ParticipationRepository,
+	sessionTrainerRepo *repository.SessionTrainerRepository,
+	trainerRepo *repository.TrainerRepository,
+	qualificationService *QualificationService,
+	auditService *AuditService,
+	payroll PayrollSettings,
+) *TrainerService {
+	return &TrainerService{
+		courseRepo:           courseRepo,
+		participationRepo:    participationRepo,
+		sessionTrainerRepo:   sessionTrainerRepo,
+		trainerRepo:          trainerRepo,
+		qualificationService: qualificationService,
+		auditService:         auditService,
+		payroll:              payroll,
+	}
+}
+
+// GetTrainers retrieves all trainers
+func (s *TrainerService) GetTrainers() ([]model.Trainer, error) {
+	return s.trainerRepo.GetAll()
+}
+
+// CreateTrainer creates a trainer, e.g. one who does not lead a course yet
+func (s *TrainerService) CreateTrainer(actor string, req TrainerRequest) (model.Trainer, error) {
+	trainer, err := s.validate(0, req)
+	if err != nil {
+		return trainer, err
+	}
+	if err := s.trainerRepo.Save(&trainer); err != nil {
+		return trainer, err
+	}
+	return trainer, s.auditService.Record(actor, model.AuditActionCreate, model.AuditEntityTrainer, fmt.Sprint(trainer.ID), nil, trainer)
+}
+
+// UpdateTrainer updates the name and email address of a trainer. Courses are linked to trainers by
+// name, so a renamed trainer is only matched by imports if the course lists the new name.
+func (s *TrainerService) UpdateTrainer(actor string, trainerID uint, req TrainerRequest) (model.Trainer, error) {
+	before, err := s.getTrainer(trainerID)
+	if err != nil {
+		return before, err
+	}
+	trainer, err := s.validate(trainerID, req)
+	if err != nil {
+		return trainer, err
+	}
+	trainer.Model = before.Model
+	if err := s.trainerRepo.Save(&trainer); err != nil {
+		return trainer, err
+	}
+	return trainer, s.auditService.Record(actor, model.AuditActionUpdate, model.AuditEntityTrainer, fmt.Sprint(trainer.ID), before, trainer)
+}
+
+// NormalizeCourseTrainers creates trainers from a course's comma-separated trainer list and
+// links them to the course
+func (s *TrainerService) NormalizeCourseTrainers(course model.Course) error {
+	var trainerIDs []uint
+	for _, name := range splitTrainerNames(course.TrainerNames) {
+		trainer, err := s.trainerRepo.FindOrCreateByName(name)
+		if err != nil {
+			return fmt.Errorf("error saving trainer %s: %v", name, err)
+		}
+		trainerIDs = append(trainerIDs, trainer.ID)
+	}
+	return s.trainerRepo.ReplaceCourseTrainers(course.ID, trainerIDs)
+}
+
+// NormalizeUnlinkedCourseTrainers normalizes the trainer lists of all courses without linked
+// trainers, e.g. for data imported before trainers became entities
+func (s *TrainerService) NormalizeUnlinkedCourseTrainers() error {
+	courses, err := s.courseRepo.GetAllUnfiltered()
+	if err != nil {
+		return err
+	}
+	courseIDs := make([]uint, 0, len(courses))
+	for _, course := range courses {
+		courseIDs = append(courseIDs, course.ID)
+	}
+	links, err := s.trainerRepo.GetCourseTrainers(courseIDs)
+	if err != nil {
+		return err
+	}
+	linked := make(map[uint]struct{})
+	for _, link := range links {
+		linked[link.CourseID] = struct{}{}
+	}
+	for _, course := range courses {
+		if _, ok := linked[course.ID]; ok {
+			continue
+		}
+		if err := s.NormalizeCourseTrainers(course); err != nil {
+			return fmt.Errorf("error normalizing trainers of course %d: %v", course.ID, err)
+		}
+	}
+	return nil
+}
+
+// GetSessionTrainers retrieves the trainers assigned to a session, defaulting to the course's trainers,
+// and warns about trainers lacking qualifications required for the course's training type
+func (s *TrainerService) GetSessionTrainers(courseID uint, date time.Time) (SessionTrainersDTO, error) {
+	course, err := getCourse(s.courseRepo, courseID)
+	if err != nil {
+		return SessionTrainersDTO{}, err
+	}
+	dateStr := date.Format("2006-01-02")
+	assignments, err := s.loadAssignments([]uint{courseID}, dateStr, dateStr)
+	if err != nil {
+		return SessionTrainersDTO{}, err
+	}
+	unavailable, err := s.loadUnavailable(dateStr, dateStr)
+	if err != nil {
+		return SessionTrainersDTO{}, err
+	}
+	assigned, overridden := assignments.forSession(courseID, date)
+	trainers, err := s.trainersByID(assignedTrainerIDs(assigned))
+	if err != nil {
+		return SessionTrainersDTO{}, err
+	}
+
+	dto := SessionTrainersDTO{
+		CourseID:   courseID,
+		Date:       dateStr,
+		Trainers:   make([]SessionTrainerDTO, 0, len(assigned)),
+		Overridden: overridden,
+		Warnings:   []string{},
+	}
+	assignedTrainers := make([]model.Trainer, 0, len(assigned))
+	for _, assignment := range assigned {
+		dto.Trainers = append(dto.Trainers, SessionTrainerDTO{
+			TrainerID:       assignment.TrainerID,
+			Name:            trainers[assignment.TrainerID].Name,
+			SubstituteForID: assignment.SubstituteForID,
+			Unavailable:     unavailable.has(assignment.TrainerID, courseID, date),
+		})
+		assignedTrainers = append(assignedTrainers, trainers[assignment.TrainerID])
+	}
+	warnings, err := s.qualificationService.CheckTrainers(assignedTrainers, course.TrainingType, date)
+	if err != nil {
+		return SessionTrainersDTO{}, err
+	}
+	dto.Warnings = append(dto.Warnings, warnings...)
+	return dto, nil
+}
+
+// SetSessionTrainers assigns the trainers of a session, e.g. substitutes for unavailable trainers.
+// An empty list resets the session to the course's trainers.
+func (s *TrainerService) SetSessionTrainers(courseID uint, date time.Time, assignments []TrainerAssignment) (SessionTrainersDTO, error) {
+	if _, err := getCourse(s.courseRepo, courseID); err != nil {
+		return SessionTrainersDTO{}, err
+	}
+	seen := make(map[uint]struct{})
+	records := make([]model.SessionTrainer, 0, len(assignments))
+	for _, assignment := range assignments {
+		if _, duplicate := seen[assignment.TrainerID]; duplicate {
+			continue
+		}
+		if _, err := s.getTrainer(assignment.TrainerID); err != nil {
+			return SessionTrainersDTO{}, err
+		}
+		if assignment.SubstituteForID != nil {
+			if _, err := s.getTrainer(*assignment.SubstituteForID); err != nil {
+				return SessionTrainersDTO{}, err
+			}
+		}
+		seen[assignment.TrainerID] = struct{}{}
+		records = append(records, model.SessionTrainer{
+			CourseID:        courseID,
+			Date:            date,
+			TrainerID:       assignment.TrainerID,
+			SubstituteForID: assignment.SubstituteForID,
+		})
+	}
+	if err := s.sessionTrainerRepo.Replace(courseID, date, records); err != nil {
+		return SessionTrainersDTO{}, err
+	}
+	return s.GetSessionTrainers(courseID, date)
+}
+
+// AddUnavailability marks a trainer as unavailable on a date, for one course or all courses
+func (s *TrainerService) AddUnavailability(trainerID uint, date time.Time, courseID *uint, reason string) (model.TrainerUnavailability, error) {
+	if _, err := s.getTrainer(trainerID); err != nil {
+		return model.TrainerUnavailability{}, err
+	}
+	if courseID != nil {
+		if _, err := getCourse(s.courseRepo, *courseID); err != nil {
+			return model.TrainerUnavailability{}, err
+		}
+	}
+	unavailability := model.TrainerUnavailability{
+		TrainerID: trainerID,
+		CourseID:  courseID,
+		Date:      date,
+		Reason:    strings.TrimSpace(reason),
+	}
+	err := s.trainerRepo.CreateUnavailability(&unavailability)
+	return unavailability, err
+}
+
+// RemoveUnavailability removes a previously reported unavailability of a trainer
+func (s *TrainerService) RemoveUnavailability(trainerID, unavailabilityID uint) error {
+	return s.trainerRepo.DeleteUnavailability(trainerID, unavailabilityID)
+}
+
+// GetUncoveredSessions lists all scheduled sessions within a date range without trainers or for
+// which at least one assigned trainer is unavailable. A session counts as covered while any
+// assigned trainer is available.
+func (s *TrainerService) GetUncoveredSessions(minDate, maxDate string) ([]UncoveredSessionDTO, error) {
+	from, err := time.Parse("2006-01-02", minDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid minDate: %v", err)
+	}
+	to, err := time.Parse("2006-01-02", maxDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid maxDate: %v", err)
+	}
+	unavailable, err := s.loadUnavailable(minDate, maxDate)
+	if err != nil {
+		return nil, err
+	}
+	courses, err := s.courseRepo.GetActiveBetween(minDate, maxDate)
+	if err != nil {
+		return nil, err
+	}
+	courseIDs := make([]uint, 0, len(courses))
+	for _, course := range courses {
+		courseIDs = append(courseIDs, course.ID)
+	}
+	assignments, err := s.loadAssignments(courseIDs, minDate, maxDate)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []UncoveredSessionDTO{}
+	var trainerIDs []uint
+	for _, course := range courses {
+		for _, dateStr := range scheduledDates(course, from, to) {
+			date, _ := time.Parse("2006-01-02", dateStr)
+			assigned, _ := assignments.forSession(course.ID, date)
+			session := UncoveredSessionDTO{
+				CourseID:   course.ID,
+				CourseName: course.Name,
+				Date:       dateStr,
+				StartTime:  course.StartTime,
+				EndTime:    course.EndTime,
+			}
+			for _, assignment := range assigned {
+				trainer := model.Trainer{}
+				trainer.ID = assignment.TrainerID
+				if unavailable.has(assignment.TrainerID, course.ID, date) {
+					session.Unavailable = append(session.Unavailable, trainer)
+				} else {
+					session.Available = append(session.Available, trainer)
+				}
+				trainerIDs = append(trainerIDs, assignment.TrainerID)
+			}
+			if len(session.Unavailable) == 0 && len(assigned) > 0 {
+				continue
+			}
+			session.Covered = len(session.Available) > 0
+			sessions = append(sessions, session)
+		}
+	}
+
+	// Fill in trainer details
+	trainers, err := s.trainersByID(trainerIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		for j, trainer := range sessions[i].Unavailable {
+			sessions[i].Unavailable[j] = trainers[trainer.ID]
+		}
+		for j, trainer := range sessions[i].Available {
+			sessions[i].Available[j] = trainers[trainer.ID]
+		}
+	}
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].Date < sessions[j].Date })
+	return sessions, nil
+}
+
+// GetHoursReport computes the sessions and hours per trainer and month of a year for all held and
+// not cancelled sessions, and checks the yearly amount against the tax-free Übungsleiter allowance
+func (s *TrainerService) GetHoursReport(year int) ([]TrainerHoursReport, error) {
+	minDate := fmt.Sprintf("%04d-01-01", year)
+	maxDate := fmt.Sprintf("%04d-12-31", year)
+	sessions, err := s.participationRepo.GetHeldSessions(minDate, maxDate)
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return []TrainerHoursReport{}, nil
+	}
+
+	courseIDs := make([]uint, 0)
+	seenCourses := make(map[uint]struct{})
+	for _, session := range sessions {
+		if _, ok := seenCourses[session.CourseID]; !ok {
+			seenCourses[session.CourseID] = struct{}{}
+			courseIDs = append(courseIDs, session.CourseID)
+		}
+	}
+	courses, err := s.courseRepo.GetByIDs(courseIDs)
+	if err != nil {
+		return nil, err
+	}
+	coursesByID := make(map[uint]model.Course)
+	for _, course := range courses {
+		coursesByID[course.ID] = course
+	}
+	assignments, err := s.loadAssignments(courseIDs, minDate, maxDate)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make(map[uint]*TrainerHoursReport)
+	for _, session := range sessions {
+		hours := courseHours(coursesByID[session.CourseID], session.Date)
+		assigned, _ := assignments.forSession(session.CourseID, session.Date)
+		for _, assignment := range assigned {
+			report, ok := reports[assignment.TrainerID]
+			if !ok {
+				report = &TrainerHoursReport{
+					TrainerID:    assignment.TrainerID,
+					Year:         year,
+					Months:       make([]TrainerMonth, 12),
+					AllowanceCap: s.payroll.AllowanceCap,
+				}
+				for i := range report.Months {
+					report.Months[i].Month = i + 1
+				}
+				reports[assignment.TrainerID] = report
+			}
+			month := &report.Months[session.Date.Month()-1]
+			month.Sessions++
+			month.Hours += hours
+			report.Sessions++
+			report.Hours += hours
+		}
+	}
+
+	trainerIDs := make([]uint, 0, len(reports))
+	for trainerID := range reports {
+		trainerIDs = append(trainerIDs, trainerID)
+	}
+	trainers, err := s.trainersByID(trainerIDs)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]TrainerHoursReport, 0, len(reports))
+	for _, report := range reports {
+		report.Trainer = trainers[report.TrainerID].Name
+		report.Email = trainers[report.TrainerID].Email
+		for i := range report.Months {
+			report.Months[i].Amount = roundCents(report.Months[i].Hours * s.payroll.HourlyRate)
+		}
+		report.Amount = roundCents(report.Hours * s.payroll.HourlyRate)
+		report.ExceedsCap = report.AllowanceCap > 0 && report.Amount > report.AllowanceCap
+		result = append(result, *report)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Trainer < result[j].Trainer })
+	return result, nil
+}
+
+// sessionAssignments holds the regular trainers per course and the per-session assignments
+type sessionAssignments struct {
+	regular   map[uint][]uint
+	bySession map[string][]model.SessionTrainer
+}
+
+// forSession returns the trainers assigned to a session and whether they differ from the regular trainers
+func (a sessionAssignments) forSession(courseID uint, date time.Time) ([]TrainerAssignment, bool) {
+	if records, ok := a.bySession[sessionKey(courseID, date)]; ok {
+		assigned := make([]TrainerAssignment, 0, len(records))
+		for _, record := range records {
+			assigned = append(assigned, TrainerAssignment{TrainerID: record.TrainerID, SubstituteForID: record.SubstituteForID})
+		}
+		return assigned, true
+	}
+	assigned := make([]TrainerAssignment, 0, len(a.regular[courseID]))
+	for _, trainerID := range a.regular[courseID] {
+		assigned = append(assigned, TrainerAssignment{TrainerID: trainerID})
+	}
+	return assigned, false
+}
+
+// loadAssignments loads the regular trainers of the courses and all session assignments within a date range
+func (s *TrainerService) loadAssignments(courseIDs []uint, minDate, maxDate string) (sessionAssignments, error) {
+	assignments := sessionAssignments{
+		regular:   make(map[uint][]uint),
+		bySession: make(map[string][]model.SessionTrainer),
+	}
+	links, err := s.trainerRepo.GetCourseTrainers(courseIDs)
+	if err != nil {
+		return assignments, err
+	}
+	for _, link := range links {
+		assignments.regular[link.CourseID] = append(assignments.regular[link.CourseID], link.TrainerID)
+	}
+	records, err := s.sessionTrainerRepo.GetInRange(minDate, maxDate)
+	if err != nil {
+		return assignments, err
+	}
+	for _, record := range records {
+		key := sessionKey(record.CourseID, record.Date)
+		assignments.bySession[key] = append(assignments.bySession[key], record)
+	}
+	return assignments, nil
+}
+
+// unavailabilities indexes trainer unavailabilities by trainer and date
+type unavailabilities map[string][]model.TrainerUnavailability
+
+// has reports whether a trainer is unavailable for a course on a date
+func (u unavailabilities) has(trainerID, courseID uint, date time.Time) bool {
+	for _, entry := range u[fmt.Sprintf("%d-%s", trainerID, date.Format("2006-01-02"))] {
+		if entry.CourseID == nil || *entry.CourseID == courseID {
+			return true
+		}
+	}
+	return false
+}
+
+// loadUnavailable loads all trainer unavailabilities within a date range
+func (s *TrainerService) loadUnavailable(minDate, maxDate string) (unavailabilities, error) {
+	entries, err := s.trainerRepo.GetUnavailabilities(minDate, maxDate)
+	if err != nil {
+		return nil, err
+	}
+	index := make(unavailabilities)
+	for _, entry := range entries {
+		key := fmt.Sprintf("%d-%s", entry.TrainerID, entry.Date.Format("2006-01-02"))
+		index[key] = append(index[key], entry)
+	}
+	return index, nil
+}
+
+// getTrainer retrieves a trainer by ID and maps a missing record to ErrTrainerNotFound
+func (s *TrainerService) getTrainer(trainerID uint) (model.Trainer, error) {
+	trainer, err := s.trainerRepo.GetByID(trainerID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return trainer, ErrTrainerNotFound
+	}
+	return trainer, err
+}
+
+// validate checks the data of a trainer and converts it into a trainer; names must be unique
+func (s *TrainerService) validate(trainerID uint, req TrainerRequest) (model.Trainer, error) {
+	trainer := model.Trainer{
+		Name:  strings.TrimSpace(req.Name),
+		Email: strings.TrimSpace(req.Email),
+	}
+	if trainer.Name == "" {
+		return trainer, fmt.Errorf("%w: name missing", ErrInvalidTrainer)
+	}
+	if trainer.Email != "" {
+		if _, err := netmail.ParseAddress(trainer.Email); err != nil {
+			return trainer, fmt.Errorf("%w: invalid email address", ErrInvalidTrainer)
+		}
+	}
+	existing, err := s.trainerRepo.GetByName(trainer.Name)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return trainer, err
+	}
+	if err == nil && existing.ID != trainerID {
+		return trainer, fmt.Errorf("%w: name already used by another trainer", ErrInvalidTrainer)
+	}
+	return trainer, nil
+}
+
+// trainersByID loads the given trainers indexed by ID
+func (s *TrainerService) trainersByID(trainerIDs []uint) (map[uint]model.Trainer, error) {
+	trainers := make(map[uint]model.Trainer)
+	if len(trainerIDs) == 0 {
+		return trainers, nil
+	}
+	records, err := s.trainerRepo.GetByIDs(trainerIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, trainer := range records {
+		trainers[trainer.ID] = trainer
+	}
+	return trainers, nil
+}
+
+// assignedTrainerIDs extracts the trainer IDs of assignments
+func assignedTrainerIDs(assignments []TrainerAssignment) []uint {
+	ids := make([]uint, 0, len(assignments))
+	for _, assignment := range assignments {
+		ids = append(ids, assignment.TrainerID)
+	}
+	return ids
+}
+
+// courseHours derives the duration of a session in hours from its start and end time (HH:MM)
+func courseHours(course model.Course, date time.Time) float64 {
+	start, end, ok := sessionClock(course, date)
+	if !ok {
+		return 0
+	}
+	return end.Sub(start).Hours()
+}
+
+// sessionClock parses the start and end time of a session; ok is false unless both are valid and
+// the session ends after it starts
+func sessionClock(course model.Course, date time.Time) (start, end time.Time, ok bool) {
+	startTime, endTime := sessionTimes(course, date)
+	start, err := time.Parse("15:04", strings.TrimSpace(startTime))
+	if err != nil {
+		return start, end, false
+	}
+	end, err = time.Parse("15:04", strings.TrimSpace(endTime))
+	if err != nil || !end.After(start) {
+		return start, end, false
+	}
+	return start, end, true
+}
+
+// atClock returns the point in time of a clock time on a date in the time zone of the club
+func atClock(date, clock time.Time, timezone *time.Location) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), 0, 0, timezone)
+}
+
+// sessionTimes returns the start and end time of a session, taken from the event date for events
+func sessionTimes(course model.Course, date time.Time) (string, string) {
+	for _, eventDate := range course.EventDates {
+		if eventDate.Date.Format("2006-01-02") == date.Format("2006-01-02") {
+			return eventDate.StartTime, eventDate.EndTime
+		}
+	}
+	return course.StartTime, course.EndTime
+}
+
+// sessionKey identifies a session by course and date
+func sessionKey(courseID uint, date time.Time) string {
+	return fmt.Sprintf("%d-%s", courseID, date.Format("2006-01-02"))
+}
+
+// roundCents rounds an amount to full cents
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
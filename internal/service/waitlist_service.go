@@ -0,0 +1,335 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"slices"
+	"time"
+
+	"azh/internal/model"
+	"azh/internal/repository"
+)
+
+// Errors returned when changing enrollments
+var (
+	ErrMemberNotFound  = errors.New("member not found")
+	ErrAlreadyEnrolled = errors.New("member already enrolled")
+	ErrAlreadyWaiting  = errors.New("member already on waitlist")
+	ErrMemberCancelled = errors.New("membership cancelled")
+)
+
+// waitlistActor is recorded in the audit log for promotions made by the daily background run
+const waitlistActor = "system (waitlist)"
+
+// EnrollmentDTO represents the outcome of an enrollment request
+type EnrollmentDTO struct {
+	CourseID         uint `json:"course_id"`
+	MemberID         uint `json:"member_id"`
+	Enrolled         bool `json:"enrolled"`
+	WaitlistPosition int  `json:"waitlist_position,omitempty"`
+}
+
+// WaitlistEntryDTO represents a member waiting for a course
+type WaitlistEntryDTO struct {
+	Position     int    `json:"position"`
+	MemberID     uint   `json:"member_id"`
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name"`
+	Email        string `json:"email"`
+	Phone        string `json:"phone"`
+	WaitingSince string `json:"waiting_since"`
+}
+
+// WaitlistDTO represents the capacity and waitlist of a course
+type WaitlistDTO struct {
+	CourseID        uint               `json:"course_id"`
+	MaxParticipants int                `json:"max_participants"`
+	Enrolled        int                `json:"enrolled"`
+	Waiting         []WaitlistEntryDTO `json:"waiting"`
+}
+
+// WaitlistService handles course capacity, enrollments and waitlists
+type WaitlistService struct {
+	courseRepo       *repository.CourseRepository
+	memberRepo       *repository.MemberRepository
+	memberCourseRepo *repository.MemberCourseRepository
+	waitlistRepo     *repository.WaitlistRepository
+	auditService     *AuditService
+}
+
+// NewWaitlistService creates a new WaitlistService
+func NewWaitlistService(
+	courseRepo *repository.CourseRepository,
+	memberRepo *repository.MemberRepository,
+	memberCourseRepo *repository.MemberCourseRepository,
+	waitlistRepo *repository.WaitlistRepository,
+	auditService *AuditService,
+) *WaitlistService {
+	return &WaitlistService{
+		courseRepo:       courseRepo,
+		memberRepo:       memberRepo,
+		memberCourseRepo: memberCourseRepo,
+		waitlistRepo:     waitlistRepo,
+		auditService:     auditService,
+	}
+}
+
+// GetWaitlist retrieves the capacity, number of enrolled members and waitlist of a course
+func (s *WaitlistService) GetWaitlist(courseID uint, referenceDate time.Time) (WaitlistDTO, error) {
+	course, err := getCourse(s.courseRepo, courseID)
+	if err != nil {
+		return WaitlistDTO{}, err
+	}
+	counts, err := s.memberCourseRepo.CountActive(referenceDate)
+	if err != nil {
+		return WaitlistDTO{}, err
+	}
+	entries, err := s.waitlistRepo.GetWaiting(courseID)
+	if err != nil {
+		return WaitlistDTO{}, err
+	}
+	dto := WaitlistDTO{
+		CourseID:        courseID,
+		MaxParticipants: course.MaxParticipants,
+		Enrolled:        counts[courseID],
+		Waiting:         make([]WaitlistEntryDTO, 0, len(entries)),
+	}
+	if len(entries) == 0 {
+		return dto, nil
+	}
+
+	memberIDs := make([]uint, 0, len(entries))
+	for _, entry := range entries {
+		memberIDs = append(memberIDs, entry.MemberID)
+	}
+	members, err := s.memberRepo.GetByIDs(memberIDs)
+	if err != nil {
+		return WaitlistDTO{}, err
+	}
+	membersByID := make(map[uint]model.Member)
+	for _, member := range members {
+		membersByID[member.ID] = member
+	}
+	for i, entry := range entries {
+		member := membersByID[entry.MemberID]
+		dto.Waiting = append(dto.Waiting, WaitlistEntryDTO{
+			Position:     i + 1,
+			MemberID:     entry.MemberID,
+			FirstName:    member.FirstName,
+			LastName:     member.LastName,
+			Email:        member.Email,
+			Phone:        member.Phone,
+			WaitingSince: entry.CreatedAt.Format("2006-01-02"),
+		})
+	}
+	return dto, nil
+}
+
+// SetCapacity updates the maximum number of participants of a course and promotes waiting
+// members if the course has room now
+func (s *WaitlistService) SetCapacity(actor string, courseID uint, maxParticipants int, referenceDate time.Time) error {
+	course, err := getCourse(s.courseRepo, courseID)
+	if err != nil {
+		return err
+	}
+	if err := s.courseRepo.SetMaxParticipants(courseID, maxParticipants); err != nil {
+		return err
+	}
+	if err := s.auditService.Record(actor, model.AuditActionUpdate, model.AuditEntityCourse, fmt.Sprint(courseID),
+		map[string]int{"max_participants": course.MaxParticipants}, map[string]int{"max_participants": maxParticipants}); err != nil {
+		return err
+	}
+	_, err = s.PromoteWaitlist(actor, courseID, referenceDate)
+	return err
+}
+
+// Enroll enrolls a member in a course if it has room, otherwise the member is put on the waitlist.
+// Members whose membership was cancelled before the reference date cannot be enrolled.
+func (s *WaitlistService) Enroll(actor string, courseID, memberID uint, referenceDate time.Time) (EnrollmentDTO, error) {
+	if _, err := getCourse(s.courseRepo, courseID); err != nil {
+		return EnrollmentDTO{}, err
+	}
+	members, err := s.memberRepo.GetByIDs([]uint{memberID})
+	if err != nil {
+		return EnrollmentDTO{}, err
+	}
+	if len(members) == 0 {
+		return EnrollmentDTO{}, ErrMemberNotFound
+	}
+	if cancellation := members[0].CancellationDate; !cancellation.IsZero() && cancellation.Before(referenceDate) {
+		return EnrollmentDTO{}, ErrMemberCancelled
+	}
+	enrolled, err := s.memberCourseRepo.Exists(memberID, courseID)
+	if err != nil {
+		return EnrollmentDTO{}, err
+	}
+	if enrolled {
+		return EnrollmentDTO{}, ErrAlreadyEnrolled
+	}
+	waiting, err := s.waitlistRepo.GetWaiting(courseID)
+	if err != nil {
+		return EnrollmentDTO{}, err
+	}
+	alreadyWaiting := slices.ContainsFunc(waiting, func(entry model.WaitlistEntry) bool { return entry.MemberID == memberID })
+
+	// Capacity check and enrollment happen in one transaction, see WaitlistRepository.Enroll
+	entry, err := s.waitlistRepo.Enroll(courseID, memberID, referenceDate)
+	if err != nil {
+		return EnrollmentDTO{}, err
+	}
+	result := EnrollmentDTO{CourseID: courseID, MemberID: memberID}
+	if entry.ID == 0 {
+		if err := s.auditService.Record(actor, model.AuditActionCreate, model.AuditEntityEnrollment, enrollmentAuditID(courseID, memberID),
+			nil, model.MemberCourse{MemberID: memberID, CourseID: courseID, Manual: true}); err != nil {
+			return EnrollmentDTO{}, err
+		}
+		result.Enrolled = true
+		return result, nil
+	}
+	if alreadyWaiting {
+		return EnrollmentDTO{}, ErrAlreadyWaiting
+	}
+
+	if waiting, err = s.waitlistRepo.GetWaiting(courseID); err != nil {
+		return EnrollmentDTO{}, err
+	}
+	for i, waitingEntry := range waiting {
+		if waitingEntry.ID == entry.ID {
+			result.WaitlistPosition = i + 1
+		}
+	}
+	return result, nil
+}
+
+// Unenroll removes a member from a course or its waitlist and promotes the next waiting members
+func (s *WaitlistService) Unenroll(actor string, courseID, memberID uint, referenceDate time.Time) error {
+	if _, err := getCourse(s.courseRepo, courseID); err != nil {
+		return err
+	}
+	enrolled, err := s.memberCourseRepo.Exists(memberID, courseID)
+	if err != nil {
+		return err
+	}
+	if err := s.waitlistRepo.Remove(courseID, memberID); err != nil {
+		return err
+	}
+	if enrolled {
+		if err := s.memberCourseRepo.Delete(memberID, courseID); err != nil {
+			return err
+		}
+		if err := s.auditService.Record(actor, model.AuditActionDelete, model.AuditEntityEnrollment,
+			enrollmentAuditID(courseID, memberID), model.MemberCourse{MemberID: memberID, CourseID: courseID}, nil); err != nil {
+			return err
+		}
+	}
+	_, err = s.PromoteWaitlist(actor, courseID, referenceDate)
+	return err
+}
+
+// PromoteWaitlist enrolls waiting members in order while the course has free spots. Members whose
+// membership was cancelled in the meantime are skipped.
+func (s *WaitlistService) PromoteWaitlist(actor string, courseID uint, referenceDate time.Time) ([]uint, error) {
+	course, err := getCourse(s.courseRepo, courseID)
+	if err != nil {
+		return nil, err
+	}
+	free, err := s.freeSpots(course, referenceDate)
+	if err != nil || free <= 0 {
+		return nil, err
+	}
+	entries, err := s.waitlistRepo.GetWaiting(courseID)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+
+	memberIDs := make([]uint, 0, len(entries))
+	for _, entry := range entries {
+		memberIDs = append(memberIDs, entry.MemberID)
+	}
+	members, err := s.memberRepo.GetByIDsAndDate(memberIDs, referenceDate)
+	if err != nil {
+		return nil, err
+	}
+	active := make(map[uint]struct{})
+	for _, member := range members {
+		active[member.ID] = struct{}{}
+	}
+
+	var promoted []uint
+	for _, entry := range entries {
+		if free == 0 {
+			break
+		}
+		if _, ok := active[entry.MemberID]; !ok {
+			continue
+		}
+		ok, err := s.waitlistRepo.Promote(entry, referenceDate, time.Now())
+		if err != nil {
+			return promoted, fmt.Errorf("error promoting member %d to course %d: %v", entry.MemberID, courseID, err)
+		}
+		if !ok {
+			// A concurrent enrollment took the last spot
+			break
+		}
+		log.Printf("Promoted member %d from the waitlist of course %d", entry.MemberID, courseID)
+		if err := s.auditService.Record(actor, model.AuditActionCreate, model.AuditEntityEnrollment, enrollmentAuditID(courseID, entry.MemberID),
+			nil, model.MemberCourse{MemberID: entry.MemberID, CourseID: courseID, Manual: true}); err != nil {
+			return promoted, err
+		}
+		promoted = append(promoted, entry.MemberID)
+		free--
+	}
+	return promoted, nil
+}
+
+// PromoteAllWaitlists promotes waiting members in all courses with free spots
+func (s *WaitlistService) PromoteAllWaitlists(actor string, referenceDate time.Time) (int, error) {
+	courseIDs, err := s.waitlistRepo.GetCourseIDsWithWaiting()
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for _, courseID := range courseIDs {
+		promoted, err := s.PromoteWaitlist(actor, courseID, referenceDate)
+		total += len(promoted)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// StartDailyPromotion promotes waiting members every day at the given hour in the background, so
+// spots freed by cancellations taking effect are offered without waiting for the next import
+func (s *WaitlistService) StartDailyPromotion(hour int) {
+	go func() {
+		for {
+			now := time.Now()
+			next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+			if !next.After(now) {
+				next = next.AddDate(0, 0, 1)
+			}
+			time.Sleep(time.Until(next))
+			promoted, err := s.PromoteAllWaitlists(waitlistActor, next)
+			if err != nil {
+				log.Printf("Failed to promote waitlists: %v", err)
+			} else if promoted > 0 {
+				log.Printf("Promoted %d members from waitlists", promoted)
+			}
+		}
+	}()
+}
+
+// freeSpots computes the number of free spots in a course; courses without capacity have unlimited room
+func (s *WaitlistService) freeSpots(course model.Course, referenceDate time.Time) (int, error) {
+	if course.MaxParticipants <= 0 {
+		return math.MaxInt, nil
+	}
+	counts, err := s.memberCourseRepo.CountActive(referenceDate)
+	if err != nil {
+		return 0, err
+	}
+	return course.MaxParticipants - counts[course.ID], nil
+}
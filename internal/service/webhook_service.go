@@ -0,0 +1,544 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"azh/internal/model"
+	"azh/internal/repository"
+	"azh/internal/webhook"
+	"gorm.io/gorm"
+)
+
+// Errors returned by the webhook service
+var (
+	ErrInvalidWebhook          = errors.New("invalid webhook")
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrWebhookDeliveryState    = errors.New("only failed deliveries can be retried")
+)
+
+const (
+	// webhookBatchSize is the number of due deliveries sent per dispatcher run
+	webhookBatchSize = 100
+	// webhookQueueSize is the number of audit batches buffered until their deliveries are stored
+	webhookQueueSize = 1000
+	// webhookQueueAttempts is how often storing the deliveries of an audit batch is tried
+	webhookQueueAttempts = 3
+)
+
+// WebhookSettings configures the delivery of webhook events
+type WebhookSettings struct {
+	MaxAttempts int
+	RetryDelay  time.Duration // delay before the first retry, doubled for every further attempt
+	Interval    time.Duration // how often due deliveries are checked
+	Timeout     time.Duration // how long a receiver may take to answer
+}
+
+// WebhookRequest holds the configuration of a webhook
+type WebhookRequest struct {
+	Name         string   `json:"name"`
+	URL          string   `json:"url"`
+	Events       []string `json:"events"`
+	Active       bool     `json:"active"`
+	RotateSecret bool     `json:"rotate_secret"` // only on updates; new webhooks always get a secret
+}
+
+// WebhookDTO represents a webhook. The secret is only included when it was created or rotated,
+// so receivers can be set up with it.
+type WebhookDTO struct {
+	model.Webhook
+	Events []string `json:"events"`
+	Secret string   `json:"secret,omitempty"`
+}
+
+// WebhookPayload is the JSON body posted to webhooks
+type WebhookPayload struct {
+	ID         string    `json:"id"` // event ID, the same for all webhooks receiving the event
+	Event      string    `json:"event"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Actor      string    `json:"actor"`
+	Data       any       `json:"data"`
+}
+
+// WebhookMember is the member data sent with member events
+type WebhookMember struct {
+	ID               uint    `json:"id"`
+	FirstName        string  `json:"first_name"`
+	LastName         string  `json:"last_name"`
+	Email            string  `json:"email"`
+	Phone            string  `json:"phone"`
+	SignUpDate       *string `json:"sign_up_date"`
+	CancellationDate *string `json:"cancellation_date"`
+	Guest            bool    `json:"guest"`
+}
+
+// WebhookService posts changes of members, enrollments and attendance to external systems. The
+// events are derived from the audit log, so every change made in the application or by an import
+// is covered. The deliveries are stored and sent in the background, off the request that made the
+// change; receivers must not rely on the order of events, as failed deliveries are retried later.
+type WebhookService struct {
+	webhookRepo  *repository.WebhookRepository
+	auditService *AuditService
+	client       *webhook.Client
+	settings     WebhookSettings
+	wake         chan struct{}
+	audits       chan []model.AuditEntry
+}
+
+// NewWebhookService creates a new WebhookService and subscribes it to the audit log
+func NewWebhookService(
+	webhookRepo *repository.WebhookRepository,
+	auditService *AuditService,
+	settings WebhookSettings,
+) *WebhookService {
+	s := &WebhookService{
+		webhookRepo:  webhookRepo,
+		auditService: auditService,
+		client:       webhook.NewClient(settings.Timeout),
+		settings:     settings,
+		wake:         make(chan struct{}, 1),
+		audits:       make(chan []model.AuditEntry, webhookQueueSize),
+	}
+	auditService.Subscribe(s.handleAudit)
+	return s
+}
+
+// GetWebhooks retrieves all webhooks
+func (s *WebhookService) GetWebhooks() ([]WebhookDTO, error) {
+	webhooks, err := s.webhookRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	dtos := make([]WebhookDTO, 0, len(webhooks))
+	for _, hook := range webhooks {
+		dtos = append(dtos, toWebhookDTO(hook, false))
+	}
+	return dtos, nil
+}
+
+// CreateWebhook creates a webhook with a new secret
+func (s *WebhookService) CreateWebhook(actor string, req WebhookRequest) (WebhookDTO, error) {
+	hook, err := validateWebhook(req)
+	if err != nil {
+		return WebhookDTO{}, err
+	}
+	if hook.Secret, err = newToken(); err != nil {
+		return WebhookDTO{}, err
+	}
+	if err := s.webhookRepo.Save(&hook); err != nil {
+		return WebhookDTO{}, err
+	}
+	if err := s.auditService.Record(actor, model.AuditActionCreate, model.AuditEntityWebhook, fmt.Sprint(hook.ID), nil, hook); err != nil {
+		return WebhookDTO{}, err
+	}
+	return toWebhookDTO(hook, true), nil
+}
+
+// UpdateWebhook updates a webhook and rotates its secret if requested
+func (s *WebhookService) UpdateWebhook(actor string, id uint, req WebhookRequest) (WebhookDTO, error) {
+	before, err := s.getWebhook(id)
+	if err != nil {
+		return WebhookDTO{}, err
+	}
+	hook, err := validateWebhook(req)
+	if err != nil {
+		return WebhookDTO{}, err
+	}
+	hook.Model = before.Model
+	hook.Secret = before.Secret
+	if req.RotateSecret {
+		if hook.Secret, err = newToken(); err != nil {
+			return WebhookDTO{}, err
+		}
+	}
+	if err := s.webhookRepo.Save(&hook); err != nil {
+		return WebhookDTO{}, err
+	}
+	if err := s.auditService.Record(actor, model.AuditActionUpdate, model.AuditEntityWebhook, fmt.Sprint(hook.ID), before, hook); err != nil {
+		return WebhookDTO{}, err
+	}
+	return toWebhookDTO(hook, req.RotateSecret), nil
+}
+
+// DeleteWebhook removes a webhook; pending deliveries to it fail
+func (s *WebhookService) DeleteWebhook(actor string, id uint) error {
+	before, err := s.getWebhook(id)
+	if err != nil {
+		return err
+	}
+	if err := s.webhookRepo.Delete(id); err != nil {
+		return err
+	}
+	return s.auditService.Record(actor, model.AuditActionDelete, model.AuditEntityWebhook, fmt.Sprint(id), before, nil)
+}
+
+// SendTest posts a test event to a webhook right away, even if it is inactive, and returns the
+// logged delivery. Test deliveries are not retried.
+func (s *WebhookService) SendTest(actor string, id uint, now time.Time) (model.WebhookDelivery, error) {
+	hook, err := s.getWebhook(id)
+	if err != nil {
+		return model.WebhookDelivery{}, err
+	}
+	deliveries, err := s.newDeliveries([]model.Webhook{hook}, model.WebhookEventTest, actor, now,
+		map[string]string{"message": "Testereignis", "webhook": hook.Name})
+	if err != nil {
+		return model.WebhookDelivery{}, err
+	}
+	delivery := deliveries[0]
+	// Saved before sending so the request carries the delivery ID of the log, but as failed, so
+	// the dispatcher does not pick it up meanwhile
+	delivery.Status = model.WebhookDeliveryFailed
+	if err := s.webhookRepo.SaveDelivery(&delivery); err != nil {
+		return delivery, err
+	}
+	s.send(&delivery, hook)
+	if delivery.Status != model.WebhookDeliveryDelivered {
+		delivery.Status = model.WebhookDeliveryFailed
+	}
+	return delivery, s.webhookRepo.SaveDelivery(&delivery)
+}
+
+// GetDeliveries retrieves the latest deliveries by webhook and status; empty filters match all
+func (s *WebhookService) GetDeliveries(webhookID uint, status string) ([]model.WebhookDelivery, error) {
+	return s.webhookRepo.FindDeliveries(webhookID, status, 500)
+}
+
+// RetryDelivery queues a failed delivery again with a fresh set of attempts
+func (s *WebhookService) RetryDelivery(id uint, now time.Time) (model.WebhookDelivery, error) {
+	delivery, err := s.webhookRepo.GetDelivery(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return delivery, ErrWebhookDeliveryNotFound
+	}
+	if err != nil {
+		return delivery, err
+	}
+	if delivery.Status != model.WebhookDeliveryFailed {
+		return delivery, ErrWebhookDeliveryState
+	}
+	delivery.Status = model.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = now
+	if err := s.webhookRepo.SaveDelivery(&delivery); err != nil {
+		return delivery, err
+	}
+	s.Wake()
+	return delivery, nil
+}
+
+// StartDispatcher queues the events of changes and sends due deliveries in the background, at the
+// configured interval and whenever new deliveries are queued
+func (s *WebhookService) StartDispatcher() {
+	go func() {
+		for entries := range s.audits {
+			s.queueEvents(entries)
+		}
+	}()
+	go func() {
+		ticker := time.NewTicker(s.settings.Interval)
+		defer ticker.Stop()
+		for {
+			if err := s.ProcessDeliveries(time.Now()); err != nil {
+				log.Printf("Failed to process webhook deliveries: %v", err)
+			}
+			select {
+			case <-ticker.C:
+			case <-s.wake:
+			}
+		}
+	}()
+}
+
+// Wake makes the dispatcher send queued deliveries right away
+func (s *WebhookService) Wake() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// ProcessDeliveries sends the deliveries due at the given time. Failed deliveries are retried with doubling delay
+// until the maximum number of attempts is reached.
+func (s *WebhookService) ProcessDeliveries(now time.Time) error {
+	for {
+		due, err := s.webhookRepo.GetDueDeliveries(now, webhookBatchSize)
+		if err != nil {
+			return err
+		}
+		for i := range due {
+			hook, err := s.webhookRepo.GetByID(due[i].WebhookID)
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				due[i].Status = model.WebhookDeliveryFailed
+				due[i].LastError = "webhook removed"
+			case err != nil:
+				return err
+			default:
+				s.send(&due[i], hook)
+			}
+			if err := s.webhookRepo.SaveDelivery(&due[i]); err != nil {
+				return err
+			}
+		}
+		if len(due) < webhookBatchSize {
+			return nil
+		}
+	}
+}
+
+// send posts a delivery once and records the outcome. The request is signed with the time it is
+// sent, as a batch of slow receivers may take longer than receivers accept signatures.
+func (s *WebhookService) send(delivery *model.WebhookDelivery, hook model.Webhook) {
+	now := time.Now()
+	delivery.Attempts++
+	response, err := s.client.Send(hook.URL, hook.Secret, delivery.Event, fmt.Sprint(delivery.ID), []byte(delivery.Payload), now)
+	delivery.ResponseStatus = response.StatusCode
+	delivery.ResponseBody = response.Body
+	switch {
+	case err == nil:
+		delivery.Status = model.WebhookDeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+	case delivery.Attempts >= s.settings.MaxAttempts:
+		delivery.Status = model.WebhookDeliveryFailed
+		delivery.LastError = err.Error()
+	default:
+		delivery.NextAttemptAt = now.Add(s.settings.RetryDelay << (delivery.Attempts - 1))
+		delivery.LastError = err.Error()
+	}
+}
+
+// handleAudit hands stored audit entries to the background worker, as audit listeners must not
+// block the request that made the change
+func (s *WebhookService) handleAudit(entries []model.AuditEntry) {
+	select {
+	case s.audits <- entries:
+	default:
+		log.Printf("Webhook queue full, dropping the events of %d audit entries from ID %d", len(entries), entries[0].ID)
+	}
+}
+
+// queueEvents stores the deliveries of the events of audit entries to the webhooks subscribed to
+// them, retrying failures before giving up
+func (s *WebhookService) queueEvents(entries []model.AuditEntry) {
+	var err error
+	for attempt := 1; attempt <= webhookQueueAttempts; attempt++ {
+		if err = s.createDeliveries(entries); err == nil {
+			return
+		}
+		if attempt < webhookQueueAttempts {
+			time.Sleep(s.settings.RetryDelay)
+		}
+	}
+	log.Printf("Failed to queue the webhook events of %d audit entries from ID %d: %v", len(entries), entries[0].ID, err)
+}
+
+// createDeliveries stores the deliveries of the events of audit entries and wakes the dispatcher
+func (s *WebhookService) createDeliveries(entries []model.AuditEntry) error {
+	var hooks []model.Webhook
+	loaded := false
+	var deliveries []model.WebhookDelivery
+	for _, entry := range entries {
+		event, data, ok := webhookEvent(entry)
+		if !ok {
+			continue
+		}
+		if !loaded {
+			var err error
+			if hooks, err = s.webhookRepo.GetActive(); err != nil {
+				return fmt.Errorf("error loading webhooks: %v", err)
+			}
+			loaded = true
+		}
+		var subscribed []model.Webhook
+		for _, hook := range hooks {
+			if slices.Contains(webhookEvents(hook), event) {
+				subscribed = append(subscribed, hook)
+			}
+		}
+		if len(subscribed) == 0 {
+			continue
+		}
+		occurredAt := entry.CreatedAt
+		if occurredAt.IsZero() {
+			occurredAt = time.Now()
+		}
+		queued, err := s.newDeliveries(subscribed, event, entry.Actor, occurredAt, data)
+		if err != nil {
+			return fmt.Errorf("error building webhook event %s: %v", event, err)
+		}
+		deliveries = append(deliveries, queued...)
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	if err := s.webhookRepo.CreateDeliveries(deliveries); err != nil {
+		return err
+	}
+	s.Wake()
+	return nil
+}
+
+// newDeliveries builds the pending deliveries of an event to the given webhooks
+func (s *WebhookService) newDeliveries(hooks []model.Webhook, event, actor string, occurredAt time.Time, data any) ([]model.WebhookDelivery, error) {
+	eventID, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(WebhookPayload{ID: eventID, Event: event, OccurredAt: occurredAt.UTC(), Actor: actor, Data: data})
+	if err != nil {
+		return nil, err
+	}
+	deliveries := make([]model.WebhookDelivery, 0, len(hooks))
+	for _, hook := range hooks {
+		deliveries = append(deliveries, model.WebhookDelivery{
+			WebhookID:     hook.ID,
+			Event:         event,
+			EventID:       eventID,
+			Payload:       string(payload),
+			Status:        model.WebhookDeliveryPending,
+			NextAttemptAt: occurredAt,
+		})
+	}
+	return deliveries, nil
+}
+
+// getWebhook retrieves a webhook and maps a missing record to ErrWebhookNotFound
+func (s *WebhookService) getWebhook(id uint) (model.Webhook, error) {
+	hook, err := s.webhookRepo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return hook, ErrWebhookNotFound
+	}
+	return hook, err
+}
+
+// webhookEvent maps an audit entry to the webhook event it stands for, if any, and its data
+func webhookEvent(entry model.AuditEntry) (string, any, bool) {
+	switch entry.Entity {
+	case model.AuditEntityMember:
+		var before, after model.Member
+		if entry.After == "" || json.Unmarshal([]byte(entry.After), &after) != nil {
+			return "", nil, false
+		}
+		if entry.Action == model.AuditActionCreate {
+			return model.WebhookEventMemberCreated, toWebhookMember(after), true
+		}
+		if entry.Before == "" || json.Unmarshal([]byte(entry.Before), &before) != nil {
+			return "", nil, false
+		}
+		if memberCancelled(after) && !after.CancellationDate.Equal(before.CancellationDate) {
+			return model.WebhookEventMemberCancelled, toWebhookMember(after), true
+		}
+	case model.AuditEntityEnrollment:
+		ids := strings.Split(entry.EntityID, "/")
+		if len(ids) != 2 || (entry.Action != model.AuditActionCreate && entry.Action != model.AuditActionDelete) {
+			return "", nil, false
+		}
+		courseID, _ := strconv.ParseUint(ids[0], 10, 32)
+		memberID, _ := strconv.ParseUint(ids[1], 10, 32)
+		return model.WebhookEventEnrollmentChanged, map[string]any{
+			"course_id": courseID,
+			"member_id": memberID,
+			"enrolled":  entry.Action == model.AuditActionCreate,
+		}, true
+	case model.AuditEntityParticipation:
+		ids := strings.Split(entry.EntityID, "/")
+		var after map[string]string
+		if len(ids) != 3 || json.Unmarshal([]byte(entry.After), &after) != nil {
+			return "", nil, false
+		}
+		courseID, _ := strconv.ParseUint(ids[0], 10, 32)
+		memberID, _ := strconv.ParseUint(ids[2], 10, 32)
+		return model.WebhookEventAttendance, map[string]any{
+			"course_id": courseID,
+			"date":      ids[1],
+			"member_id": memberID,
+			"status":    after["status"],
+		}, true
+	case model.AuditEntityImport:
+		return model.WebhookEventImportCompleted, map[string]any{
+			"file":    entry.EntityID,
+			"summary": json.RawMessage(entry.After),
+		}, true
+	}
+	return "", nil, false
+}
+
+// memberCancelled reports whether a member has a cancellation date; imports store 9999-12-31 for none
+func memberCancelled(member model.Member) bool {
+	return !member.CancellationDate.IsZero() && member.CancellationDate.Year() < 9999
+}
+
+// toWebhookMember converts a member into the data of member events
+func toWebhookMember(member model.Member) WebhookMember {
+	dto := WebhookMember{
+		ID:        member.ID,
+		FirstName: member.FirstName,
+		LastName:  member.LastName,
+		Email:     member.Email,
+		Phone:     member.Phone,
+		Guest:     member.Guest,
+	}
+	if !member.SignUpDate.IsZero() && member.SignUpDate.Year() > 1 {
+		signUpDate := member.SignUpDate.Format("2006-01-02")
+		dto.SignUpDate = &signUpDate
+	}
+	if memberCancelled(member) {
+		cancellationDate := member.CancellationDate.Format("2006-01-02")
+		dto.CancellationDate = &cancellationDate
+	}
+	return dto
+}
+
+// validateWebhook checks the configuration of a webhook and converts it into a webhook
+func validateWebhook(req WebhookRequest) (model.Webhook, error) {
+	hook := model.Webhook{
+		Name:   strings.TrimSpace(req.Name),
+		URL:    strings.TrimSpace(req.URL),
+		Active: req.Active,
+	}
+	if hook.Name == "" {
+		return hook, fmt.Errorf("%w: name missing", ErrInvalidWebhook)
+	}
+	if parsed, err := url.Parse(hook.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return hook, fmt.Errorf("%w: invalid URL", ErrInvalidWebhook)
+	}
+	if len(req.Events) == 0 {
+		return hook, fmt.Errorf("%w: no events", ErrInvalidWebhook)
+	}
+	var events []string
+	for _, event := range req.Events {
+		if !slices.Contains(model.WebhookEvents, event) {
+			return hook, fmt.Errorf("%w: unknown event %s", ErrInvalidWebhook, event)
+		}
+		if !slices.Contains(events, event) {
+			events = append(events, event)
+		}
+	}
+	hook.Events = strings.Join(events, ",")
+	return hook, nil
+}
+
+// webhookEvents splits the events a webhook subscribes to
+func webhookEvents(hook model.Webhook) []string {
+	if hook.Events == "" {
+		return nil
+	}
+	return strings.Split(hook.Events, ",")
+}
+
+// toWebhookDTO converts a webhook, including its secret if it was just created or rotated
+func toWebhookDTO(hook model.Webhook, withSecret bool) WebhookDTO {
+	dto := WebhookDTO{Webhook: hook, Events: webhookEvents(hook)}
+	if withSecret {
+		dto.Secret = hook.Secret
+	}
+	return dto
+}
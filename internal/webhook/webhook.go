@@ -0,0 +1,102 @@
+// Package webhook signs and posts the JSON payloads of outgoing webhooks and verifies them on the
+// receiving side.
+//
+// Every request carries the event name, a delivery ID, the Unix time it was sent and a signature
+// of the form "sha256=<hex>", the HMAC-SHA256 of "<timestamp>.<body>" keyed with the secret of the
+// webhook. Receivers recompute the signature and reject old timestamps to prevent replays.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Request headers of a webhook delivery
+const (
+	HeaderEvent     = "X-AZH-Event"
+	HeaderDelivery  = "X-AZH-Delivery"
+	HeaderTimestamp = "X-AZH-Timestamp"
+	HeaderSignature = "X-AZH-Signature"
+)
+
+// ErrInvalidSignature is returned by Verify for requests that were not signed with the secret
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// maxResponseLength is how much of a response body is kept for the delivery log
+const maxResponseLength = 500
+
+// Sign computes the signature of a payload sent at the given Unix time
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature of a received payload. Requests sent more than tolerance before or
+// after now are rejected as well.
+func Verify(secret string, header http.Header, payload []byte, now time.Time, tolerance time.Duration) error {
+	timestamp, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: missing timestamp", ErrInvalidSignature)
+	}
+	if age := now.Sub(time.Unix(timestamp, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidSignature)
+	}
+	if !hmac.Equal([]byte(header.Get(HeaderSignature)), []byte(Sign(secret, timestamp, payload))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Response is the outcome of a delivery attempt that reached the receiver
+type Response struct {
+	StatusCode int
+	Body       string // beginning of the response body
+}
+
+// Client posts signed payloads to webhook receivers
+type Client struct {
+	http *http.Client
+}
+
+// NewClient creates a new Client giving up on receivers after timeout
+func NewClient(timeout time.Duration) *Client {
+	return &Client{http: &http.Client{Timeout: timeout}}
+}
+
+// Send posts a signed payload. Any status other than 2xx counts as failure; the response is
+// returned in that case as well if the receiver answered at all. Send does not retry.
+func (c *Client) Send(url, secret, event, deliveryID string, payload []byte, now time.Time) (Response, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return Response{}, err
+	}
+	timestamp := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "azh-webhook/1")
+	req.Header.Set(HeaderEvent, event)
+	req.Header.Set(HeaderDelivery, deliveryID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(secret, timestamp, payload))
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return Response{}, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseLength))
+	response := Response{StatusCode: resp.StatusCode, Body: string(body)}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return response, fmt.Errorf("receiver returned %s", resp.Status)
+	}
+	return response, nil
+}
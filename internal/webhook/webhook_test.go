@@ -0,0 +1,118 @@
+package webhook
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSignIsStable(t *testing.T) {
+	payload := []byte(`{"event":"test"}`)
+	first := Sign("secret", 1700000000, payload)
+	if second := Sign("secret", 1700000000, payload); first != second {
+		t.Fatalf("signatures differ: %s and %s", first, second)
+	}
+	if len(first) != len("sha256=")+64 || first[:7] != "sha256=" {
+		t.Fatalf("unexpected signature format: %s", first)
+	}
+	if Sign("other", 1700000000, payload) == first {
+		t.Fatal("signature does not depend on the secret")
+	}
+	if Sign("secret", 1700000001, payload) == first {
+		t.Fatal("signature does not depend on the timestamp")
+	}
+	if Sign("secret", 1700000000, []byte(`{"event":"other"}`)) == first {
+		t.Fatal("signature does not depend on the payload")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	const secret = "secret"
+	payload := []byte(`{"event":"test"}`)
+	sentAt := time.Unix(1700000000, 0)
+	tolerance := 5 * time.Minute
+
+	header := func(timestamp string, signature string) http.Header {
+		h := http.Header{}
+		if timestamp != "" {
+			h.Set(HeaderTimestamp, timestamp)
+		}
+		h.Set(HeaderSignature, signature)
+		return h
+	}
+	valid := Sign(secret, sentAt.Unix(), payload)
+	sentAtText := strconv.FormatInt(sentAt.Unix(), 10)
+
+	tests := []struct {
+		name    string
+		header  http.Header
+		payload []byte
+		now     time.Time
+		wantErr bool
+	}{
+		{"valid", header(sentAtText, valid), payload, sentAt, false},
+		{"valid within tolerance", header(sentAtText, valid), payload, sentAt.Add(tolerance), false},
+		{"clock of receiver behind", header(sentAtText, valid), payload, sentAt.Add(-tolerance), false},
+		{"too old", header(sentAtText, valid), payload, sentAt.Add(tolerance + time.Second), true},
+		{"too far in the future", header(sentAtText, valid), payload, sentAt.Add(-tolerance - time.Second), true},
+		{"missing timestamp", header("", valid), payload, sentAt, true},
+		{"invalid timestamp", header("yesterday", valid), payload, sentAt, true},
+		{"other timestamp", header(strconv.FormatInt(sentAt.Unix()+1, 10), valid), payload, sentAt, true},
+		{"changed payload", header(sentAtText, valid), []byte(`{"event":"other"}`), sentAt, true},
+		{"wrong secret", header(sentAtText, Sign("other", sentAt.Unix(), payload)), payload, sentAt, true},
+		{"missing signature", header(sentAtText, ""), payload, sentAt, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(secret, tt.header, tt.payload, tt.now, tolerance)
+			if tt.wantErr && !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("expected ErrInvalidSignature, got %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestClientSend(t *testing.T) {
+	const secret = "secret"
+	payload := []byte(`{"event":"test"}`)
+
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{"delivered", http.StatusNoContent, false},
+		{"rejected", http.StatusUnauthorized, true},
+		{"receiver error", http.StatusInternalServerError, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if err := Verify(secret, r.Header, body, time.Now(), time.Minute); err != nil {
+					t.Errorf("request does not verify: %v", err)
+				}
+				if r.Header.Get(HeaderEvent) != "test" || r.Header.Get(HeaderDelivery) != "42" {
+					t.Errorf("unexpected headers: %v", r.Header)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			response, err := NewClient(time.Second).Send(server.URL, secret, "test", "42", payload, time.Now())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if response.StatusCode != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, response.StatusCode)
+			}
+		})
+	}
+}
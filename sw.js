@@ -0,0 +1,33 @@
+// Service worker of the attendance list. It keeps the page and the last loaded lists available
+// offline; attendance changes made offline are queued by the page and sent to /api/sync.
+const CACHE_NAME = 'azh-v1';
+const APP_SHELL = ['/', '/manifest.webmanifest', 'https://cdn.tailwindcss.com'];
+
+self.addEventListener('install', event => {
+    event.waitUntil(caches.open(CACHE_NAME).then(cache => cache.addAll(APP_SHELL)));
+    self.skipWaiting();
+});
+
+self.addEventListener('activate', event => {
+    event.waitUntil(caches.keys().then(keys => Promise.all(
+        keys.filter(key => key !== CACHE_NAME).map(key => caches.delete(key))
+    )));
+    self.clients.claim();
+});
+
+// Network first for GET requests, falling back to the last cached response while offline
+self.addEventListener('fetch', event => {
+    const request = event.request;
+    if (request.method !== 'GET' || request.url.endsWith('/stream')) return;
+    event.respondWith(
+        fetch(request)
+            .then(response => {
+                if (response.ok) {
+                    const copy = response.clone();
+                    caches.open(CACHE_NAME).then(cache => cache.put(request, copy));
+                }
+                return response;
+            })
+            .catch(() => caches.match(request).then(cached => cached || Response.error()))
+    );
+});